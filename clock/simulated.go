@@ -0,0 +1,280 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const simulatedResolution = 10 * time.Millisecond
+
+type Simulated struct {
+	mu       sync.Mutex
+	advance  sync.Mutex
+	now      time.Time
+	speed    float64
+	paused   bool
+	lastReal time.Time
+	tickers  map[*simulatedTicker]struct{}
+	timers   map[*simulatedTimer]struct{}
+	seq      uint64
+	stop     chan struct{}
+}
+
+type SimulatedState struct {
+	Mode   string    `json:"mode"`
+	Now    time.Time `json:"now"`
+	Speed  float64   `json:"speed"`
+	Paused bool      `json:"paused"`
+}
+
+func NewSimulated(start time.Time, speed float64) *Simulated {
+	if speed <= 0 {
+		speed = 1
+	}
+	
+	c := &Simulated{
+		now:      start,
+		speed:    speed,
+		lastReal: time.Now(),
+		tickers:  make(map[*simulatedTicker]struct{}),
+		timers:   make(map[*simulatedTimer]struct{}),
+		stop:     make(chan struct{}),
+	}
+	
+	go c.run()
+	
+	return c
+}
+
+func (c *Simulated) run() {
+	ticker := time.NewTicker(simulatedResolution)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case realNow := <-ticker.C:
+			c.mu.Lock()
+			elapsed := realNow.Sub(c.lastReal)
+			c.lastReal = realNow
+			if c.paused {
+				c.mu.Unlock()
+				continue
+			}
+			target := c.now.Add(time.Duration(float64(elapsed) * c.speed))
+			c.mu.Unlock()
+			
+			c.advanceTo(target)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *Simulated) Close() {
+	close(c.stop)
+}
+
+func (c *Simulated) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	return c.now
+}
+
+func (c *Simulated) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *Simulated) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.paused = true
+}
+
+func (c *Simulated) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.paused = false
+	c.lastReal = time.Now()
+}
+
+func (c *Simulated) SetSpeed(speed float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	if speed > 0 {
+		c.speed = speed
+	}
+}
+
+func (c *Simulated) Step(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	c.advanceTo(c.Now().Add(d))
+}
+
+func (c *Simulated) State() SimulatedState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	return SimulatedState{
+		Mode:   "simulated",
+		Now:    c.now,
+		Speed:  c.speed,
+		Paused: c.paused,
+	}
+}
+
+func (c *Simulated) advanceTo(target time.Time) {
+	c.advance.Lock()
+	defer c.advance.Unlock()
+	
+	for {
+		c.mu.Lock()
+		deadline, ok := c.nextDeadline(target)
+		if !ok {
+			if target.After(c.now) {
+				c.now = target
+			}
+			c.mu.Unlock()
+			return
+		}
+		if deadline.After(c.now) {
+			c.now = deadline
+		}
+		
+		var fire []*simulatedTimer
+		for t := range c.timers {
+			if !t.deadline.After(deadline) {
+				delete(c.timers, t)
+				fire = append(fire, t)
+			}
+		}
+		var tick []*simulatedTicker
+		for t := range c.tickers {
+			if !t.next.After(deadline) {
+				tick = append(tick, t)
+				t.next = t.next.Add(t.period)
+			}
+		}
+		c.mu.Unlock()
+		
+		sort.Slice(fire, func(i, j int) bool {
+			if !fire[i].deadline.Equal(fire[j].deadline) {
+				return fire[i].deadline.Before(fire[j].deadline)
+			}
+			return fire[i].seq < fire[j].seq
+		})
+		for _, t := range fire {
+			t.f()
+		}
+		
+		sort.Slice(tick, func(i, j int) bool {
+			return tick[i].seq < tick[j].seq
+		})
+		for _, t := range tick {
+			select {
+			case t.ch <- deadline:
+			case <-t.done:
+			}
+		}
+	}
+}
+
+func (c *Simulated) nextDeadline(target time.Time) (time.Time, bool) {
+	var deadlines []time.Time
+	for t := range c.timers {
+		deadlines = append(deadlines, t.deadline)
+	}
+	for t := range c.tickers {
+		deadlines = append(deadlines, t.next)
+	}
+	
+	sort.Slice(deadlines, func(i, j int) bool {
+		return deadlines[i].Before(deadlines[j])
+	})
+	
+	if len(deadlines) == 0 || deadlines[0].After(target) {
+		return time.Time{}, false
+	}
+	return deadlines[0], true
+}
+
+func (c *Simulated) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.seq++
+	t := &simulatedTicker{
+		clock:  c,
+		ch:     make(chan time.Time, 1),
+		done:   make(chan struct{}),
+		period: d,
+		next:   c.now.Add(d),
+		seq:    c.seq,
+	}
+	c.tickers[t] = struct{}{}
+	
+	return t
+}
+
+func (c *Simulated) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.seq++
+	t := &simulatedTimer{
+		clock:    c,
+		deadline: c.now.Add(d),
+		f:        f,
+		seq:      c.seq,
+	}
+	c.timers[t] = struct{}{}
+	
+	return t
+}
+
+type simulatedTicker struct {
+	clock  *Simulated
+	ch     chan time.Time
+	done   chan struct{}
+	period time.Duration
+	next   time.Time
+	seq    uint64
+}
+
+func (t *simulatedTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *simulatedTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	
+	if _, ok := t.clock.tickers[t]; ok {
+		delete(t.clock.tickers, t)
+		close(t.done)
+	}
+}
+
+type simulatedTimer struct {
+	clock    *Simulated
+	deadline time.Time
+	f        func()
+	seq      uint64
+}
+
+func (t *simulatedTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	
+	if _, ok := t.clock.timers[t]; !ok {
+		return false
+	}
+	delete(t.clock.timers, t)
+	return true
+}
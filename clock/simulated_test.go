@@ -0,0 +1,144 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestClock(t *testing.T) *Simulated {
+	c := NewSimulated(testStart, 1)
+	c.Pause()
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestStepAdvancesPausedClock(t *testing.T) {
+	c := newTestClock(t)
+	
+	time.Sleep(3 * simulatedResolution)
+	if got := c.Now(); !got.Equal(testStart) {
+		t.Fatalf("paused clock moved to %s", got)
+	}
+	
+	c.Step(90 * time.Minute)
+	if want := testStart.Add(90 * time.Minute); !c.Now().Equal(want) {
+		t.Errorf("now = %s, want %s", c.Now(), want)
+	}
+	if got := c.Since(testStart); got != 90*time.Minute {
+		t.Errorf("since start = %s, want 1h30m", got)
+	}
+	
+	c.Step(-time.Hour)
+	if want := testStart.Add(90 * time.Minute); !c.Now().Equal(want) {
+		t.Errorf("negative step moved the clock to %s", c.Now())
+	}
+}
+
+func TestTickerTicksAtEachPeriod(t *testing.T) {
+	c := newTestClock(t)
+	ticker := c.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	
+	ticks := make(chan time.Time, 8)
+	go func() {
+		for at := range ticker.C() {
+			ticks <- at
+		}
+	}()
+	
+	c.Step(35 * time.Second)
+	for i := 1; i <= 3; i++ {
+		select {
+		case at := <-ticks:
+			if want := testStart.Add(time.Duration(i) * 10 * time.Second); !at.Equal(want) {
+				t.Errorf("tick %d at %s, want %s", i, at, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("tick %d not delivered", i)
+		}
+	}
+	select {
+	case at := <-ticks:
+		t.Errorf("unexpected tick at %s", at)
+	default:
+	}
+	if want := testStart.Add(35 * time.Second); !c.Now().Equal(want) {
+		t.Errorf("now = %s, want %s", c.Now(), want)
+	}
+}
+
+func TestTimersFireInDeadlineOrder(t *testing.T) {
+	c := newTestClock(t)
+	
+	var fired []string
+	record := func(name string) func() {
+		return func() {
+			fired = append(fired, name+"@"+c.Now().Sub(testStart).String())
+		}
+	}
+	c.AfterFunc(30*time.Second, record("c"))
+	c.AfterFunc(10*time.Second, record("a"))
+	c.AfterFunc(20*time.Second, record("b1"))
+	c.AfterFunc(20*time.Second, record("b2"))
+	stopped := c.AfterFunc(15*time.Second, record("stopped"))
+	c.AfterFunc(time.Minute, record("later"))
+	
+	if !stopped.Stop() {
+		t.Error("stop of a pending timer = false, want true")
+	}
+	if stopped.Stop() {
+		t.Error("second stop = true, want false")
+	}
+	
+	c.Step(45 * time.Second)
+	
+	want := []string{"a@10s", "b1@20s", "b2@20s", "c@30s"}
+	if len(fired) != len(want) {
+		t.Fatalf("fired = %v, want %v", fired, want)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Errorf("fired = %v, want %v", fired, want)
+			break
+		}
+	}
+}
+
+func TestTimerCanScheduleAnotherWithinStep(t *testing.T) {
+	c := newTestClock(t)
+	
+	var fired []time.Duration
+	c.AfterFunc(10*time.Second, func() {
+		fired = append(fired, c.Since(testStart))
+		c.AfterFunc(5*time.Second, func() {
+			fired = append(fired, c.Since(testStart))
+		})
+	})
+	
+	c.Step(time.Minute)
+	if len(fired) != 2 || fired[0] != 10*time.Second || fired[1] != 15*time.Second {
+		t.Errorf("fired at %v, want [10s 15s]", fired)
+	}
+}
+
+func TestSpeedScalesRealTime(t *testing.T) {
+	c := NewSimulated(testStart, 1000)
+	defer c.Close()
+	
+	time.Sleep(50 * time.Millisecond)
+	if elapsed := c.Since(testStart); elapsed < 10*time.Second {
+		t.Errorf("elapsed at 1000x after 50ms = %s, want at least 10s", elapsed)
+	}
+	
+	c.Pause()
+	paused := c.Now()
+	time.Sleep(3 * simulatedResolution)
+	if !c.Now().Equal(paused) {
+		t.Error("clock moved while paused")
+	}
+	if state := c.State(); !state.Paused || state.Speed != 1000 || state.Mode != "simulated" {
+		t.Errorf("state = %+v, want paused simulated at 1000x", state)
+	}
+}
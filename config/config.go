@@ -5,6 +5,9 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+
+	"multi-agent-framework-testing/models"
 )
 
 type Config struct {
@@ -17,6 +20,146 @@ type Config struct {
 	RateLimitRPS         int    `json:"rate_limit_rps"`
 	MaxDevices           int    `json:"max_devices"`
 	EnableDebugMode      bool   `json:"enable_debug_mode"`
+	StateFile            string `json:"state_file"`
+	ClockMode            string  `json:"clock_mode"`
+	ClockSpeed           float64 `json:"clock_speed"`
+	RandomSeed           *int64  `json:"random_seed"`
+	ExitDelay            int     `json:"exit_delay"`
+	EntryDelay           int     `json:"entry_delay"`
+	AlarmResponse        AlarmResponseConfig `json:"alarm_response"`
+	AlarmVerification    VerificationConfig  `json:"alarm_verification"`
+	CameraStorageDir     string  `json:"camera_storage_dir"`
+	CameraRetentionHours int     `json:"camera_retention_hours"`
+	CameraQuotaMB        int     `json:"camera_quota_mb"`
+	Locks                LockConfig `json:"locks"`
+	Currency             string              `json:"currency"`
+	Tariff               string              `json:"tariff"`
+	Tariffs              []models.TariffPlan `json:"tariffs"`
+	EnergyHistoryHours   int                 `json:"energy_history_hours"`
+	ForecastHistoryDays  int                 `json:"forecast_history_days"`
+	ForecastConfidence   float64             `json:"forecast_confidence"`
+	Budgets              []models.EnergyBudget `json:"budgets"`
+	BudgetAlertLevels    []float64             `json:"budget_alert_levels"`
+	BudgetAlertCooldown  int                   `json:"budget_alert_cooldown"`
+	LoadManagement       LoadConfig            `json:"load_management"`
+	EnergyFlow           EnergyFlowConfig      `json:"energy_flow"`
+	Anomalies            AnomalyConfig         `json:"anomalies"`
+	Weather              WeatherConfig         `json:"weather"`
+}
+
+type WeatherConfig struct {
+	Provider    string              `json:"provider"`
+	ReplayFile  string              `json:"replay_file"`
+	ReplaySpeed float64             `json:"replay_speed"`
+	ReplayLoop  bool                `json:"replay_loop"`
+	HTTPURL     string              `json:"http_url"`
+	HTTPTimeout int                 `json:"http_timeout"`
+	Alerts      WeatherAlertConfig  `json:"alerts"`
+	Policies    WeatherPolicyConfig `json:"policies"`
+}
+
+type WeatherPolicyConfig struct {
+	Precondition     PreconditionPolicy     `json:"precondition"`
+	SunShading       SunShadingPolicy       `json:"sun_shading"`
+	FreezeProtection FreezeProtectionPolicy `json:"freeze_protection"`
+	IrrigationPause  IrrigationPausePolicy  `json:"irrigation_pause"`
+}
+
+type PreconditionPolicy struct {
+	Enabled        bool    `json:"enabled"`
+	LookaheadHours int     `json:"lookahead_hours"`
+	ColdBelowC     float64 `json:"cold_below_c"`
+	HeatAboveC     float64 `json:"heat_above_c"`
+	OffsetC        float64 `json:"offset_c"`
+}
+
+type SunShadingPolicy struct {
+	Enabled  bool    `json:"enabled"`
+	AboveC   float64 `json:"above_c"`
+	Position int     `json:"position"`
+}
+
+type FreezeProtectionPolicy struct {
+	Enabled    bool    `json:"enabled"`
+	BelowC     float64 `json:"below_c"`
+	MinTargetC float64 `json:"min_target_c"`
+}
+
+type IrrigationPausePolicy struct {
+	Enabled        bool `json:"enabled"`
+	LookaheadHours int  `json:"lookahead_hours"`
+}
+
+type WeatherAlertConfig struct {
+	HeatC                float64 `json:"heat_c"`
+	SevereHeatC          float64 `json:"severe_heat_c"`
+	FreezeC              float64 `json:"freeze_c"`
+	SevereFreezeC        float64 `json:"severe_freeze_c"`
+	WindKmh              float64 `json:"wind_kmh"`
+	SevereWindKmh        float64 `json:"severe_wind_kmh"`
+	LowPressureHPa       float64 `json:"low_pressure_hpa"`
+	SevereLowPressureHPa float64 `json:"severe_low_pressure_hpa"`
+	ClearMinutes         int     `json:"clear_minutes"`
+}
+
+type AnomalyConfig struct {
+	Enabled            bool               `json:"enabled"`
+	Sigma              float64            `json:"sigma"`
+	MinRatio           float64            `json:"min_ratio"`
+	MinDurationMinutes int                `json:"min_duration_minutes"`
+	MinSamples         int                `json:"min_samples"`
+	LongRunHours       map[string]float64 `json:"long_run_hours"`
+	StandbyMinW        float64            `json:"standby_min_w"`
+	StandbyHours       int                `json:"standby_hours"`
+	StandbyTypes       []string           `json:"standby_types"`
+}
+
+type EnergyFlowConfig struct {
+	BatteryDispatch   models.DispatchPolicy `json:"battery_dispatch"`
+	BatteryReserve    float64               `json:"battery_reserve"`
+	BatteryEfficiency float64               `json:"battery_efficiency"`
+	ExportRate        float64               `json:"export_rate"`
+}
+
+type LoadConfig struct {
+	Enabled          bool     `json:"enabled"`
+	PowerLimitW      float64  `json:"power_limit_w"`
+	ShedOnPeak       bool     `json:"shed_on_peak"`
+	PeakBands        []string `json:"peak_bands"`
+	PeakMaxPriority  int      `json:"peak_max_priority"`
+	RestoreInterval  int      `json:"restore_interval"`
+	RestoreMargin    float64  `json:"restore_margin"`
+	DimBrightness    int      `json:"dim_brightness"`
+	ShedDeadband     float64  `json:"shed_deadband"`
+	GridEventMinutes int      `json:"grid_event_minutes"`
+}
+
+type LockConfig struct {
+	AutoLockDelay    int     `json:"auto_lock_delay"`
+	DoorOpenAlert    int     `json:"door_open_alert"`
+	DoorOpenEscalate int     `json:"door_open_escalate"`
+	JamChance        float64 `json:"jam_chance"`
+	LowBattery       int     `json:"low_battery"`
+}
+
+type VerificationConfig struct {
+	CrossZone            bool `json:"cross_zone"`
+	WindowSeconds        int  `json:"window_seconds"`
+	SensitivityWeighting bool `json:"sensitivity_weighting"`
+	SwingerLimit         int  `json:"swinger_limit"`
+}
+
+type AlarmResponseConfig struct {
+	Siren         bool             `json:"siren"`
+	RecordCameras bool             `json:"record_cameras"`
+	Lights        string           `json:"lights"`
+	Notify        []string         `json:"notify"`
+	Escalation    []EscalationStep `json:"escalation"`
+}
+
+type EscalationStep struct {
+	AfterSeconds int      `json:"after_seconds"`
+	Notify       []string `json:"notify"`
 }
 
 func Load() *Config {
@@ -30,6 +173,116 @@ func Load() *Config {
 		RateLimitRPS:         100,
 		MaxDevices:           50,
 		EnableDebugMode:      false,
+		ClockMode:            "real",
+		ClockSpeed:           1,
+		ExitDelay:            30,
+		EntryDelay:           30,
+		AlarmResponse: AlarmResponseConfig{
+			Siren:         true,
+			RecordCameras: true,
+			Lights:        "flash",
+			Notify:        []string{"push"},
+			Escalation: []EscalationStep{
+				{AfterSeconds: 30, Notify: []string{"sms"}},
+				{AfterSeconds: 120, Notify: []string{"monitoring_center"}},
+			},
+		},
+		AlarmVerification: VerificationConfig{
+			CrossZone:            false,
+			WindowSeconds:        60,
+			SensitivityWeighting: true,
+			SwingerLimit:         3,
+		},
+		CameraStorageDir:     "camera_data",
+		CameraRetentionHours: 72,
+		CameraQuotaMB:        100,
+		Locks: LockConfig{
+			AutoLockDelay:    30,
+			DoorOpenAlert:    120,
+			DoorOpenEscalate: 600,
+			JamChance:        0.02,
+			LowBattery:       20,
+		},
+		Currency: "USD",
+		Tariff:   "time_of_use",
+		Tariffs:  defaultTariffs(),
+		EnergyHistoryHours:  48,
+		ForecastHistoryDays: 28,
+		ForecastConfidence:  0.8,
+		Budgets: []models.EnergyBudget{
+			{ID: "household_daily", Name: "Household daily", Scope: models.BudgetGlobal, Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 40},
+		},
+		BudgetAlertLevels:   []float64{50, 80, 100},
+		BudgetAlertCooldown: 3600,
+		LoadManagement: LoadConfig{
+			Enabled:          true,
+			PowerLimitW:      5000,
+			ShedOnPeak:       true,
+			PeakBands:        []string{"afternoon"},
+			PeakMaxPriority:  2,
+			RestoreInterval:  60,
+			RestoreMargin:    0.9,
+			DimBrightness:    30,
+			ShedDeadband:     3,
+			GridEventMinutes: 15,
+		},
+		EnergyFlow: EnergyFlowConfig{
+			BatteryDispatch:   models.DispatchSelfConsumption,
+			BatteryReserve:    20,
+			BatteryEfficiency: 0.95,
+			ExportRate:        0.05,
+		},
+		Anomalies: AnomalyConfig{
+			Enabled:            true,
+			Sigma:              3,
+			MinRatio:           2,
+			MinDurationMinutes: 15,
+			MinSamples:         3,
+			LongRunHours:       map[string]float64{"light": 12},
+			StandbyMinW:        5,
+			StandbyHours:       24,
+			StandbyTypes:       []string{"light", "smart_plug", "ev_charger", "thermostat", "camera"},
+		},
+		Weather: WeatherConfig{
+			Provider:    "random",
+			ReplaySpeed: 1,
+			ReplayLoop:  true,
+			HTTPTimeout: 5,
+			Alerts: WeatherAlertConfig{
+				HeatC:                35,
+				SevereHeatC:          40,
+				FreezeC:              0,
+				SevereFreezeC:        -10,
+				WindKmh:              20,
+				SevereWindKmh:        35,
+				LowPressureHPa:       990,
+				SevereLowPressureHPa: 975,
+				ClearMinutes:         10,
+			},
+			Policies: WeatherPolicyConfig{
+				Precondition: PreconditionPolicy{
+					Enabled:        true,
+					LookaheadHours: 6,
+					ColdBelowC:     2,
+					HeatAboveC:     32,
+					OffsetC:        2,
+				},
+				SunShading: SunShadingPolicy{
+					Enabled:  true,
+					AboveC:   30,
+					Position: 0,
+				},
+				FreezeProtection: FreezeProtectionPolicy{
+					Enabled:    true,
+					BelowC:     0,
+					MinTargetC: 10,
+				},
+				IrrigationPause: IrrigationPausePolicy{
+					Enabled:        true,
+					LookaheadHours: 24,
+				},
+			},
+		},
 	}
 	
 	if configFile := os.Getenv("CONFIG_FILE"); configFile != "" {
@@ -39,10 +292,373 @@ func Load() *Config {
 	}
 	
 	loadFromEnv(cfg)
+	applyDefaults(cfg)
 	
 	return cfg
 }
 
+func applyDefaults(cfg *Config) {
+	if cfg.WeatherUpdateInterval <= 0 {
+		log.Printf("Invalid weather_update_interval %d, using 30", cfg.WeatherUpdateInterval)
+		cfg.WeatherUpdateInterval = 30
+	}
+	
+	switch cfg.Weather.Provider {
+	case "random":
+	case "replay":
+		if cfg.Weather.ReplayFile == "" {
+			log.Printf("weather.provider replay needs weather.replay_file, using random")
+			cfg.Weather.Provider = "random"
+		}
+	case "http":
+		if cfg.Weather.HTTPURL == "" {
+			log.Printf("weather.provider http needs weather.http_url, using random")
+			cfg.Weather.Provider = "random"
+		}
+	default:
+		log.Printf("Invalid weather.provider %q, using random", cfg.Weather.Provider)
+		cfg.Weather.Provider = "random"
+	}
+	
+	if cfg.Weather.ReplaySpeed <= 0 {
+		log.Printf("Invalid weather.replay_speed %v, using 1", cfg.Weather.ReplaySpeed)
+		cfg.Weather.ReplaySpeed = 1
+	}
+	
+	if cfg.Weather.HTTPTimeout <= 0 {
+		log.Printf("Invalid weather.http_timeout %d, using 5", cfg.Weather.HTTPTimeout)
+		cfg.Weather.HTTPTimeout = 5
+	}
+	
+	alerts := &cfg.Weather.Alerts
+	if alerts.SevereHeatC <= alerts.HeatC {
+		log.Printf("weather.alerts.severe_heat_c %v must be above heat_c %v, using %v", alerts.SevereHeatC, alerts.HeatC, alerts.HeatC+5)
+		alerts.SevereHeatC = alerts.HeatC + 5
+	}
+	
+	if alerts.SevereFreezeC >= alerts.FreezeC {
+		log.Printf("weather.alerts.severe_freeze_c %v must be below freeze_c %v, using %v", alerts.SevereFreezeC, alerts.FreezeC, alerts.FreezeC-10)
+		alerts.SevereFreezeC = alerts.FreezeC - 10
+	}
+	
+	if alerts.WindKmh <= 0 {
+		log.Printf("Invalid weather.alerts.wind_kmh %v, using 20", alerts.WindKmh)
+		alerts.WindKmh = 20
+	}
+	
+	if alerts.SevereWindKmh <= alerts.WindKmh {
+		log.Printf("weather.alerts.severe_wind_kmh %v must be above wind_kmh %v, using %v", alerts.SevereWindKmh, alerts.WindKmh, alerts.WindKmh+15)
+		alerts.SevereWindKmh = alerts.WindKmh + 15
+	}
+	
+	if alerts.SevereLowPressureHPa >= alerts.LowPressureHPa {
+		log.Printf("weather.alerts.severe_low_pressure_hpa %v must be below low_pressure_hpa %v, using %v", alerts.SevereLowPressureHPa, alerts.LowPressureHPa, alerts.LowPressureHPa-15)
+		alerts.SevereLowPressureHPa = alerts.LowPressureHPa - 15
+	}
+	
+	if alerts.ClearMinutes < 0 {
+		log.Printf("Invalid weather.alerts.clear_minutes %d, using 10", alerts.ClearMinutes)
+		alerts.ClearMinutes = 10
+	}
+	
+	policies := &cfg.Weather.Policies
+	if policies.Precondition.LookaheadHours <= 0 || policies.Precondition.LookaheadHours > 24 {
+		log.Printf("Invalid weather.policies.precondition.lookahead_hours %d, using 6", policies.Precondition.LookaheadHours)
+		policies.Precondition.LookaheadHours = 6
+	}
+	
+	if policies.Precondition.ColdBelowC >= policies.Precondition.HeatAboveC {
+		log.Printf("weather.policies.precondition.cold_below_c %v must be below heat_above_c %v, using 2 and 32", policies.Precondition.ColdBelowC, policies.Precondition.HeatAboveC)
+		policies.Precondition.ColdBelowC = 2
+		policies.Precondition.HeatAboveC = 32
+	}
+	
+	if policies.Precondition.OffsetC <= 0 {
+		log.Printf("Invalid weather.policies.precondition.offset_c %v, using 2", policies.Precondition.OffsetC)
+		policies.Precondition.OffsetC = 2
+	}
+	
+	if policies.SunShading.Position < 0 || policies.SunShading.Position > 100 {
+		log.Printf("Invalid weather.policies.sun_shading.position %d, using 0", policies.SunShading.Position)
+		policies.SunShading.Position = 0
+	}
+	
+	if policies.IrrigationPause.LookaheadHours <= 0 || policies.IrrigationPause.LookaheadHours > 168 {
+		log.Printf("Invalid weather.policies.irrigation_pause.lookahead_hours %d, using 24", policies.IrrigationPause.LookaheadHours)
+		policies.IrrigationPause.LookaheadHours = 24
+	}
+	
+	if cfg.EnergyUpdateInterval <= 0 {
+		log.Printf("Invalid energy_update_interval %d, using 10", cfg.EnergyUpdateInterval)
+		cfg.EnergyUpdateInterval = 10
+	}
+	
+	if cfg.SecurityTimeout <= 0 {
+		log.Printf("Invalid security_timeout %d, using 300", cfg.SecurityTimeout)
+		cfg.SecurityTimeout = 300
+	}
+	
+	if cfg.ExitDelay < 0 {
+		log.Printf("Invalid exit_delay %d, using 0", cfg.ExitDelay)
+		cfg.ExitDelay = 0
+	}
+	
+	if cfg.EntryDelay < 0 {
+		log.Printf("Invalid entry_delay %d, using 0", cfg.EntryDelay)
+		cfg.EntryDelay = 0
+	}
+	
+	switch cfg.AlarmResponse.Lights {
+	case "flash", "on", "off":
+	default:
+		log.Printf("Invalid alarm_response.lights %q, using flash", cfg.AlarmResponse.Lights)
+		cfg.AlarmResponse.Lights = "flash"
+	}
+	
+	steps := make([]EscalationStep, 0, len(cfg.AlarmResponse.Escalation))
+	for _, step := range cfg.AlarmResponse.Escalation {
+		if step.AfterSeconds <= 0 {
+			log.Printf("Ignoring alarm escalation step with after_seconds %d", step.AfterSeconds)
+			continue
+		}
+		steps = append(steps, step)
+	}
+	cfg.AlarmResponse.Escalation = steps
+	
+	if cfg.AlarmVerification.WindowSeconds <= 0 {
+		log.Printf("Invalid alarm_verification.window_seconds %d, using 60", cfg.AlarmVerification.WindowSeconds)
+		cfg.AlarmVerification.WindowSeconds = 60
+	}
+	
+	if cfg.AlarmVerification.SwingerLimit < 0 {
+		log.Printf("Invalid alarm_verification.swinger_limit %d, using 0", cfg.AlarmVerification.SwingerLimit)
+		cfg.AlarmVerification.SwingerLimit = 0
+	}
+	
+	if cfg.CameraStorageDir == "" {
+		cfg.CameraStorageDir = "camera_data"
+	}
+	
+	if cfg.CameraRetentionHours <= 0 {
+		log.Printf("Invalid camera_retention_hours %d, using 72", cfg.CameraRetentionHours)
+		cfg.CameraRetentionHours = 72
+	}
+	
+	if cfg.CameraQuotaMB <= 0 {
+		log.Printf("Invalid camera_quota_mb %d, using 100", cfg.CameraQuotaMB)
+		cfg.CameraQuotaMB = 100
+	}
+	
+	if cfg.Locks.AutoLockDelay <= 0 {
+		log.Printf("Invalid locks.auto_lock_delay %d, using 30", cfg.Locks.AutoLockDelay)
+		cfg.Locks.AutoLockDelay = 30
+	}
+	
+	if cfg.Locks.DoorOpenAlert <= 0 {
+		log.Printf("Invalid locks.door_open_alert %d, using 120", cfg.Locks.DoorOpenAlert)
+		cfg.Locks.DoorOpenAlert = 120
+	}
+	
+	if cfg.Locks.DoorOpenEscalate <= cfg.Locks.DoorOpenAlert {
+		log.Printf("Invalid locks.door_open_escalate %d, using %d", cfg.Locks.DoorOpenEscalate, cfg.Locks.DoorOpenAlert*5)
+		cfg.Locks.DoorOpenEscalate = cfg.Locks.DoorOpenAlert * 5
+	}
+	
+	if cfg.Locks.JamChance < 0 || cfg.Locks.JamChance > 1 {
+		log.Printf("Invalid locks.jam_chance %v, using 0.02", cfg.Locks.JamChance)
+		cfg.Locks.JamChance = 0.02
+	}
+	
+	if cfg.Locks.LowBattery <= 0 || cfg.Locks.LowBattery > 100 {
+		log.Printf("Invalid locks.low_battery %d, using 20", cfg.Locks.LowBattery)
+		cfg.Locks.LowBattery = 20
+	}
+	
+	if cfg.Currency == "" {
+		cfg.Currency = "USD"
+	}
+	
+	if len(cfg.Tariffs) == 0 {
+		cfg.Tariffs = defaultTariffs()
+	}
+	
+	plans := make([]models.TariffPlan, 0, len(cfg.Tariffs))
+	for _, plan := range cfg.Tariffs {
+		if err := plan.Validate(); err != nil {
+			log.Printf("Ignoring tariff: %v", err)
+			continue
+		}
+		if plan.Currency == "" {
+			plan.Currency = cfg.Currency
+		}
+		plans = append(plans, plan)
+	}
+	if len(plans) == 0 {
+		plans = defaultTariffs()
+	}
+	cfg.Tariffs = plans
+	
+	if cfg.EnergyHistoryHours <= 0 {
+		log.Printf("Invalid energy_history_hours %d, using 48", cfg.EnergyHistoryHours)
+		cfg.EnergyHistoryHours = 48
+	}
+	
+	if cfg.ForecastHistoryDays <= 0 {
+		log.Printf("Invalid forecast_history_days %d, using 28", cfg.ForecastHistoryDays)
+		cfg.ForecastHistoryDays = 28
+	}
+	
+	if _, ok := models.ForecastZScores[cfg.ForecastConfidence]; !ok {
+		log.Printf("Invalid forecast_confidence %v, using 0.8", cfg.ForecastConfidence)
+		cfg.ForecastConfidence = 0.8
+	}
+	
+	budgets := make([]models.EnergyBudget, 0, len(cfg.Budgets))
+	for _, budget := range cfg.Budgets {
+		if err := budget.Validate(); err != nil {
+			log.Printf("Ignoring budget %q: %v", budget.Name, err)
+			continue
+		}
+		budgets = append(budgets, budget)
+	}
+	cfg.Budgets = budgets
+	
+	levels := make([]float64, 0, len(cfg.BudgetAlertLevels))
+	for _, level := range cfg.BudgetAlertLevels {
+		if level <= 0 {
+			log.Printf("Ignoring budget alert level %v", level)
+			continue
+		}
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		levels = []float64{50, 80, 100}
+	}
+	cfg.BudgetAlertLevels = levels
+	
+	if cfg.BudgetAlertCooldown < 0 {
+		log.Printf("Invalid budget_alert_cooldown %d, using 3600", cfg.BudgetAlertCooldown)
+		cfg.BudgetAlertCooldown = 3600
+	}
+	
+	if cfg.LoadManagement.PowerLimitW <= 0 {
+		log.Printf("Invalid load_management.power_limit_w %v, using 5000", cfg.LoadManagement.PowerLimitW)
+		cfg.LoadManagement.PowerLimitW = 5000
+	}
+	
+	if cfg.LoadManagement.RestoreInterval <= 0 {
+		log.Printf("Invalid load_management.restore_interval %d, using 60", cfg.LoadManagement.RestoreInterval)
+		cfg.LoadManagement.RestoreInterval = 60
+	}
+	
+	if cfg.LoadManagement.RestoreMargin <= 0 || cfg.LoadManagement.RestoreMargin > 1 {
+		log.Printf("Invalid load_management.restore_margin %v, using 0.9", cfg.LoadManagement.RestoreMargin)
+		cfg.LoadManagement.RestoreMargin = 0.9
+	}
+	
+	if cfg.LoadManagement.DimBrightness < 0 || cfg.LoadManagement.DimBrightness > 100 {
+		log.Printf("Invalid load_management.dim_brightness %d, using 30", cfg.LoadManagement.DimBrightness)
+		cfg.LoadManagement.DimBrightness = 30
+	}
+	
+	if cfg.LoadManagement.ShedDeadband <= 1 {
+		log.Printf("Invalid load_management.shed_deadband %v, using 3", cfg.LoadManagement.ShedDeadband)
+		cfg.LoadManagement.ShedDeadband = 3
+	}
+	
+	if cfg.LoadManagement.GridEventMinutes <= 0 {
+		log.Printf("Invalid load_management.grid_event_minutes %d, using 15", cfg.LoadManagement.GridEventMinutes)
+		cfg.LoadManagement.GridEventMinutes = 15
+	}
+	
+	if !models.ValidDispatchPolicy(cfg.EnergyFlow.BatteryDispatch) {
+		log.Printf("Invalid energy_flow.battery_dispatch %q, using self_consumption", cfg.EnergyFlow.BatteryDispatch)
+		cfg.EnergyFlow.BatteryDispatch = models.DispatchSelfConsumption
+	}
+	
+	if cfg.EnergyFlow.BatteryReserve < 0 || cfg.EnergyFlow.BatteryReserve > 100 {
+		log.Printf("Invalid energy_flow.battery_reserve %v, using 20", cfg.EnergyFlow.BatteryReserve)
+		cfg.EnergyFlow.BatteryReserve = 20
+	}
+	
+	if cfg.EnergyFlow.BatteryEfficiency <= 0 || cfg.EnergyFlow.BatteryEfficiency > 1 {
+		log.Printf("Invalid energy_flow.battery_efficiency %v, using 0.95", cfg.EnergyFlow.BatteryEfficiency)
+		cfg.EnergyFlow.BatteryEfficiency = 0.95
+	}
+	
+	if cfg.Anomalies.Sigma <= 0 {
+		log.Printf("Invalid anomalies.sigma %v, using 3", cfg.Anomalies.Sigma)
+		cfg.Anomalies.Sigma = 3
+	}
+	
+	if cfg.Anomalies.MinRatio < 1 {
+		log.Printf("Invalid anomalies.min_ratio %v, using 2", cfg.Anomalies.MinRatio)
+		cfg.Anomalies.MinRatio = 2
+	}
+	
+	if cfg.Anomalies.MinDurationMinutes < 0 {
+		log.Printf("Invalid anomalies.min_duration_minutes %d, using 15", cfg.Anomalies.MinDurationMinutes)
+		cfg.Anomalies.MinDurationMinutes = 15
+	}
+	
+	if cfg.Anomalies.MinSamples < 2 {
+		log.Printf("Invalid anomalies.min_samples %d, using 3", cfg.Anomalies.MinSamples)
+		cfg.Anomalies.MinSamples = 3
+	}
+	
+	if cfg.Anomalies.StandbyHours <= 0 {
+		log.Printf("Invalid anomalies.standby_hours %d, using 24", cfg.Anomalies.StandbyHours)
+		cfg.Anomalies.StandbyHours = 24
+	}
+	
+	if cfg.EnergyFlow.ExportRate < 0 {
+		log.Printf("Invalid energy_flow.export_rate %v, using 0", cfg.EnergyFlow.ExportRate)
+		cfg.EnergyFlow.ExportRate = 0
+	}
+	
+	active := false
+	for _, plan := range cfg.Tariffs {
+		if plan.Name == cfg.Tariff {
+			active = true
+		}
+	}
+	if !active {
+		log.Printf("Unknown tariff %q, using %s", cfg.Tariff, cfg.Tariffs[0].Name)
+		cfg.Tariff = cfg.Tariffs[0].Name
+	}
+}
+
+func defaultTariffs() []models.TariffPlan {
+	return []models.TariffPlan{
+		{
+			Name:     "flat",
+			Type:     models.TariffFlat,
+			FlatRate: 0.12,
+		},
+		{
+			Name: "time_of_use",
+			Type: models.TariffTimeOfUse,
+			Bands: []models.TariffBand{
+				{Name: "night", Days: "weekday", Start: "21:00", End: "05:00", Rate: 0.084},
+				{Name: "morning", Days: "weekday", Start: "05:00", End: "12:00", Rate: 0.144},
+				{Name: "afternoon", Days: "weekday", Start: "12:00", End: "17:00", Rate: 0.18},
+				{Name: "evening", Days: "weekday", Start: "17:00", End: "21:00", Rate: 0.156},
+				{Name: "weekend", Days: "weekend", Start: "00:00", End: "24:00", Rate: 0.096},
+			},
+		},
+		{
+			Name: "tiered",
+			Type: models.TariffTiered,
+			Tiers: []models.TariffTier{
+				{UpToKWh: 300, Rate: 0.10},
+				{UpToKWh: 800, Rate: 0.14},
+				{UpToKWh: 0, Rate: 0.18},
+			},
+			DailyCharge: 0.50,
+		},
+	}
+}
+
 func loadFromFile(cfg *Config, filename string) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -73,6 +689,53 @@ func loadFromEnv(cfg *Config) {
 		}
 	}
 	
+	if provider := os.Getenv("WEATHER_PROVIDER"); provider != "" {
+		cfg.Weather.Provider = provider
+	}
+	
+	if file := os.Getenv("WEATHER_REPLAY_FILE"); file != "" {
+		cfg.Weather.ReplayFile = file
+	}
+	
+	if speed := os.Getenv("WEATHER_REPLAY_SPEED"); speed != "" {
+		if s, err := strconv.ParseFloat(speed, 64); err == nil {
+			cfg.Weather.ReplaySpeed = s
+		}
+	}
+	
+	if url := os.Getenv("WEATHER_HTTP_URL"); url != "" {
+		cfg.Weather.HTTPURL = url
+	}
+	
+	if names := os.Getenv("WEATHER_POLICIES"); names != "" {
+		enabled := make(map[string]bool)
+		for _, name := range strings.Split(names, ",") {
+			enabled[strings.TrimSpace(name)] = true
+		}
+		cfg.Weather.Policies.Precondition.Enabled = enabled[string(models.WeatherPolicyPrecondition)]
+		cfg.Weather.Policies.SunShading.Enabled = enabled[string(models.WeatherPolicySunShading)]
+		cfg.Weather.Policies.FreezeProtection.Enabled = enabled[string(models.WeatherPolicyFreezeProtection)]
+		cfg.Weather.Policies.IrrigationPause.Enabled = enabled[string(models.WeatherPolicyIrrigationPause)]
+	}
+	
+	if temp := os.Getenv("HEAT_ALERT_TEMP"); temp != "" {
+		if t, err := strconv.ParseFloat(temp, 64); err == nil {
+			cfg.Weather.Alerts.HeatC = t
+		}
+	}
+	
+	if temp := os.Getenv("FREEZE_ALERT_TEMP"); temp != "" {
+		if t, err := strconv.ParseFloat(temp, 64); err == nil {
+			cfg.Weather.Alerts.FreezeC = t
+		}
+	}
+	
+	if speed := os.Getenv("WIND_ALERT_SPEED"); speed != "" {
+		if s, err := strconv.ParseFloat(speed, 64); err == nil {
+			cfg.Weather.Alerts.WindKmh = s
+		}
+	}
+	
 	if interval := os.Getenv("ENERGY_UPDATE_INTERVAL"); interval != "" {
 		if i, err := strconv.Atoi(interval); err == nil {
 			cfg.EnergyUpdateInterval = i
@@ -100,6 +763,144 @@ func loadFromEnv(cfg *Config) {
 	if debug := os.Getenv("ENABLE_DEBUG_MODE"); debug == "true" {
 		cfg.EnableDebugMode = true
 	}
+	
+	if stateFile := os.Getenv("STATE_FILE"); stateFile != "" {
+		cfg.StateFile = stateFile
+	}
+	
+	if mode := os.Getenv("CLOCK_MODE"); mode != "" {
+		cfg.ClockMode = mode
+	}
+	
+	if speed := os.Getenv("CLOCK_SPEED"); speed != "" {
+		if s, err := strconv.ParseFloat(speed, 64); err == nil {
+			cfg.ClockSpeed = s
+		}
+	}
+	
+	if seed := os.Getenv("RANDOM_SEED"); seed != "" {
+		if s, err := strconv.ParseInt(seed, 10, 64); err == nil {
+			cfg.RandomSeed = &s
+		}
+	}
+	
+	if delay := os.Getenv("EXIT_DELAY"); delay != "" {
+		if d, err := strconv.Atoi(delay); err == nil {
+			cfg.ExitDelay = d
+		}
+	}
+	
+	if delay := os.Getenv("ENTRY_DELAY"); delay != "" {
+		if d, err := strconv.Atoi(delay); err == nil {
+			cfg.EntryDelay = d
+		}
+	}
+	
+	if lights := os.Getenv("ALARM_LIGHTS"); lights != "" {
+		cfg.AlarmResponse.Lights = lights
+	}
+	
+	if crossZone := os.Getenv("VERIFY_CROSS_ZONE"); crossZone != "" {
+		cfg.AlarmVerification.CrossZone = crossZone == "true"
+	}
+	
+	if limit := os.Getenv("SWINGER_LIMIT"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			cfg.AlarmVerification.SwingerLimit = l
+		}
+	}
+	
+	if dir := os.Getenv("CAMERA_STORAGE_DIR"); dir != "" {
+		cfg.CameraStorageDir = dir
+	}
+	
+	if hours := os.Getenv("CAMERA_RETENTION_HOURS"); hours != "" {
+		if h, err := strconv.Atoi(hours); err == nil {
+			cfg.CameraRetentionHours = h
+		}
+	}
+	
+	if quota := os.Getenv("CAMERA_QUOTA_MB"); quota != "" {
+		if q, err := strconv.Atoi(quota); err == nil {
+			cfg.CameraQuotaMB = q
+		}
+	}
+	
+	if delay := os.Getenv("AUTO_LOCK_DELAY"); delay != "" {
+		if d, err := strconv.Atoi(delay); err == nil {
+			cfg.Locks.AutoLockDelay = d
+		}
+	}
+	
+	if alert := os.Getenv("DOOR_OPEN_ALERT"); alert != "" {
+		if a, err := strconv.Atoi(alert); err == nil {
+			cfg.Locks.DoorOpenAlert = a
+		}
+	}
+	
+	if escalate := os.Getenv("DOOR_OPEN_ESCALATE"); escalate != "" {
+		if e, err := strconv.Atoi(escalate); err == nil {
+			cfg.Locks.DoorOpenEscalate = e
+		}
+	}
+	
+	if chance := os.Getenv("LOCK_JAM_CHANCE"); chance != "" {
+		if c, err := strconv.ParseFloat(chance, 64); err == nil {
+			cfg.Locks.JamChance = c
+		}
+	}
+	
+	if currency := os.Getenv("CURRENCY"); currency != "" {
+		cfg.Currency = currency
+	}
+	
+	if tariff := os.Getenv("TARIFF"); tariff != "" {
+		cfg.Tariff = tariff
+	}
+	
+	if cooldown := os.Getenv("BUDGET_ALERT_COOLDOWN"); cooldown != "" {
+		if c, err := strconv.Atoi(cooldown); err == nil {
+			cfg.BudgetAlertCooldown = c
+		}
+	}
+	
+	if enabled := os.Getenv("LOAD_SHEDDING"); enabled != "" {
+		cfg.LoadManagement.Enabled = enabled == "true"
+	}
+	
+	if limit := os.Getenv("LOAD_POWER_LIMIT"); limit != "" {
+		if l, err := strconv.ParseFloat(limit, 64); err == nil {
+			cfg.LoadManagement.PowerLimitW = l
+		}
+	}
+	
+	if sigma := os.Getenv("ANOMALY_SIGMA"); sigma != "" {
+		if s, err := strconv.ParseFloat(sigma, 64); err == nil {
+			cfg.Anomalies.Sigma = s
+		}
+	}
+	
+	if dispatch := os.Getenv("BATTERY_DISPATCH"); dispatch != "" {
+		cfg.EnergyFlow.BatteryDispatch = models.DispatchPolicy(dispatch)
+	}
+	
+	if rate := os.Getenv("EXPORT_RATE"); rate != "" {
+		if r, err := strconv.ParseFloat(rate, 64); err == nil {
+			cfg.EnergyFlow.ExportRate = r
+		}
+	}
+	
+	if hours := os.Getenv("ENERGY_HISTORY_HOURS"); hours != "" {
+		if h, err := strconv.Atoi(hours); err == nil {
+			cfg.EnergyHistoryHours = h
+		}
+	}
+	
+	if days := os.Getenv("FORECAST_HISTORY_DAYS"); days != "" {
+		if d, err := strconv.Atoi(days); err == nil {
+			cfg.ForecastHistoryDays = d
+		}
+	}
 }
 
 func (c *Config) SaveToFile(filename string) error {
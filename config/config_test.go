@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadReadsRuntimeSettingsFromEnv(t *testing.T) {
+	t.Setenv("AUTH_TOKEN", "test-token")
+	t.Setenv("WEATHER_UPDATE_INTERVAL", "45")
+	t.Setenv("ENERGY_UPDATE_INTERVAL", "15")
+	t.Setenv("SECURITY_TIMEOUT", "90")
+	t.Setenv("RATE_LIMIT_RPS", "0")
+	t.Setenv("MAX_DEVICES", "3")
+	t.Setenv("ENABLE_DEBUG_MODE", "true")
+	
+	cfg := Load()
+	
+	if cfg.AuthToken != "test-token" {
+		t.Errorf("auth token = %q, want test-token", cfg.AuthToken)
+	}
+	if cfg.WeatherUpdateInterval != 45 {
+		t.Errorf("weather update interval = %d, want 45", cfg.WeatherUpdateInterval)
+	}
+	if cfg.EnergyUpdateInterval != 15 {
+		t.Errorf("energy update interval = %d, want 15", cfg.EnergyUpdateInterval)
+	}
+	if cfg.SecurityTimeout != 90 {
+		t.Errorf("security timeout = %d, want 90", cfg.SecurityTimeout)
+	}
+	if cfg.RateLimitRPS != 0 {
+		t.Errorf("rate limit = %d, want 0 to disable limiting", cfg.RateLimitRPS)
+	}
+	if cfg.MaxDevices != 3 {
+		t.Errorf("max devices = %d, want 3", cfg.MaxDevices)
+	}
+	if !cfg.EnableDebugMode {
+		t.Error("debug mode disabled, want enabled")
+	}
+}
+
+func TestLoadReadsRuntimeSettingsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"auth_token": "file-token", "weather_update_interval": 60, "energy_update_interval": 20, "security_timeout": 600, "rate_limit_rps": 5, "max_devices": 10, "enable_debug_mode": true}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	
+	cfg := Load()
+	
+	if cfg.AuthToken != "file-token" || cfg.WeatherUpdateInterval != 60 || cfg.EnergyUpdateInterval != 20 ||
+		cfg.SecurityTimeout != 600 || cfg.RateLimitRPS != 5 || cfg.MaxDevices != 10 || !cfg.EnableDebugMode {
+		t.Errorf("config = %+v, want the values from %s", cfg, path)
+	}
+}
+
+func TestLoadReplacesInvalidIntervals(t *testing.T) {
+	t.Setenv("WEATHER_UPDATE_INTERVAL", "0")
+	t.Setenv("ENERGY_UPDATE_INTERVAL", "-5")
+	t.Setenv("SECURITY_TIMEOUT", "0")
+	
+	cfg := Load()
+	
+	if cfg.WeatherUpdateInterval != 30 {
+		t.Errorf("weather update interval = %d, want the default 30", cfg.WeatherUpdateInterval)
+	}
+	if cfg.EnergyUpdateInterval != 10 {
+		t.Errorf("energy update interval = %d, want the default 10", cfg.EnergyUpdateInterval)
+	}
+	if cfg.SecurityTimeout != 300 {
+		t.Errorf("security timeout = %d, want the default 300", cfg.SecurityTimeout)
+	}
+}
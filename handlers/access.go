@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) ListSecurityUsers(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.access.ListUsers(),
+	})
+}
+
+func (h *Handler) CreateSecurityUser(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name      string                 `json:"name"`
+		PIN       string                 `json:"pin"`
+		DuressPIN string                 `json:"duress_pin"`
+		Schedule  *models.AccessSchedule `json:"schedule"`
+		AdminPIN  string                 `json:"admin_pin"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	
+	actor, err := h.access.AuthorizeAdmin(request.AdminPIN, "create_user", "security")
+	if err != nil {
+		h.respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	
+	user, err := h.access.CreateUser(request.Name, request.PIN, request.DuressPIN, request.Schedule)
+	if err != nil {
+		h.access.Audit(actor, "create_user", "security", false, err.Error())
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.access.Audit(actor, "create_user", user.ID, true, user.Name)
+	
+	h.respondWithJSON(w, http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    user,
+		Message: "Security user created successfully",
+	})
+}
+
+func (h *Handler) DeleteSecurityUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	var request struct {
+		AdminPIN string `json:"admin_pin"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	
+	actor, err := h.access.AuthorizeAdmin(request.AdminPIN, "delete_user", vars["id"])
+	if err != nil {
+		h.respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	
+	if err := h.access.DeleteUser(vars["id"]); err != nil {
+		h.access.Audit(actor, "delete_user", vars["id"], false, err.Error())
+		h.respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h.access.Audit(actor, "delete_user", vars["id"], true, "")
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Security user deleted successfully",
+	})
+}
+
+func (h *Handler) SetLockCode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	var request struct {
+		Code     string `json:"code"`
+		AdminPIN string `json:"admin_pin"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	
+	actor, err := h.access.AuthorizeAdmin(request.AdminPIN, "set_lock_code", vars["lock"])
+	if err != nil {
+		h.respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	
+	if err := h.access.SetLockCode(vars["id"], vars["lock"], request.Code); err != nil {
+		h.access.Audit(actor, "set_lock_code", vars["lock"], false, err.Error())
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.access.Audit(actor, "set_lock_code", vars["lock"], true, "user "+vars["id"])
+	
+	message := "Lock access code set"
+	if request.Code == "" {
+		message = "Lock access code removed"
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: message,
+	})
+}
+
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.access.GetAuditLog(limit),
+	})
+}
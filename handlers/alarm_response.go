@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetAlarmResponse(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.alarmResponse.Status(),
+	})
+}
+
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.notifier.GetNotifications(limit),
+	})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetEnergyAnomalies(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	
+	status := models.AnomalyStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.AnomalyOpen, models.AnomalyAcknowledged, models.AnomalyDismissed, models.AnomalyResolved:
+	default:
+		h.respondWithError(w, http.StatusBadRequest, "status must be open, acknowledged, dismissed or resolved")
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.anomalies.List(status, r.URL.Query().Get("device"), limit),
+	})
+}
+
+func (h *Handler) UpdateEnergyAnomaly(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	var anomaly models.EnergyAnomaly
+	var err error
+	var message string
+	switch vars["action"] {
+	case "acknowledge":
+		anomaly, err = h.anomalies.Acknowledge(vars["id"])
+		message = "Anomaly acknowledged"
+	case "dismiss":
+		anomaly, err = h.anomalies.Dismiss(vars["id"])
+		message = "Anomaly dismissed"
+	default:
+		h.respondWithError(w, http.StatusNotFound, "unknown anomaly action: "+vars["action"])
+		return
+	}
+	
+	if err != nil {
+		status := http.StatusNotFound
+		if anomaly.ID != "" {
+			status = http.StatusConflict
+		}
+		h.respondWithError(w, status, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    anomaly,
+		Message: message,
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetBudgets(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.budgets.Statuses(),
+	})
+}
+
+func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var budget models.EnergyBudget
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	
+	if budget.Scope == models.BudgetDevice {
+		if _, err := h.deviceService.GetDevice(budget.Target); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	
+	budget, err := h.budgets.Add(budget)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    budget,
+		Message: "Budget created",
+	})
+}
+
+func (h *Handler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	if err := h.budgets.Remove(vars["id"]); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Budget deleted",
+	})
+}
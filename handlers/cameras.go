@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetCameraEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	if _, err := h.cameraService.GetCamera(vars["id"]); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.cameraService.GetEvents(vars["id"], limit),
+	})
+}
+
+func (h *Handler) GetCameraThumbnail(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	event, err := h.cameraService.GetEvent(vars["id"], vars["eid"])
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	
+	if event.ThumbnailPath == "" {
+		h.respondWithError(w, http.StatusNotFound, "Thumbnail not available for this event")
+		return
+	}
+	
+	data, err := os.ReadFile(event.ThumbnailPath)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, "Thumbnail not available for this event")
+		return
+	}
+	
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
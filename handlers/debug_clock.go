@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetClock(w http.ResponseWriter, r *http.Request) {
+	simulated, ok := h.clock.(*clock.Simulated)
+	if !ok {
+		h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"mode": "real",
+				"now":  h.clock.Now(),
+			},
+		})
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    simulated.State(),
+	})
+}
+
+func (h *Handler) ControlClock(w http.ResponseWriter, r *http.Request) {
+	simulated, ok := h.clock.(*clock.Simulated)
+	if !ok {
+		h.respondWithError(w, http.StatusConflict, "Clock is not simulated; start the hub with CLOCK_MODE=simulated")
+		return
+	}
+	
+	var request struct {
+		Duration string  `json:"duration"`
+		Speed    float64 `json:"speed"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	
+	action := mux.Vars(r)["action"]
+	switch action {
+	case "pause":
+		simulated.Pause()
+		
+	case "resume":
+		simulated.Resume()
+		
+	case "step":
+		duration, err := time.ParseDuration(request.Duration)
+		if err != nil || duration <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "A positive duration such as \"5m\" or \"24h\" is required")
+			return
+		}
+		simulated.Step(duration)
+		
+	case "speed":
+		if request.Speed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Speed must be greater than zero")
+			return
+		}
+		simulated.SetSpeed(request.Speed)
+		
+	default:
+		h.respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Unknown clock action: %s", action))
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    simulated.State(),
+		Message: fmt.Sprintf("Clock %s applied", action),
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/utils"
+)
+
+func (h *Handler) GetTariffs(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"active": h.tariffs.Active().Name,
+			"plans":  h.tariffs.Plans(),
+		},
+	})
+}
+
+func (h *Handler) SetActiveTariff(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	
+	if err := h.tariffs.SetActive(request.Name); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.tariffs.Active(),
+		Message: "Active tariff updated",
+	})
+}
+
+func (h *Handler) TariffWhatIf(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Plan   string             `json:"plan"`
+		Tariff *models.TariffPlan `json:"tariff"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	
+	var candidate models.TariffPlan
+	switch {
+	case request.Tariff != nil:
+		candidate = *request.Tariff
+		if err := candidate.Validate(); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if candidate.Currency == "" {
+			candidate.Currency = h.config.Currency
+		}
+		
+	case request.Plan != "":
+		plan, err := h.tariffs.Plan(request.Plan)
+		if err != nil {
+			h.respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		candidate = plan
+		
+	default:
+		h.respondWithError(w, http.StatusBadRequest, "Either plan or tariff is required")
+		return
+	}
+	
+	history := h.store.GetEnergyUsage(0)
+	current := h.tariffs.Quote(h.tariffs.Active(), history)
+	proposed := h.tariffs.Quote(candidate, history)
+	
+	response := map[string]interface{}{
+		"samples":  len(history),
+		"current":  current,
+		"proposed": proposed,
+	}
+	if current.Currency == proposed.Currency {
+		response["difference"] = utils.RoundToDecimal(proposed.TotalCost-current.TotalCost, 4)
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (h *Handler) GetEnergyReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	
+	to := h.clock.Now()
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	
+	from := to.Add(-24 * time.Hour)
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+		from = parsed
+	}
+	
+	groupBy := models.GroupByDevice
+	if value := query.Get("group_by"); value != "" {
+		groupBy = models.EnergyGroupBy(value)
+	}
+	
+	report, err := h.reports.Report(from, to, groupBy)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	
+	if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		h.writeEnergyReportCSV(w, report)
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+func (h *Handler) writeEnergyReportCSV(w http.ResponseWriter, report models.EnergyReport) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=energy-report-%s.csv", report.From.Format("20060102T150405")))
+	w.WriteHeader(http.StatusOK)
+	
+	writer := csv.NewWriter(w)
+	writer.Write([]string{string(report.GroupBy), "label", "energy_wh", "cost", "currency", "peak_demand_w", "peak_at", "average_power_w", "share_percent", "samples"})
+	
+	row := func(key, label string, totals models.EnergyTotals, share float64) []string {
+		peakAt := ""
+		if !totals.PeakAt.IsZero() {
+			peakAt = totals.PeakAt.Format(time.RFC3339)
+		}
+		return []string{
+			key,
+			label,
+			strconv.FormatFloat(totals.EnergyWh, 'f', -1, 64),
+			strconv.FormatFloat(totals.Cost, 'f', -1, 64),
+			report.Currency,
+			strconv.FormatFloat(totals.PeakDemandW, 'f', -1, 64),
+			peakAt,
+			strconv.FormatFloat(totals.AveragePowerW, 'f', -1, 64),
+			strconv.FormatFloat(share, 'f', -1, 64),
+			strconv.Itoa(totals.Samples),
+		}
+	}
+	
+	for _, group := range report.Groups {
+		writer.Write(row(group.Key, group.Label, group.EnergyTotals, group.SharePercent))
+	}
+	writer.Write(row("total", "Total", report.Totals, 100))
+	writer.Write(row("previous", "Previous period", report.Previous.EnergyTotals, 0))
+	writer.Flush()
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetEnergyFlow(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.flows.Status(),
+	})
+}
+
+func (h *Handler) GetEnergyFlowHistory(w http.ResponseWriter, r *http.Request) {
+	hours := 24
+	if value := r.URL.Query().Get("hours"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > 168 {
+			h.respondWithError(w, http.StatusBadRequest, "hours must be an integer between 1 and 168")
+			return
+		}
+		hours = parsed
+	}
+	
+	to := h.clock.Now()
+	from := to.Add(-time.Duration(hours) * time.Hour)
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"summary": h.flows.Summary(from, to),
+			"hourly":  h.flows.Hourly(from, to),
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetEnergyForecast(w http.ResponseWriter, r *http.Request) {
+	forecast := *h.forecasts.Forecast()
+	
+	if deviceID := r.URL.Query().Get("device"); deviceID != "" {
+		device := forecast.Device(deviceID)
+		if device == nil {
+			h.respondWithError(w, http.StatusNotFound, "no forecast for device "+deviceID)
+			return
+		}
+		forecast.Devices = []models.DeviceForecast{*device}
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    forecast,
+	})
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -11,9 +14,14 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
 	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/rules"
 	"multi-agent-framework-testing/services"
 	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
 	"multi-agent-framework-testing/workers"
 )
 
@@ -21,28 +29,82 @@ type Handler struct {
 	store          *storage.MemoryStore
 	deviceService  *services.DeviceService
 	weatherService *services.WeatherService
+	security       *services.SecurityService
+	access         *services.AccessService
+	alarmResponse  *services.AlarmResponseService
+	notifier       *services.NotificationService
+	cameraService  *services.CameraService
+	locks          *services.LockService
+	safety         *services.LifeSafetyService
+	tariffs        *services.TariffService
+	reports        *services.EnergyReportService
+	budgets        *services.BudgetService
+	loads          *services.LoadService
+	flows          *services.EnergyFlowService
+	forecasts      *services.ForecastService
+	anomalies      *services.AnomalyService
 	scheduler      *workers.Scheduler
 	upgrader       *websocket.Upgrader
 	wsClients      map[*websocket.Conn]bool
 	wsClientsMu    sync.RWMutex
-	rateLimiter    map[string]time.Time
+	rateLimiter    map[string]*rateLimitEntry
 	rateLimiterMu  sync.Mutex
+	clock          clock.Clock
+	random         *random.Source
+	config         *config.Config
+}
+
+type rateLimitEntry struct {
+	limiter  *utils.RateLimiter
+	lastSeen time.Time
 }
 
 func NewHandler(store *storage.MemoryStore, deviceService *services.DeviceService, 
-	weatherService *services.WeatherService, scheduler *workers.Scheduler, 
-	upgrader *websocket.Upgrader) *Handler {
+	weatherService *services.WeatherService, securityService *services.SecurityService, accessService *services.AccessService, 
+	alarmResponse *services.AlarmResponseService, notifier *services.NotificationService, 
+	cameraService *services.CameraService, lockService *services.LockService, 
+	safetyService *services.LifeSafetyService, 
+	tariffService *services.TariffService, reportService *services.EnergyReportService, budgetService *services.BudgetService, loadService *services.LoadService, flowService *services.EnergyFlowService, forecastService *services.ForecastService, anomalyService *services.AnomalyService, scheduler *workers.Scheduler, 
+	upgrader *websocket.Upgrader, clk clock.Clock, src *random.Source, cfg *config.Config) *Handler {
 	
 	handler := &Handler{
 		store:          store,
 		deviceService:  deviceService,
 		weatherService: weatherService,
+		security:       securityService,
+		access:         accessService,
+		alarmResponse:  alarmResponse,
+		notifier:       notifier,
+		cameraService:  cameraService,
+		locks:          lockService,
+		safety:         safetyService,
+		tariffs:        tariffService,
+		reports:        reportService,
+		budgets:        budgetService,
+		loads:          loadService,
+		flows:          flowService,
+		forecasts:      forecastService,
+		anomalies:      anomalyService,
 		scheduler:      scheduler,
 		upgrader:       upgrader,
 		wsClients:      make(map[*websocket.Conn]bool),
-		rateLimiter:    make(map[string]time.Time),
+		rateLimiter:    make(map[string]*rateLimitEntry),
+		clock:          clk,
+		random:         src,
+		config:         cfg,
 	}
 	
+	securityService.OnTransition(func(transition models.SecurityTransition, security models.SecuritySystem) {
+		handler.broadcastMessage("security_state_changed", map[string]interface{}{
+			"transition": transition,
+			"security":   security,
+		})
+	})
+	
+	notifier.OnNotify(func(notification models.Notification) {
+		handler.broadcastMessage("notification", notification)
+	})
+	
 	go handler.broadcastUpdates()
 	
 	return handler
@@ -50,6 +112,11 @@ func NewHandler(store *storage.MemoryStore, deviceService *services.DeviceServic
 
 func (h *Handler) LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.config.LogLevel != "debug" && h.config.LogLevel != "info" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		
 		start := time.Now()
 		log.Printf("[%s] %s %s", r.Method, r.URL.Path, r.RemoteAddr)
 		next.ServeHTTP(w, r)
@@ -71,7 +138,7 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 		}
 		
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token != "smarthome-secret-token" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.config.AuthToken)) != 1 {
 			h.respondWithError(w, http.StatusUnauthorized, "Invalid token")
 			return
 		}
@@ -82,22 +149,33 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 
 func (h *Handler) RateLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.config.RateLimitRPS <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		
 		clientIP := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
 		
 		h.rateLimiterMu.Lock()
-		lastRequest, exists := h.rateLimiter[clientIP]
+		entry, exists := h.rateLimiter[clientIP]
+		if !exists {
+			entry = &rateLimitEntry{limiter: utils.NewRateLimiter(h.config.RateLimitRPS, time.Second)}
+			h.rateLimiter[clientIP] = entry
+		}
+		entry.lastSeen = time.Now()
 		
-		if exists && time.Since(lastRequest) < time.Millisecond*10 {
+		if !entry.limiter.Allow() {
 			h.rateLimiterMu.Unlock()
 			h.respondWithError(w, http.StatusTooManyRequests, "Rate limit exceeded")
 			return
 		}
 		
-		h.rateLimiter[clientIP] = time.Now()
-		
 		if len(h.rateLimiter) > 10000 {
-			for ip, timestamp := range h.rateLimiter {
-				if time.Since(timestamp) > time.Minute {
+			for ip, e := range h.rateLimiter {
+				if time.Since(e.lastSeen) > time.Minute {
 					delete(h.rateLimiter, ip)
 				}
 			}
@@ -124,7 +202,7 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		"online_devices": h.countOnlineDevices(devices),
 		"weather_status": state.Weather.Condition,
 		"security_state": state.Security.State,
-		"timestamp":      time.Now(),
+		"timestamp":      h.clock.Now(),
 	}
 	
 	h.respondWithJSON(w, http.StatusOK, health)
@@ -174,11 +252,49 @@ func (h *Handler) UpdateDevice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	if err := h.deviceService.UpdateDevice(deviceID, updates); err != nil {
+	existing, err := h.deviceService.GetDevice(deviceID)
+	if err != nil {
 		h.respondWithError(w, http.StatusNotFound, err.Error())
 		return
 	}
 	
+	if value, ok := updates["locked"]; ok {
+		if _, ok := value.(bool); !ok {
+			h.respondWithError(w, http.StatusBadRequest, "locked must be true or false")
+			return
+		}
+	}
+	
+	code, _ := updates["code"].(string)
+	delete(updates, "code")
+	
+	var user *models.SecurityUser
+	locked, ok := updates["locked"].(bool)
+	unlocking := existing.Type == models.DeviceTypeLock && ok && !locked
+	if unlocking && (h.access.Required() || code != "") {
+		user, err = h.access.AuthenticateLock(deviceID, code)
+		if err != nil {
+			h.respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
+	}
+	
+	if err := h.deviceService.UpdateDevice(deviceID, updates); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrLockFailed) {
+			status = http.StatusConflict
+			if unlocking {
+				h.access.Audit(user, "unlock", deviceID, false, err.Error())
+			}
+		}
+		h.respondWithError(w, status, err.Error())
+		return
+	}
+	
+	if unlocking {
+		h.access.Audit(user, "unlock", deviceID, true, "")
+	}
+	
 	device, _ := h.deviceService.GetDevice(deviceID)
 	h.broadcastMessage("device_updated", device)
 	
@@ -196,6 +312,8 @@ func (h *Handler) GetWeather(w http.ResponseWriter, r *http.Request) {
 		"current":  weather,
 		"forecast": h.weatherService.GetForecast(5),
 		"alert":    h.weatherService.GetWeatherAlert(),
+		"alerts":   h.weatherService.ActiveAlerts(),
+		"provider": h.weatherService.ProviderName(),
 	}
 	
 	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
@@ -206,21 +324,21 @@ func (h *Handler) GetWeather(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) GetEnergyUsage(w http.ResponseWriter, r *http.Request) {
 	usage := h.store.GetEnergyUsage(100)
-	currentUsage := h.deviceService.CalculateEnergyUsage()
-	
-	totalUsage := 0.0
-	totalCost := 0.0
-	for _, u := range currentUsage {
-		totalUsage += u.Usage
-		totalCost += u.Cost
-	}
+	currentPower := h.deviceService.CalculatePower()
+	energyCost := h.calculateTotalCost(usage)
+	fixedCharges := h.tariffs.FixedCharges(h.tariffs.Active(), usage)
 	
 	response := map[string]interface{}{
-		"current_usage":     currentUsage,
+		"current_power":     currentPower,
+		"current_power_w":   h.calculateTotalPower(currentPower),
 		"historical_usage":  usage,
-		"total_usage_kwh":   totalUsage,
-		"total_cost_usd":    totalCost,
-		"usage_by_device":   h.aggregateUsageByDevice(usage),
+		"total_energy_wh":   h.calculateTotalEnergy(usage),
+		"energy_cost":       energyCost,
+		"fixed_charges":     fixedCharges,
+		"total_cost":        utils.RoundToDecimal(energyCost+fixedCharges, 4),
+		"currency":          h.tariffs.Active().Currency,
+		"tariff":            h.tariffs.Active().Name,
+		"energy_by_device":  h.aggregateEnergyByDevice(usage),
 	}
 	
 	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
@@ -230,34 +348,96 @@ func (h *Handler) GetEnergyUsage(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) ArmSecurity(w http.ResponseWriter, r *http.Request) {
-	security := h.store.GetSecurity()
-	security.State = models.SecurityStateArmed
-	security.LastArmed = time.Now()
+	var request struct {
+		Mode   models.ArmMode `json:"mode"`
+		Bypass bool           `json:"bypass"`
+		PIN    string         `json:"pin"`
+	}
+	
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
 	
-	sensors := h.deviceService.GetDevicesByType(models.DeviceTypeSensor)
-	security.ActiveSensors = make([]string, 0)
-	for _, sensor := range sensors {
-		if sensor.Status == models.DeviceStatusOnline {
-			security.ActiveSensors = append(security.ActiveSensors, sensor.ID)
+	if _, ok := models.ArmModePolicies[request.Mode]; request.Mode != "" && !ok {
+		h.respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Unknown arm mode: %s", request.Mode))
+		return
+	}
+	
+	var user *models.SecurityUser
+	source := "api"
+	if request.PIN != "" {
+		authenticated, _, err := h.access.Authenticate(request.PIN, "arm", "security")
+		if err != nil {
+			h.respondWithError(w, http.StatusForbidden, err.Error())
+			return
 		}
+		user = authenticated
+		source = user.Name
+	}
+	
+	security, err := h.security.Arm(source, request.Mode, request.Bypass)
+	if err != nil {
+		h.access.Audit(user, "arm", "security", false, err.Error())
+		h.respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	h.access.Audit(user, "arm", "security", true, string(security.Mode))
+	
+	message := fmt.Sprintf("Security system armed (%s)", security.Mode)
+	if security.State == models.SecurityStateArming {
+		message = fmt.Sprintf("Security system arming (%s), exit delay ends at %s", security.Mode, security.DelayEndsAt.Format(time.RFC3339))
 	}
 	
-	h.store.UpdateSecurity(security)
 	h.broadcastMessage("security_armed", security)
 	
 	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    security,
-		Message: "Security system armed",
+		Message: message,
 	})
 }
 
 func (h *Handler) DisarmSecurity(w http.ResponseWriter, r *http.Request) {
-	security := h.store.GetSecurity()
-	security.State = models.SecurityStateDisarmed
-	security.ActiveSensors = []string{}
+	var request struct {
+		PIN string `json:"pin"`
+	}
+	
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	
+	var user *models.SecurityUser
+	duress := false
+	source := "api"
+	if h.access.Required() || request.PIN != "" {
+		authenticated, isDuress, err := h.access.Authenticate(request.PIN, "disarm", "security")
+		if err != nil {
+			h.respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		user = authenticated
+		duress = isDuress
+		source = user.Name
+	}
+	
+	security, err := h.security.Disarm(source)
+	if err != nil {
+		h.access.Audit(user, "disarm", "security", false, err.Error())
+		h.respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	h.access.Audit(user, "disarm", "security", true, "")
+	
+	if duress {
+		h.access.RaiseDuress(user, "security")
+	}
 	
-	h.store.UpdateSecurity(security)
 	h.broadcastMessage("security_disarmed", security)
 	
 	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
@@ -270,6 +450,7 @@ func (h *Handler) DisarmSecurity(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
 	devices := h.deviceService.ListDevices()
 	energyUsage := h.store.GetEnergyUsage(500)
+	currentPower := h.deviceService.CalculatePower()
 	events := h.store.GetSystemEvents(100)
 	weather := h.weatherService.GetCurrentWeather()
 	tasks := h.scheduler.ListTasks()
@@ -278,13 +459,16 @@ func (h *Handler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
 		TotalDevices:     len(devices),
 		OnlineDevices:    h.countOnlineDevices(devices),
 		OfflineDevices:   h.countOfflineDevices(devices),
-		TotalEnergyUsage: h.calculateTotalEnergy(energyUsage),
+		CurrentPowerW:    h.calculateTotalPower(currentPower),
+		TotalEnergyWh:    h.calculateTotalEnergy(energyUsage),
 		TotalEnergyCost:  h.calculateTotalCost(energyUsage),
-		DeviceUsage:      h.aggregateUsageByDevice(energyUsage),
+		Currency:         h.tariffs.Active().Currency,
+		DevicePowerW:     h.aggregatePowerByDevice(currentPower),
+		DeviceEnergyWh:   h.aggregateEnergyByDevice(energyUsage),
 		SecurityEvents:   h.countSecurityEvents(events),
 		ScheduledTasks:   len(tasks),
 		WeatherSummary:   *weather,
-		GeneratedAt:      time.Now(),
+		GeneratedAt:      h.clock.Now(),
 	}
 	
 	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
@@ -305,7 +489,22 @@ func (h *Handler) CreateScheduledTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	switch task.MisfirePolicy {
+	case "", models.MisfireRunOnce, models.MisfireRunAll, models.MisfireSkip:
+	default:
+		h.respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Unknown misfire policy: %s", task.MisfirePolicy))
+		return
+	}
+	
 	if err := h.scheduler.AddTask(&task); err != nil {
+		if ruleErr, ok := err.(*rules.Error); ok {
+			h.respondWithRuleError(w, ruleErr)
+			return
+		}
+		if errors.Is(err, services.ErrAccessDenied) {
+			h.respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		h.respondWithError(w, http.StatusConflict, err.Error())
 		return
 	}
@@ -317,14 +516,105 @@ func (h *Handler) CreateScheduledTask(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskID := vars["id"]
+	
+	task, err := h.scheduler.GetTask(taskID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"task":    task,
+			"history": h.store.GetTaskHistory(taskID, 100),
+		},
+	})
+}
+
+func (h *Handler) CreateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name      string              `json:"name"`
+		Condition string              `json:"condition"`
+		Actions   []models.TestAction `json:"actions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	
+	if request.Name == "" || request.Condition == "" || len(request.Actions) == 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Rule name, condition, and at least one action are required")
+		return
+	}
+	
+	task, err := h.scheduler.CreateAutomationRule(request.Name, request.Condition, request.Actions)
+	if err != nil {
+		if ruleErr, ok := err.(*rules.Error); ok {
+			h.respondWithRuleError(w, ruleErr)
+			return
+		}
+		if errors.Is(err, services.ErrAccessDenied) {
+			h.respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		h.respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    task,
+		Message: "Automation rule created successfully",
+	})
+}
+
 func (h *Handler) DebugState(w http.ResponseWriter, r *http.Request) {
 	state := h.store.GetSystemState()
+	state.RandomSeed = h.random.Seed()
 	h.respondWithJSON(w, http.StatusOK, state)
 }
 
+func (h *Handler) SetSeed(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Seed *int64 `json:"seed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Seed == nil {
+		h.respondWithError(w, http.StatusBadRequest, "A numeric seed is required")
+		return
+	}
+	
+	h.random.Reseed(*request.Seed)
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"seed": *request.Seed,
+		},
+		Message: "Simulation randomness reseeded",
+	})
+}
+
 func (h *Handler) ResetSystem(w http.ResponseWriter, r *http.Request) {
+	h.security.Reset()
+	h.alarmResponse.Reset()
+	h.safety.Reset()
+	h.locks.Reset()
+	h.cameraService.Reset()
+	h.loads.Reset()
+	h.anomalies.Reset()
+	h.budgets.Reset()
+	h.forecasts.Reset()
+	h.tariffs.Reset()
+	h.access.Reset()
+	h.scheduler.Reset()
+	
 	h.store.Reset()
-	h.deviceService = services.NewDeviceService(h.store)
+	h.deviceService.Reset()
+	h.weatherService.Reset()
 	
 	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
 		Success: true,
@@ -356,12 +646,49 @@ func (h *Handler) TriggerScenario(w http.ResponseWriter, r *http.Request) {
 		}
 		
 	case "power_surge":
-		usageData := h.deviceService.CalculateEnergyUsage()
-		for _, usage := range usageData {
-			usage.Usage *= 5
-			usage.Cost *= 5
-			h.store.AddEnergyUsage(usage)
+		interval := float64(h.config.EnergyUpdateInterval)
+		for _, usage := range h.deviceService.CalculatePower() {
+			usage.PowerW *= 5
+			usage.Interval = interval
+			usage.EnergyWh = utils.RoundToDecimal(usage.PowerW*interval/3600, 4)
+			h.tariffs.Price(&usage)
+			h.deviceService.RecordEnergyUsage(usage)
+		}
+		
+	case "grid_event":
+		var duration time.Duration
+		if value := r.URL.Query().Get("duration"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				h.respondWithError(w, http.StatusBadRequest, "duration must be a positive Go duration such as 30m")
+				return
+			}
+			duration = parsed
+		}
+		h.loads.StartGridEvent(duration)
+		
+	case "camera_motion":
+		for _, camera := range h.deviceService.GetDevicesByType(models.DeviceTypeCamera) {
+			h.cameraService.StartMotion(camera.ID, "debug")
+		}
+		
+	case "smoke_alarm", "co_alarm", "water_leak":
+		sensorTypes := map[string]models.DeviceType{
+			"smoke_alarm": models.DeviceTypeSmokeSensor,
+			"co_alarm":    models.DeviceTypeCOSensor,
+			"water_leak":  models.DeviceTypeLeakSensor,
 		}
+		hazards := map[string]map[string]interface{}{
+			"smoke_alarm": {"smoke_level": 40.0},
+			"co_alarm":    {"co_ppm": 150.0},
+			"water_leak":  {"leak_detected": true},
+		}
+		sensors := h.deviceService.GetDevicesByType(sensorTypes[scenario])
+		if len(sensors) == 0 {
+			h.respondWithError(w, http.StatusBadRequest, fmt.Sprintf("No %s devices to trigger", sensorTypes[scenario]))
+			return
+		}
+		h.deviceService.UpdateDevice(sensors[0].ID, hazards[scenario])
 		
 	case "morning_routine", "evening_routine", "away_mode", "sleep_mode", "security_breach":
 		if err := h.scheduler.TriggerAutomationScenario(scenario); err != nil {
@@ -402,7 +729,7 @@ func (h *Handler) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	if err := conn.WriteJSON(models.WebSocketMessage{
 		Type:      "initial_state",
 		Data:      state,
-		Timestamp: time.Now(),
+		Timestamp: h.clock.Now(),
 	}); err != nil {
 		log.Printf("WebSocket write error: %v", err)
 		return
@@ -426,7 +753,7 @@ func (h *Handler) broadcastMessage(messageType string, data interface{}) {
 	message := models.WebSocketMessage{
 		Type:      messageType,
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: h.clock.Now(),
 	}
 	
 	for client := range h.wsClients {
@@ -466,6 +793,14 @@ func (h *Handler) respondWithError(w http.ResponseWriter, status int, message st
 	})
 }
 
+func (h *Handler) respondWithRuleError(w http.ResponseWriter, err *rules.Error) {
+	h.respondWithJSON(w, http.StatusBadRequest, models.APIResponse{
+		Success: false,
+		Data:    err,
+		Error:   fmt.Sprintf("Invalid condition: %s", err.Error()),
+	})
+}
+
 func (h *Handler) countOnlineDevices(devices []*models.Device) int {
 	count := 0
 	for _, device := range devices {
@@ -486,12 +821,20 @@ func (h *Handler) countOfflineDevices(devices []*models.Device) int {
 	return count
 }
 
+func (h *Handler) calculateTotalPower(readings []models.EnergyUsage) float64 {
+	total := 0.0
+	for _, r := range readings {
+		total += r.PowerW
+	}
+	return total
+}
+
 func (h *Handler) calculateTotalEnergy(usage []models.EnergyUsage) float64 {
 	total := 0.0
 	for _, u := range usage {
-		total += u.Usage
+		total += u.EnergyWh
 	}
-	return total
+	return utils.RoundToDecimal(total, 4)
 }
 
 func (h *Handler) calculateTotalCost(usage []models.EnergyUsage) float64 {
@@ -499,13 +842,31 @@ func (h *Handler) calculateTotalCost(usage []models.EnergyUsage) float64 {
 	for _, u := range usage {
 		total += u.Cost
 	}
-	return total
+	return utils.RoundToDecimal(total, 4)
 }
 
-func (h *Handler) aggregateUsageByDevice(usage []models.EnergyUsage) map[string]float64 {
-	aggregate := make(map[string]float64)
+func (h *Handler) aggregatePowerByDevice(readings []models.EnergyUsage) map[string]models.DevicePower {
+	aggregate := make(map[string]models.DevicePower)
+	for _, r := range readings {
+		total := aggregate[r.DeviceID]
+		total.Name = r.DeviceName
+		total.PowerW += r.PowerW
+		aggregate[r.DeviceID] = total
+	}
+	return aggregate
+}
+
+func (h *Handler) aggregateEnergyByDevice(usage []models.EnergyUsage) map[string]models.DeviceEnergy {
+	aggregate := make(map[string]models.DeviceEnergy)
 	for _, u := range usage {
-		aggregate[u.DeviceName] += u.Usage
+		total := aggregate[u.DeviceID]
+		total.Name = u.DeviceName
+		total.EnergyWh += u.EnergyWh
+		aggregate[u.DeviceID] = total
+	}
+	for deviceID, total := range aggregate {
+		total.EnergyWh = utils.RoundToDecimal(total.EnergyWh, 4)
+		aggregate[deviceID] = total
 	}
 	return aggregate
 }
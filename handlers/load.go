@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/utils"
+)
+
+func (h *Handler) GetLoadStatus(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.loads.Status(),
+	})
+}
+
+func (h *Handler) GetShedActions(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	
+	actions := h.loads.Actions(limit)
+	savedWh, savedCost := 0.0, 0.0
+	byReason := make(map[models.ShedReason]float64)
+	for _, action := range actions {
+		savedWh += action.SavedWh
+		savedCost += action.SavedCost
+		byReason[action.Reason] += action.SavedWh
+	}
+	for reason, saved := range byReason {
+		byReason[reason] = utils.RoundToDecimal(saved, 2)
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"actions":            actions,
+			"saved_wh":           utils.RoundToDecimal(savedWh, 2),
+			"saved_cost":         utils.RoundToDecimal(savedCost, 4),
+			"saved_wh_by_reason": byReason,
+			"currency":           h.tariffs.Active().Currency,
+		},
+	})
+}
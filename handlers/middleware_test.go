@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"multi-agent-framework-testing/config"
+)
+
+func newTestHandler(cfg *config.Config) *Handler {
+	return &Handler{
+		rateLimiter: make(map[string]*rateLimitEntry),
+		config:      cfg,
+	}
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestAuthMiddlewareUsesConfiguredToken(t *testing.T) {
+	cfg := config.Load()
+	cfg.AuthToken = "configured-token"
+	handler := newTestHandler(cfg).AuthMiddleware(okHandler)
+	
+	tests := []struct {
+		path   string
+		header string
+		want   int
+	}{
+		{"/devices", "Bearer configured-token", http.StatusOK},
+		{"/devices", "Bearer smarthome-secret-token", http.StatusUnauthorized},
+		{"/devices", "configured-token", http.StatusUnauthorized},
+		{"/devices", "", http.StatusUnauthorized},
+		{"/health", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("GET %s with %q = %d, want %d", tt.path, tt.header, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareUsesConfiguredRate(t *testing.T) {
+	cfg := config.Load()
+	cfg.RateLimitRPS = 3
+	handler := newTestHandler(cfg).RateLimitMiddleware(okHandler)
+	
+	serve := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/devices", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	
+	for i := 0; i < 3; i++ {
+		if code := serve("192.0.2.1:1000"); code != http.StatusOK {
+			t.Fatalf("request %d = %d, want 200 within the limit", i+1, code)
+		}
+	}
+	if code := serve("192.0.2.1:1001"); code != http.StatusTooManyRequests {
+		t.Errorf("request over the limit = %d, want 429", code)
+	}
+	if code := serve("192.0.2.2:1000"); code != http.StatusOK {
+		t.Errorf("request from another client = %d, want 200", code)
+	}
+}
+
+func TestRateLimitMiddlewareDisabledAtZero(t *testing.T) {
+	cfg := config.Load()
+	cfg.RateLimitRPS = 0
+	handler := newTestHandler(cfg).RateLimitMiddleware(okHandler)
+	
+	for i := 0; i < 200; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/devices", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d = %d with rate limiting disabled, want 200", i+1, rec.Code)
+		}
+	}
+}
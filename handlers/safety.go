@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetSafetyAlarms(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	
+	activeOnly := r.URL.Query().Get("active") == "true"
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.safety.GetAlarms(activeOnly, limit),
+	})
+}
+
+func (h *Handler) SilenceSafetyAlarm(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	alarm, err := h.safety.Silence(vars["id"])
+	if err != nil {
+		status := http.StatusNotFound
+		if alarm.ID != "" {
+			status = http.StatusConflict
+		}
+		h.respondWithError(w, status, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    alarm,
+		Message: "Safety alarm silenced",
+	})
+}
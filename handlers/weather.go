@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"multi-agent-framework-testing/models"
+)
+
+func (h *Handler) GetWeatherHistory(w http.ResponseWriter, r *http.Request) {
+	hours := 24
+	if value := r.URL.Query().Get("hours"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > 168 {
+			h.respondWithError(w, http.StatusBadRequest, "hours must be an integer between 1 and 168")
+			return
+		}
+		hours = parsed
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"hours":  hours,
+			"hourly": h.weatherService.GetWeatherHistory(hours),
+		},
+	})
+}
+
+func (h *Handler) GetWeatherAlerts(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	
+	activeOnly := false
+	if value := r.URL.Query().Get("active"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "active must be true or false")
+			return
+		}
+		activeOnly = parsed
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.weatherService.GetWeatherAlerts(activeOnly, limit),
+	})
+}
+
+func (h *Handler) GetWeatherPolicies(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.weatherService.PolicyStatus(),
+	})
+}
+
+func (h *Handler) UpdateWeatherPolicy(w http.ResponseWriter, r *http.Request) {
+	policy := models.WeatherPolicy(mux.Vars(r)["policy"])
+	
+	var request struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Enabled == nil {
+		h.respondWithError(w, http.StatusBadRequest, "enabled must be true or false")
+		return
+	}
+	
+	if err := h.weatherService.SetPolicyEnabled(policy, *request.Enabled); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	
+	h.respondWithJSON(w, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.weatherService.PolicyStatus(),
+		Message: "Weather policy updated",
+	})
+}
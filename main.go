@@ -12,22 +12,59 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"multi-agent-framework-testing/clock"
 	"multi-agent-framework-testing/config"
 	"multi-agent-framework-testing/handlers"
+	"multi-agent-framework-testing/random"
 	"multi-agent-framework-testing/services"
 	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
 	"multi-agent-framework-testing/workers"
 )
 
 func main() {
 	cfg := config.Load()
 	
-	store := storage.NewMemoryStore()
+	var clk clock.Clock = clock.NewReal()
+	if cfg.ClockMode == "simulated" {
+		clk = clock.NewSimulated(time.Now(), cfg.ClockSpeed)
+		log.Printf("Using simulated clock at %.1fx speed", cfg.ClockSpeed)
+	}
 	
-	deviceService := services.NewDeviceService(store)
-	weatherService := services.NewWeatherService()
+	seed := time.Now().UnixNano()
+	if cfg.RandomSeed != nil {
+		seed = *cfg.RandomSeed
+	}
+	src := random.NewSource(seed)
+	utils.SetRandomSource(src)
+	log.Printf("Simulation random seed: %d", seed)
+	
+	store := storage.NewMemoryStore(clk)
+	store.SetEnergyRetention(time.Duration(cfg.EnergyHistoryHours) * time.Hour)
+	if cfg.StateFile != "" {
+		if err := store.EnableTaskPersistence(cfg.StateFile); err != nil {
+			log.Printf("Failed to restore task state: %v", err)
+		}
+	}
 	
-	scheduler := workers.NewScheduler(store, deviceService, weatherService)
+	securityService := services.NewSecurityService(store, clk, cfg)
+	accessService := services.NewAccessService(store, clk)
+	notifier := services.NewNotificationService(store, clk)
+	lockService := services.NewLockService(store, notifier, clk, src, cfg)
+	safetyService := services.NewLifeSafetyService(store, notifier, clk)
+	tariffService := services.NewTariffService(clk, cfg)
+	weatherService := services.NewWeatherService(clk, src, cfg)
+	reportService := services.NewEnergyReportService(store, tariffService, clk)
+	forecastService := services.NewForecastService(store, weatherService, tariffService, clk, cfg)
+	budgetService := services.NewBudgetService(store, notifier, forecastService, clk, cfg)
+	loadService := services.NewLoadService(store, tariffService, forecastService, clk, cfg)
+	anomalyService := services.NewAnomalyService(store, tariffService, clk, cfg)
+	deviceService := services.NewDeviceService(store, securityService, lockService, safetyService, tariffService, budgetService, clk, src, cfg)
+	alarmResponse := services.NewAlarmResponseService(store, securityService, deviceService, notifier, clk, cfg)
+	cameraService := services.NewCameraService(store, clk, src, cfg)
+	flowService := services.NewEnergyFlowService(store, weatherService, tariffService, clk, cfg)
+	
+	scheduler := workers.NewScheduler(store, deviceService, weatherService, securityService, accessService, clk, cfg)
 	
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
@@ -35,7 +72,7 @@ func main() {
 		},
 	}
 	
-	handler := handlers.NewHandler(store, deviceService, weatherService, scheduler, &upgrader)
+	handler := handlers.NewHandler(store, deviceService, weatherService, securityService, accessService, alarmResponse, notifier, cameraService, lockService, safetyService, tariffService, reportService, budgetService, loadService, flowService, forecastService, anomalyService, scheduler, &upgrader, clk, src, cfg)
 	
 	router := mux.NewRouter()
 	
@@ -44,16 +81,53 @@ func main() {
 	router.HandleFunc("/devices", handler.AddDevice).Methods("POST")
 	router.HandleFunc("/devices/{id}", handler.UpdateDevice).Methods("PUT")
 	router.HandleFunc("/weather", handler.GetWeather).Methods("GET")
+	router.HandleFunc("/weather/history", handler.GetWeatherHistory).Methods("GET")
+	router.HandleFunc("/weather/alerts", handler.GetWeatherAlerts).Methods("GET")
+	router.HandleFunc("/weather/policies", handler.GetWeatherPolicies).Methods("GET")
+	router.HandleFunc("/weather/policies/{policy}", handler.UpdateWeatherPolicy).Methods("PUT")
 	router.HandleFunc("/energy/usage", handler.GetEnergyUsage).Methods("GET")
+	router.HandleFunc("/energy/report", handler.GetEnergyReport).Methods("GET")
+	router.HandleFunc("/energy/budgets", handler.GetBudgets).Methods("GET")
+	router.HandleFunc("/energy/budgets", handler.CreateBudget).Methods("POST")
+	router.HandleFunc("/energy/budgets/{id}", handler.DeleteBudget).Methods("DELETE")
+	router.HandleFunc("/energy/load", handler.GetLoadStatus).Methods("GET")
+	router.HandleFunc("/energy/load/actions", handler.GetShedActions).Methods("GET")
+	router.HandleFunc("/energy/forecast", handler.GetEnergyForecast).Methods("GET")
+	router.HandleFunc("/energy/anomalies", handler.GetEnergyAnomalies).Methods("GET")
+	router.HandleFunc("/energy/anomalies/{id}/{action}", handler.UpdateEnergyAnomaly).Methods("POST")
+	router.HandleFunc("/energy/flow", handler.GetEnergyFlow).Methods("GET")
+	router.HandleFunc("/energy/flow/history", handler.GetEnergyFlowHistory).Methods("GET")
+	router.HandleFunc("/energy/tariffs", handler.GetTariffs).Methods("GET")
+	router.HandleFunc("/energy/tariffs/active", handler.SetActiveTariff).Methods("PUT")
+	router.HandleFunc("/energy/tariffs/what-if", handler.TariffWhatIf).Methods("POST")
 	router.HandleFunc("/security/arm", handler.ArmSecurity).Methods("POST")
 	router.HandleFunc("/security/disarm", handler.DisarmSecurity).Methods("POST")
+	router.HandleFunc("/security/users", handler.ListSecurityUsers).Methods("GET")
+	router.HandleFunc("/security/users", handler.CreateSecurityUser).Methods("POST")
+	router.HandleFunc("/security/users/{id}", handler.DeleteSecurityUser).Methods("DELETE")
+	router.HandleFunc("/security/users/{id}/locks/{lock}", handler.SetLockCode).Methods("PUT")
+	router.HandleFunc("/security/audit", handler.GetAuditLog).Methods("GET")
+	router.HandleFunc("/security/alarm-response", handler.GetAlarmResponse).Methods("GET")
+	router.HandleFunc("/safety/alarms", handler.GetSafetyAlarms).Methods("GET")
+	router.HandleFunc("/safety/alarms/{id}/silence", handler.SilenceSafetyAlarm).Methods("POST")
+	router.HandleFunc("/notifications", handler.GetNotifications).Methods("GET")
+	router.HandleFunc("/cameras/{id}/events", handler.GetCameraEvents).Methods("GET")
+	router.HandleFunc("/cameras/{id}/events/{eid}/thumbnail", handler.GetCameraThumbnail).Methods("GET")
 	router.HandleFunc("/analytics/summary", handler.GetAnalytics).Methods("GET")
 	router.HandleFunc("/schedule/task", handler.CreateScheduledTask).Methods("POST")
-	router.HandleFunc("/debug/state", handler.DebugState).Methods("GET")
-	router.HandleFunc("/debug/reset", handler.ResetSystem).Methods("POST")
-	router.HandleFunc("/debug/trigger/{scenario}", handler.TriggerScenario).Methods("POST")
+	router.HandleFunc("/schedule/task/{id}/history", handler.GetTaskHistory).Methods("GET")
+	router.HandleFunc("/automation/rules", handler.CreateAutomationRule).Methods("POST")
 	router.HandleFunc("/ws", handler.WebSocketHandler).Methods("GET")
 	
+	if cfg.EnableDebugMode {
+		router.HandleFunc("/debug/state", handler.DebugState).Methods("GET")
+		router.HandleFunc("/debug/reset", handler.ResetSystem).Methods("POST")
+		router.HandleFunc("/debug/trigger/{scenario}", handler.TriggerScenario).Methods("POST")
+		router.HandleFunc("/debug/seed", handler.SetSeed).Methods("POST")
+		router.HandleFunc("/debug/clock", handler.GetClock).Methods("GET")
+		router.HandleFunc("/debug/clock/{action}", handler.ControlClock).Methods("POST")
+	}
+	
 	router.Use(handler.LoggingMiddleware)
 	router.Use(handler.AuthMiddleware)
 	router.Use(handler.RateLimitMiddleware)
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+type AnomalyKind string
+
+const (
+	AnomalyPowerHigh AnomalyKind = "power_high"
+	AnomalyPowerLow  AnomalyKind = "power_low"
+	AnomalyLongRun   AnomalyKind = "long_run"
+	AnomalyStandby   AnomalyKind = "standby"
+)
+
+type AnomalyStatus string
+
+const (
+	AnomalyOpen         AnomalyStatus = "open"
+	AnomalyAcknowledged AnomalyStatus = "acknowledged"
+	AnomalyDismissed    AnomalyStatus = "dismissed"
+	AnomalyResolved     AnomalyStatus = "resolved"
+)
+
+type EnergyAnomaly struct {
+	ID             string                 `json:"id"`
+	DeviceID       string                 `json:"device_id"`
+	DeviceName     string                 `json:"device_name"`
+	DeviceType     DeviceType             `json:"device_type"`
+	Location       string                 `json:"location"`
+	Kind           AnomalyKind            `json:"kind"`
+	Status         AnomalyStatus          `json:"status"`
+	Severity       string                 `json:"severity"`
+	Message        string                 `json:"message"`
+	PowerW         float64                `json:"power_w"`
+	BaselineW      float64                `json:"baseline_w"`
+	Context        map[string]interface{} `json:"context"`
+	DetectedAt     time.Time              `json:"detected_at"`
+	LastSeenAt     time.Time              `json:"last_seen_at"`
+	AcknowledgedAt time.Time              `json:"acknowledged_at,omitempty"`
+	DismissedAt    time.Time              `json:"dismissed_at,omitempty"`
+	ResolvedAt     time.Time              `json:"resolved_at,omitempty"`
+}
+
+func (a EnergyAnomaly) Active() bool {
+	return a.Status == AnomalyOpen || a.Status == AnomalyAcknowledged
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type BudgetPeriod string
+
+const (
+	BudgetDaily   BudgetPeriod = "day"
+	BudgetWeekly  BudgetPeriod = "week"
+	BudgetMonthly BudgetPeriod = "month"
+)
+
+type BudgetScope string
+
+const (
+	BudgetGlobal BudgetScope = "global"
+	BudgetDevice BudgetScope = "device"
+	BudgetRoom   BudgetScope = "room"
+)
+
+type BudgetMetric string
+
+const (
+	BudgetEnergy BudgetMetric = "energy"
+	BudgetCost   BudgetMetric = "cost"
+)
+
+type EnergyBudget struct {
+	ID     string       `json:"id"`
+	Name   string       `json:"name"`
+	Scope  BudgetScope  `json:"scope"`
+	Target string       `json:"target,omitempty"`
+	Period BudgetPeriod `json:"period"`
+	Metric BudgetMetric `json:"metric"`
+	Limit  float64      `json:"limit"`
+}
+
+type BudgetStatus struct {
+	Budget           EnergyBudget `json:"budget"`
+	PeriodStart      time.Time    `json:"period_start"`
+	PeriodEnd        time.Time    `json:"period_end"`
+	TrackedSince     time.Time    `json:"tracked_since"`
+	Unit             string       `json:"unit"`
+	Used             float64      `json:"used"`
+	Projected        float64      `json:"projected"`
+	Projection       string       `json:"projection"`
+	Remaining        float64      `json:"remaining"`
+	UsedPercent      float64      `json:"used_percent"`
+	ProjectedPercent float64      `json:"projected_percent"`
+	AlertLevel       float64      `json:"alert_level"`
+	State            string       `json:"state"`
+}
+
+func (b EnergyBudget) Validate() error {
+	switch b.Period {
+	case BudgetDaily, BudgetWeekly, BudgetMonthly:
+	default:
+		return fmt.Errorf("budget period must be day, week or month")
+	}
+	
+	switch b.Metric {
+	case BudgetEnergy, BudgetCost:
+	default:
+		return fmt.Errorf("budget metric must be energy or cost")
+	}
+	
+	switch b.Scope {
+	case BudgetGlobal:
+		if b.Target != "" {
+			return fmt.Errorf("global budgets do not take a target")
+		}
+	case BudgetDevice, BudgetRoom:
+		if b.Target == "" {
+			return fmt.Errorf("%s budgets require a target", b.Scope)
+		}
+	default:
+		return fmt.Errorf("budget scope must be global, device or room")
+	}
+	
+	if b.Limit <= 0 {
+		return fmt.Errorf("budget limit must be positive")
+	}
+	return nil
+}
+
+func (b EnergyBudget) Matches(usage EnergyUsage) bool {
+	switch b.Scope {
+	case BudgetDevice:
+		return usage.DeviceID == b.Target
+	case BudgetRoom:
+		return usage.Location == b.Target
+	}
+	return true
+}
+
+func (b EnergyBudget) PeriodAt(t time.Time) (time.Time, time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch b.Period {
+	case BudgetWeekly:
+		start := day.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case BudgetMonthly:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	return day, day.AddDate(0, 0, 1)
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type ClipMetadata struct {
+	Resolution      string  `json:"resolution"`
+	Codec           string  `json:"codec"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	SizeBytes       int64   `json:"size_bytes"`
+}
+
+type CameraEvent struct {
+	ID             string        `json:"id"`
+	CameraID       string        `json:"camera_id"`
+	Trigger        string        `json:"trigger"`
+	StartedAt      time.Time     `json:"started_at"`
+	EndedAt        time.Time     `json:"ended_at"`
+	InProgress     bool          `json:"in_progress"`
+	Clip           *ClipMetadata `json:"clip,omitempty"`
+	ThumbnailURL   string        `json:"thumbnail_url,omitempty"`
+	ThumbnailBytes int64         `json:"thumbnail_bytes"`
+	ThumbnailPath  string        `json:"-"`
+}
+
+func (e CameraEvent) StorageBytes() int64 {
+	size := e.ThumbnailBytes
+	if e.Clip != nil {
+		size += e.Clip.SizeBytes
+	}
+	return size
+}
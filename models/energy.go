@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+type EnergyGroupBy string
+
+const (
+	GroupByDevice EnergyGroupBy = "device"
+	GroupByType   EnergyGroupBy = "type"
+	GroupByRoom   EnergyGroupBy = "room"
+	GroupByHour   EnergyGroupBy = "hour"
+	GroupByDay    EnergyGroupBy = "day"
+)
+
+type EnergyTotals struct {
+	EnergyWh      float64   `json:"energy_wh"`
+	Cost          float64   `json:"cost"`
+	PeakDemandW   float64   `json:"peak_demand_w"`
+	PeakAt        time.Time `json:"peak_at,omitempty"`
+	AveragePowerW float64   `json:"average_power_w"`
+	Samples       int       `json:"samples"`
+}
+
+type EnergyReportGroup struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	EnergyTotals
+	SharePercent float64 `json:"share_percent"`
+}
+
+type EnergyComparison struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	EnergyTotals
+	EnergyChangeWh      float64  `json:"energy_change_wh"`
+	EnergyChangePercent *float64 `json:"energy_change_percent"`
+	CostChange          float64  `json:"cost_change"`
+	CostChangePercent   *float64 `json:"cost_change_percent"`
+}
+
+type EnergyReport struct {
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	GroupBy      EnergyGroupBy       `json:"group_by"`
+	Currency     string              `json:"currency"`
+	DataFrom     time.Time           `json:"data_from,omitempty"`
+	Partial      bool                `json:"partial"`
+	Totals       EnergyTotals        `json:"totals"`
+	FixedCharges float64             `json:"fixed_charges"`
+	TotalCost    float64             `json:"total_cost"`
+	Groups       []EnergyReportGroup `json:"groups"`
+	Previous     EnergyComparison    `json:"previous"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+)
+
+type DispatchPolicy string
+
+const (
+	DispatchSelfConsumption DispatchPolicy = "self_consumption"
+	DispatchTOUArbitrage    DispatchPolicy = "tou_arbitrage"
+	DispatchBackup          DispatchPolicy = "backup"
+)
+
+var SolarConditionFactor = map[string]float64{
+	"sunny":         1.0,
+	"clear":         1.0,
+	"partly_cloudy": 0.7,
+	"cloudy":        0.4,
+	"overcast":      0.25,
+	"foggy":         0.3,
+	"rainy":         0.15,
+	"stormy":        0.05,
+}
+
+type EnergyFlow struct {
+	Timestamp          time.Time `json:"timestamp"`
+	Interval           float64   `json:"interval_seconds"`
+	ConsumptionW       float64   `json:"consumption_w"`
+	SolarW             float64   `json:"solar_w"`
+	BatteryW           float64   `json:"battery_w"`
+	GridW              float64   `json:"grid_w"`
+	ConsumptionWh      float64   `json:"consumption_wh"`
+	SolarWh            float64   `json:"solar_wh"`
+	BatteryChargeWh    float64   `json:"battery_charge_wh"`
+	BatteryDischargeWh float64   `json:"battery_discharge_wh"`
+	ImportWh           float64   `json:"import_wh"`
+	ExportWh           float64   `json:"export_wh"`
+	ImportCost         float64   `json:"import_cost"`
+	ExportCredit       float64   `json:"export_credit"`
+}
+
+type EnergyFlowSummary struct {
+	From               time.Time `json:"from"`
+	To                 time.Time `json:"to"`
+	ConsumptionWh      float64   `json:"consumption_wh"`
+	SolarWh            float64   `json:"solar_wh"`
+	BatteryChargeWh    float64   `json:"battery_charge_wh"`
+	BatteryDischargeWh float64   `json:"battery_discharge_wh"`
+	ImportWh           float64   `json:"import_wh"`
+	ExportWh           float64   `json:"export_wh"`
+	ImportCost         float64   `json:"import_cost"`
+	ExportCredit       float64   `json:"export_credit"`
+	NetCost            float64   `json:"net_cost"`
+	Currency           string    `json:"currency"`
+	SelfConsumption    *float64  `json:"self_consumption_ratio"`
+	SelfSufficiency    *float64  `json:"self_sufficiency_ratio"`
+}
+
+func ValidDispatchPolicy(policy DispatchPolicy) bool {
+	switch policy {
+	case DispatchSelfConsumption, DispatchTOUArbitrage, DispatchBackup:
+		return true
+	}
+	return false
+}
+
+func IsEnergySource(deviceType DeviceType) bool {
+	return deviceType == DeviceTypeSolarInverter || deviceType == DeviceTypeHomeBattery
+}
+
+type BatteryState struct {
+	DeviceID       string         `json:"device_id"`
+	DeviceName     string         `json:"device_name"`
+	Dispatch       DispatchPolicy `json:"dispatch"`
+	SoC            float64        `json:"soc"`
+	CapacityWh     float64        `json:"capacity_wh"`
+	StoredWh       float64        `json:"stored_wh"`
+	PowerW         float64        `json:"power_w"`
+	ReservePercent float64        `json:"reserve_percent"`
+}
+
+type EnergyFlowStatus struct {
+	Current   *EnergyFlow       `json:"current"`
+	Today     EnergyFlowSummary `json:"today"`
+	Batteries []BatteryState    `json:"batteries"`
+}
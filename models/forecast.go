@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+)
+
+var ForecastZScores = map[float64]float64{
+	0.5:  0.6745,
+	0.8:  1.2816,
+	0.9:  1.6449,
+	0.95: 1.96,
+	0.99: 2.5758,
+}
+
+type EnergyRollup struct {
+	Hour        time.Time  `json:"hour"`
+	DeviceID    string     `json:"device_id"`
+	DeviceName  string     `json:"device_name"`
+	DeviceType  DeviceType `json:"device_type"`
+	Location    string     `json:"location"`
+	EnergyWh    float64    `json:"energy_wh"`
+	Cost        float64    `json:"cost"`
+	Coverage    float64    `json:"coverage"`
+	OutdoorTemp float64    `json:"outdoor_temp"`
+}
+
+type ForecastPoint struct {
+	Hour        time.Time `json:"hour"`
+	EnergyWh    float64   `json:"energy_wh"`
+	LowerWh     float64   `json:"lower_wh"`
+	UpperWh     float64   `json:"upper_wh"`
+	Cost        float64   `json:"cost"`
+	OutdoorTemp *float64  `json:"outdoor_temp,omitempty"`
+}
+
+type DeviceForecast struct {
+	DeviceID   string          `json:"device_id"`
+	DeviceName string          `json:"device_name"`
+	DeviceType DeviceType      `json:"device_type"`
+	Location   string          `json:"location"`
+	Method     string          `json:"method"`
+	Samples    int             `json:"samples"`
+	EnergyWh   float64         `json:"energy_wh"`
+	LowerWh    float64         `json:"lower_wh"`
+	UpperWh    float64         `json:"upper_wh"`
+	Cost       float64         `json:"cost"`
+	Hourly     []ForecastPoint `json:"hourly"`
+}
+
+type EnergyForecast struct {
+	GeneratedAt  time.Time        `json:"generated_at"`
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Confidence   float64          `json:"confidence"`
+	HistoryHours int              `json:"history_hours"`
+	Currency     string           `json:"currency"`
+	EnergyWh     float64          `json:"energy_wh"`
+	LowerWh      float64          `json:"lower_wh"`
+	UpperWh      float64          `json:"upper_wh"`
+	Cost         float64          `json:"cost"`
+	PeakPowerW   float64          `json:"peak_power_w"`
+	PeakAt       time.Time        `json:"peak_at"`
+	Hourly       []ForecastPoint  `json:"hourly"`
+	Devices      []DeviceForecast `json:"devices"`
+}
+
+func (f *EnergyForecast) Device(deviceID string) *DeviceForecast {
+	for i := range f.Devices {
+		if f.Devices[i].DeviceID == deviceID {
+			return &f.Devices[i]
+		}
+	}
+	return nil
+}
+
+func (d DeviceForecast) Usage(point ForecastPoint) EnergyUsage {
+	return EnergyUsage{
+		DeviceID:   d.DeviceID,
+		DeviceName: d.DeviceName,
+		DeviceType: d.DeviceType,
+		Location:   d.Location,
+		EnergyWh:   point.EnergyWh,
+		Interval:   3600,
+		Cost:       point.Cost,
+		Timestamp:  point.Hour,
+	}
+}
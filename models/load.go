@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+type ShedStrategy string
+
+const (
+	ShedDim      ShedStrategy = "dim"
+	ShedDeadband ShedStrategy = "deadband"
+	ShedPause    ShedStrategy = "pause"
+)
+
+type ShedReason string
+
+const (
+	ShedPowerLimit ShedReason = "power_limit"
+	ShedPeakTariff ShedReason = "peak_tariff"
+	ShedGridEvent  ShedReason = "grid_event"
+)
+
+var ShedStrategies = map[DeviceType]ShedStrategy{
+	DeviceTypeLight:      ShedDim,
+	DeviceTypeThermostat: ShedDeadband,
+	DeviceTypeSmartPlug:  ShedPause,
+	DeviceTypeEVCharger:  ShedPause,
+}
+
+var DefaultLoadPriority = map[DeviceType]int{
+	DeviceTypeSmartPlug:  1,
+	DeviceTypeEVCharger:  1,
+	DeviceTypeLight:      2,
+	DeviceTypeThermostat: 3,
+}
+
+type ShedAction struct {
+	ID               string                 `json:"id"`
+	DeviceID         string                 `json:"device_id"`
+	DeviceName       string                 `json:"device_name"`
+	Strategy         ShedStrategy           `json:"strategy"`
+	Reason           ShedReason             `json:"reason"`
+	Priority         int                    `json:"priority"`
+	Changes          map[string]interface{} `json:"changes"`
+	Previous         map[string]interface{} `json:"previous"`
+	EstimatedSavingW float64                `json:"estimated_saving_w"`
+	Rate             float64                `json:"rate"`
+	Active           bool                   `json:"active"`
+	ShedAt           time.Time              `json:"shed_at"`
+	RestoredAt       time.Time              `json:"restored_at,omitempty"`
+	SavedWh          float64                `json:"saved_wh"`
+	SavedCost        float64                `json:"saved_cost"`
+}
+
+type LoadStatus struct {
+	Enabled        bool         `json:"enabled"`
+	PowerLimitW    float64      `json:"power_limit_w"`
+	CurrentPowerW  float64      `json:"current_power_w"`
+	Reasons        []ShedReason `json:"reasons"`
+	TariffBand     string       `json:"tariff_band"`
+	GridEventEnds  time.Time    `json:"grid_event_ends,omitempty"`
+	ForecastPeakW  float64      `json:"forecast_peak_w"`
+	ForecastPeakAt time.Time    `json:"forecast_peak_at"`
+	Shed           []ShedAction `json:"shed"`
+	SavedWh        float64      `json:"saved_wh"`
+	SavedCost      float64      `json:"saved_cost"`
+	Currency       string       `json:"currency"`
+}
+
+func LoadPriority(device *Device) int {
+	switch value := device.Properties["load_priority"].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return DefaultLoadPriority[device.Type]
+}
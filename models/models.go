@@ -7,12 +7,23 @@ import (
 type DeviceType string
 
 const (
-	DeviceTypeLight      DeviceType = "light"
-	DeviceTypeThermostat DeviceType = "thermostat"
-	DeviceTypeCamera     DeviceType = "camera"
-	DeviceTypeSensor     DeviceType = "sensor"
-	DeviceTypeLock       DeviceType = "lock"
-	DeviceTypeAlarm      DeviceType = "alarm"
+	DeviceTypeLight         DeviceType = "light"
+	DeviceTypeThermostat    DeviceType = "thermostat"
+	DeviceTypeCamera        DeviceType = "camera"
+	DeviceTypeSensor        DeviceType = "sensor"
+	DeviceTypeLock          DeviceType = "lock"
+	DeviceTypeAlarm         DeviceType = "alarm"
+	DeviceTypeSmokeSensor   DeviceType = "smoke_sensor"
+	DeviceTypeCOSensor      DeviceType = "co_sensor"
+	DeviceTypeLeakSensor    DeviceType = "leak_sensor"
+	DeviceTypeContactSensor DeviceType = "contact_sensor"
+	DeviceTypeWaterValve    DeviceType = "water_valve"
+	DeviceTypeSmartPlug     DeviceType = "smart_plug"
+	DeviceTypeSolarInverter DeviceType = "solar_inverter"
+	DeviceTypeHomeBattery   DeviceType = "home_battery"
+	DeviceTypeEVCharger     DeviceType = "ev_charger"
+	DeviceTypeBlinds        DeviceType = "blinds"
+	DeviceTypeIrrigation    DeviceType = "irrigation"
 )
 
 type DeviceStatus string
@@ -47,16 +58,29 @@ type WeatherData struct {
 type EnergyUsage struct {
 	DeviceID    string    `json:"device_id"`
 	DeviceName  string    `json:"device_name"`
-	Usage       float64   `json:"usage_kwh"`
-	Cost        float64   `json:"cost_usd"`
+	DeviceType  DeviceType `json:"device_type"`
+	Location    string    `json:"location"`
+	PowerW      float64   `json:"power_w"`
+	EnergyWh    float64   `json:"energy_wh"`
+	Interval    float64   `json:"interval_seconds"`
+	Rate        float64   `json:"rate"`
+	Band        string    `json:"band"`
+	Cost        float64   `json:"cost"`
+	Currency    string    `json:"currency"`
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+func (u EnergyUsage) KWh() float64 {
+	return u.EnergyWh / 1000
+}
+
 type SecurityState string
 
 const (
 	SecurityStateDisarmed SecurityState = "disarmed"
+	SecurityStateArming   SecurityState = "arming"
 	SecurityStateArmed    SecurityState = "armed"
+	SecurityStatePending  SecurityState = "pending"
 	SecurityStateTriggered SecurityState = "triggered"
 )
 
@@ -66,6 +90,27 @@ type SecuritySystem struct {
 	LastTriggered  time.Time     `json:"last_triggered"`
 	ActiveSensors  []string      `json:"active_sensors"`
 	TriggeredBy    string        `json:"triggered_by"`
+	Mode           ArmMode       `json:"mode"`
+	BypassedSensors []string     `json:"bypassed_sensors"`
+	StateChangedAt time.Time     `json:"state_changed_at"`
+	DelayEndsAt    time.Time     `json:"delay_ends_at"`
+}
+
+type Notification struct {
+	ID        string                 `json:"id"`
+	Channel   string                 `json:"channel"`
+	Severity  string                 `json:"severity"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+type SecurityTransition struct {
+	From      SecurityState `json:"from"`
+	To        SecurityState `json:"to"`
+	Cause     string        `json:"cause"`
+	Source    string        `json:"source"`
+	Timestamp time.Time     `json:"timestamp"`
 }
 
 type ScheduledTask struct {
@@ -75,25 +120,68 @@ type ScheduledTask struct {
 	Action      string                 `json:"action"`
 	Parameters  map[string]interface{} `json:"parameters"`
 	Schedule    string                 `json:"schedule"`
+	Condition   string                 `json:"condition,omitempty"`
+	Actions     []TestAction           `json:"actions,omitempty"`
 	Enabled     bool                   `json:"enabled"`
 	NextRun     time.Time              `json:"next_run"`
 	LastRun     time.Time              `json:"last_run"`
 	CreatedAt   time.Time              `json:"created_at"`
+	MisfirePolicy MisfirePolicy        `json:"misfire_policy,omitempty"`
+	MaxCatchUp    int                  `json:"max_catch_up,omitempty"`
+	MisfireGrace  int                  `json:"misfire_grace_seconds,omitempty"`
+	MissedRuns    int                  `json:"missed_runs"`
+}
+
+type MisfirePolicy string
+
+const (
+	MisfireRunOnce MisfirePolicy = "run_once"
+	MisfireRunAll  MisfirePolicy = "run_all"
+	MisfireSkip    MisfirePolicy = "skip"
+)
+
+type TaskRunStatus string
+
+const (
+	TaskRunExecuted TaskRunStatus = "executed"
+	TaskRunSkipped  TaskRunStatus = "skipped"
+	TaskRunFailed   TaskRunStatus = "failed"
+)
+
+type TaskRun struct {
+	TaskID       string        `json:"task_id"`
+	ScheduledFor time.Time     `json:"scheduled_for"`
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Status       TaskRunStatus `json:"status"`
+	Reason       string        `json:"reason,omitempty"`
 }
 
 type AnalyticsData struct {
 	TotalDevices     int                        `json:"total_devices"`
 	OnlineDevices    int                        `json:"online_devices"`
 	OfflineDevices   int                        `json:"offline_devices"`
-	TotalEnergyUsage float64                    `json:"total_energy_usage_kwh"`
-	TotalEnergyCost  float64                    `json:"total_energy_cost_usd"`
-	DeviceUsage      map[string]float64         `json:"device_usage"`
+	CurrentPowerW    float64                    `json:"current_power_w"`
+	TotalEnergyWh    float64                    `json:"total_energy_wh"`
+	TotalEnergyCost  float64                    `json:"total_energy_cost"`
+	Currency         string                     `json:"currency"`
+	DevicePowerW     map[string]DevicePower     `json:"device_power_w"`
+	DeviceEnergyWh   map[string]DeviceEnergy    `json:"device_energy_wh"`
 	SecurityEvents   int                        `json:"security_events"`
 	ScheduledTasks   int                        `json:"scheduled_tasks"`
 	WeatherSummary   WeatherData                `json:"weather_summary"`
 	GeneratedAt      time.Time                  `json:"generated_at"`
 }
 
+type DevicePower struct {
+	Name   string  `json:"name"`
+	PowerW float64 `json:"power_w"`
+}
+
+type DeviceEnergy struct {
+	Name     string  `json:"name"`
+	EnergyWh float64 `json:"energy_wh"`
+}
+
 type SystemEvent struct {
 	ID          string                 `json:"id"`
 	Type        string                 `json:"type"`
@@ -140,4 +228,5 @@ type SystemState struct {
 	SystemEvents []SystemEvent    `json:"system_events"`
 	Uptime       time.Duration    `json:"uptime"`
 	Timestamp    time.Time        `json:"timestamp"`
+	RandomSeed   int64            `json:"random_seed"`
 }
\ No newline at end of file
@@ -0,0 +1,137 @@
+package models
+
+type PropertyKind string
+
+const (
+	PropertyNumber PropertyKind = "number"
+	PropertyString PropertyKind = "string"
+	PropertyBool   PropertyKind = "bool"
+)
+
+var DeviceFields = map[string]PropertyKind{
+	"id":       PropertyString,
+	"name":     PropertyString,
+	"type":     PropertyString,
+	"status":   PropertyString,
+	"location": PropertyString,
+	"online":   PropertyBool,
+}
+
+var DeviceTypeRegistry = map[DeviceType]map[string]PropertyKind{
+	DeviceTypeLight: {
+		"brightness":    PropertyNumber,
+		"color":         PropertyString,
+		"power":         PropertyBool,
+		"load_priority": PropertyNumber,
+		"load_shed":     PropertyBool,
+	},
+	DeviceTypeThermostat: {
+		"temperature":   PropertyNumber,
+		"target_temp":   PropertyNumber,
+		"mode":          PropertyString,
+		"heating":       PropertyBool,
+		"cooling":       PropertyBool,
+		"deadband":      PropertyNumber,
+		"load_priority": PropertyNumber,
+		"load_shed":     PropertyBool,
+	},
+	DeviceTypeCamera: {
+		"recording":     PropertyBool,
+		"motion_detect": PropertyBool,
+		"night_vision":  PropertyBool,
+		"resolution":    PropertyString,
+		"motion_active": PropertyBool,
+		"zone":          PropertyString,
+	},
+	DeviceTypeSensor: {
+		"motion_detected": PropertyBool,
+		"open":            PropertyBool,
+		"zone":            PropertyString,
+		"sensitivity":     PropertyNumber,
+		"battery_level":   PropertyNumber,
+	},
+	DeviceTypeLock: {
+		"locked":          PropertyBool,
+		"auto_lock":       PropertyBool,
+		"auto_lock_delay": PropertyNumber,
+		"door_sensor":     PropertyString,
+		"jammed":          PropertyBool,
+		"battery_level":   PropertyNumber,
+	},
+	DeviceTypeAlarm: {
+		"active": PropertyBool,
+	},
+	DeviceTypeSmokeSensor: {
+		"smoke_level":    PropertyNumber,
+		"smoke_detected": PropertyBool,
+		"battery_level":  PropertyNumber,
+	},
+	DeviceTypeCOSensor: {
+		"co_ppm":        PropertyNumber,
+		"co_detected":   PropertyBool,
+		"battery_level": PropertyNumber,
+	},
+	DeviceTypeLeakSensor: {
+		"leak_detected": PropertyBool,
+		"battery_level": PropertyNumber,
+	},
+	DeviceTypeContactSensor: {
+		"open":          PropertyBool,
+		"zone":          PropertyString,
+		"battery_level": PropertyNumber,
+	},
+	DeviceTypeWaterValve: {
+		"open": PropertyBool,
+	},
+	DeviceTypeSmartPlug: {
+		"power":         PropertyBool,
+		"load_w":        PropertyNumber,
+		"load_priority": PropertyNumber,
+		"load_shed":     PropertyBool,
+	},
+	DeviceTypeSolarInverter: {
+		"capacity_w": PropertyNumber,
+		"output_w":   PropertyNumber,
+	},
+	DeviceTypeHomeBattery: {
+		"capacity_wh":     PropertyNumber,
+		"soc":             PropertyNumber,
+		"max_power_w":     PropertyNumber,
+		"power_w":         PropertyNumber,
+		"reserve_percent": PropertyNumber,
+		"dispatch":        PropertyString,
+	},
+	DeviceTypeEVCharger: {
+		"power":               PropertyBool,
+		"connected":           PropertyBool,
+		"charging":            PropertyBool,
+		"max_power_w":         PropertyNumber,
+		"vehicle_soc":         PropertyNumber,
+		"vehicle_capacity_wh": PropertyNumber,
+		"target_soc":          PropertyNumber,
+		"load_priority":       PropertyNumber,
+		"load_shed":           PropertyBool,
+	},
+	DeviceTypeBlinds: {
+		"position":   PropertyNumber,
+		"sun_facing": PropertyBool,
+	},
+	DeviceTypeIrrigation: {
+		"power":  PropertyBool,
+		"paused": PropertyBool,
+	},
+}
+
+func LookupDeviceProperty(deviceType DeviceType, name string) (PropertyKind, bool) {
+	if kind, ok := DeviceFields[name]; ok {
+		return kind, true
+	}
+	
+	properties, ok := DeviceTypeRegistry[deviceType]
+	if !ok {
+		return "", false
+	}
+	
+	kind, ok := properties[name]
+	return kind, ok
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+type Hazard string
+
+const (
+	HazardSmoke Hazard = "smoke"
+	HazardCO    Hazard = "co"
+	HazardLeak  Hazard = "leak"
+)
+
+const (
+	SmokeAlarmLevel = 10.0
+	COAlarmPPM      = 50.0
+)
+
+var HazardSensors = map[DeviceType]Hazard{
+	DeviceTypeSmokeSensor: HazardSmoke,
+	DeviceTypeCOSensor:    HazardCO,
+	DeviceTypeLeakSensor:  HazardLeak,
+}
+
+var hazardProperties = map[Hazard]string{
+	HazardSmoke: "smoke_detected",
+	HazardCO:    "co_detected",
+	HazardLeak:  "leak_detected",
+}
+
+type LifeSafetyAlarm struct {
+	ID        string    `json:"id"`
+	Hazard    Hazard    `json:"hazard"`
+	SensorID  string    `json:"sensor_id"`
+	Location  string    `json:"location"`
+	Active    bool      `json:"active"`
+	Silenced  bool      `json:"silenced"`
+	Actions   []string  `json:"actions"`
+	StartedAt time.Time `json:"started_at"`
+	ClearedAt time.Time `json:"cleared_at,omitempty"`
+}
+
+func IsSecuritySensor(deviceType DeviceType) bool {
+	return deviceType == DeviceTypeSensor || deviceType == DeviceTypeContactSensor
+}
+
+func HazardDetected(device *Device) (Hazard, bool) {
+	hazard, ok := HazardSensors[device.Type]
+	if !ok {
+		return "", false
+	}
+	detected, _ := device.Properties[hazardProperties[hazard]].(bool)
+	return hazard, detected
+}
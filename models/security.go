@@ -0,0 +1,190 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SecurityZone string
+
+const (
+	SecurityZonePerimeter SecurityZone = "perimeter"
+	SecurityZoneInterior  SecurityZone = "interior"
+	SecurityZone24h       SecurityZone = "24h"
+	SecurityZoneFire      SecurityZone = "fire"
+)
+
+type ArmMode string
+
+const (
+	ArmModeAway  ArmMode = "away"
+	ArmModeStay  ArmMode = "stay"
+	ArmModeNight ArmMode = "night"
+)
+
+type ArmModePolicy struct {
+	Zones      []SecurityZone `json:"zones"`
+	EntryDelay bool           `json:"entry_delay"`
+}
+
+var ArmModePolicies = map[ArmMode]ArmModePolicy{
+	ArmModeAway: {
+		Zones:      []SecurityZone{SecurityZonePerimeter, SecurityZoneInterior, SecurityZone24h, SecurityZoneFire},
+		EntryDelay: true,
+	},
+	ArmModeStay: {
+		Zones:      []SecurityZone{SecurityZonePerimeter, SecurityZone24h, SecurityZoneFire},
+		EntryDelay: true,
+	},
+	ArmModeNight: {
+		Zones:      []SecurityZone{SecurityZonePerimeter, SecurityZone24h, SecurityZoneFire},
+		EntryDelay: false,
+	},
+}
+
+func ValidSecurityZone(zone SecurityZone) bool {
+	switch zone {
+	case SecurityZonePerimeter, SecurityZoneInterior, SecurityZone24h, SecurityZoneFire:
+		return true
+	}
+	return false
+}
+
+func IsAlwaysActiveZone(zone SecurityZone) bool {
+	return zone == SecurityZone24h || zone == SecurityZoneFire
+}
+
+func (p ArmModePolicy) Includes(zone SecurityZone) bool {
+	for _, z := range p.Zones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
+
+func SensorZone(device *Device) SecurityZone {
+	if zone, ok := device.Properties["zone"].(string); ok && ValidSecurityZone(SecurityZone(zone)) {
+		return SecurityZone(zone)
+	}
+	return SecurityZoneInterior
+}
+
+func SensorOpen(device *Device) bool {
+	open, _ := device.Properties["open"].(bool)
+	return open
+}
+
+type AccessSchedule struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start,omitempty"`
+	End   string   `json:"end,omitempty"`
+}
+
+type SecurityUser struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Schedule   *AccessSchedule   `json:"schedule,omitempty"`
+	HasDuress  bool              `json:"has_duress_code"`
+	Locks      []string          `json:"locks"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Salt       string            `json:"-"`
+	PINHash    string            `json:"-"`
+	DuressHash string            `json:"-"`
+	LockCodes  map[string]string `json:"-"`
+}
+
+type AuditEntry struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id,omitempty"`
+	UserName  string    `json:"user_name,omitempty"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (s *AccessSchedule) Allows(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	
+	if len(s.Days) > 0 {
+		day := strings.ToLower(t.Weekday().String())
+		allowed := false
+		for _, d := range s.Days {
+			if strings.ToLower(d) == day {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	
+	if s.Start == "" || s.End == "" {
+		return true
+	}
+	
+	start, err := time.Parse("15:04", s.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", s.End)
+	if err != nil {
+		return false
+	}
+	
+	minute := t.Hour()*60 + t.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+func (s *AccessSchedule) Validate() error {
+	if s == nil {
+		return nil
+	}
+	
+	for _, d := range s.Days {
+		valid := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.ToLower(d) == strings.ToLower(day.String()) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid schedule day: %s", d)
+		}
+	}
+	
+	if (s.Start == "") != (s.End == "") {
+		return fmt.Errorf("schedule needs both start and end")
+	}
+	
+	for _, value := range []string{s.Start, s.End} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", value); err != nil {
+			return fmt.Errorf("invalid schedule time %q, expected HH:MM", value)
+		}
+	}
+	
+	return nil
+}
+
+type AlarmResponseStatus struct {
+	Active          bool      `json:"active"`
+	TriggeredBy     string    `json:"triggered_by,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	EscalationLevel int       `json:"escalation_level"`
+	Devices         []string  `json:"devices"`
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type TariffType string
+
+const (
+	TariffFlat      TariffType = "flat"
+	TariffTimeOfUse TariffType = "time_of_use"
+	TariffTiered    TariffType = "tiered"
+)
+
+type TariffBand struct {
+	Name  string  `json:"name"`
+	Days  string  `json:"days"`
+	Start string  `json:"start"`
+	End   string  `json:"end"`
+	Rate  float64 `json:"rate"`
+}
+
+type TariffTier struct {
+	UpToKWh float64 `json:"up_to_kwh"`
+	Rate    float64 `json:"rate"`
+}
+
+type TariffPlan struct {
+	Name        string       `json:"name"`
+	Type        TariffType   `json:"type"`
+	Currency    string       `json:"currency"`
+	FlatRate    float64      `json:"flat_rate,omitempty"`
+	Bands       []TariffBand `json:"bands,omitempty"`
+	Tiers       []TariffTier `json:"tiers,omitempty"`
+	DailyCharge float64      `json:"daily_charge"`
+}
+
+type TariffQuote struct {
+	Plan         string                 `json:"plan"`
+	Currency     string                 `json:"currency"`
+	EnergyKWh    float64                `json:"energy_kwh"`
+	EnergyCost   float64                `json:"energy_cost"`
+	Days         int                    `json:"days"`
+	FixedCharges float64                `json:"fixed_charges"`
+	TotalCost    float64                `json:"total_cost"`
+	ByBand       map[string]BandSummary `json:"by_band"`
+}
+
+type BandSummary struct {
+	EnergyKWh float64 `json:"energy_kwh"`
+	Cost      float64 `json:"cost"`
+}
+
+func (p TariffPlan) RateAt(t time.Time, monthKWh float64) (float64, string) {
+	switch p.Type {
+	case TariffTimeOfUse:
+		minute := t.Hour()*60 + t.Minute()
+		for _, band := range p.Bands {
+			if band.appliesTo(t.Weekday()) && band.covers(minute) {
+				return band.Rate, band.Name
+			}
+		}
+		return 0, "uncovered"
+		
+	case TariffTiered:
+		for i, tier := range p.Tiers {
+			if tier.UpToKWh <= 0 || monthKWh < tier.UpToKWh {
+				return tier.Rate, fmt.Sprintf("tier_%d", i+1)
+			}
+		}
+		return 0, "uncovered"
+	}
+	
+	return p.FlatRate, "flat"
+}
+
+func (p TariffPlan) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("tariff name is required")
+	}
+	if p.DailyCharge < 0 {
+		return fmt.Errorf("tariff %s: daily_charge must not be negative", p.Name)
+	}
+	
+	switch p.Type {
+	case TariffFlat:
+		if p.FlatRate < 0 {
+			return fmt.Errorf("tariff %s: flat_rate must not be negative", p.Name)
+		}
+		
+	case TariffTimeOfUse:
+		for _, band := range p.Bands {
+			if band.Rate < 0 {
+				return fmt.Errorf("tariff %s: band %s has a negative rate", p.Name, band.Name)
+			}
+			if band.Days != "" && band.Days != "all" && band.Days != "weekday" && band.Days != "weekend" {
+				return fmt.Errorf("tariff %s: band %s days must be all, weekday or weekend", p.Name, band.Name)
+			}
+			if _, err := parseClock(band.Start); err != nil {
+				return fmt.Errorf("tariff %s: band %s: %v", p.Name, band.Name, err)
+			}
+			if _, err := parseClock(band.End); err != nil {
+				return fmt.Errorf("tariff %s: band %s: %v", p.Name, band.Name, err)
+			}
+		}
+		for _, day := range []time.Weekday{time.Monday, time.Sunday} {
+			for minute := 0; minute < 24*60; minute++ {
+				if !p.bandCovers(day, minute) {
+					return fmt.Errorf("tariff %s: no band covers %s %02d:%02d", p.Name, day, minute/60, minute%60)
+				}
+			}
+		}
+		
+	case TariffTiered:
+		if len(p.Tiers) == 0 {
+			return fmt.Errorf("tariff %s: at least one tier is required", p.Name)
+		}
+		previous := 0.0
+		for i, tier := range p.Tiers {
+			if tier.Rate < 0 {
+				return fmt.Errorf("tariff %s: tier %d has a negative rate", p.Name, i+1)
+			}
+			last := i == len(p.Tiers)-1
+			if last && tier.UpToKWh != 0 {
+				return fmt.Errorf("tariff %s: the last tier must be unlimited (up_to_kwh 0)", p.Name)
+			}
+			if !last && tier.UpToKWh <= previous {
+				return fmt.Errorf("tariff %s: tier limits must increase", p.Name)
+			}
+			previous = tier.UpToKWh
+		}
+		
+	default:
+		return fmt.Errorf("tariff %s: unknown type %q", p.Name, p.Type)
+	}
+	
+	return nil
+}
+
+func (p TariffPlan) bandCovers(day time.Weekday, minute int) bool {
+	for _, band := range p.Bands {
+		if band.appliesTo(day) && band.covers(minute) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b TariffBand) appliesTo(day time.Weekday) bool {
+	weekend := day == time.Saturday || day == time.Sunday
+	switch b.Days {
+	case "weekday":
+		return !weekend
+	case "weekend":
+		return weekend
+	}
+	return true
+}
+
+func (b TariffBand) covers(minute int) bool {
+	start, _ := parseClock(b.Start)
+	end, _ := parseClock(b.End)
+	if start == end {
+		return true
+	}
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func parseClock(value string) (int, error) {
+	if value == "24:00" {
+		return 0, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+)
+
+type WeatherHistoryPoint struct {
+	Hour           time.Time `json:"hour"`
+	Samples        int       `json:"samples"`
+	Temperature    float64   `json:"temperature"`
+	TemperatureMin float64   `json:"temperature_min"`
+	TemperatureMax float64   `json:"temperature_max"`
+	Humidity       float64   `json:"humidity"`
+	Pressure       float64   `json:"pressure"`
+	WindSpeed      float64   `json:"wind_speed"`
+	WindSpeedMax   float64   `json:"wind_speed_max"`
+	Condition      string    `json:"condition"`
+	WindDir        string    `json:"wind_direction"`
+}
+
+type WeatherAlertType string
+
+const (
+	WeatherAlertHeat        WeatherAlertType = "extreme_heat"
+	WeatherAlertFreeze      WeatherAlertType = "freeze"
+	WeatherAlertWind        WeatherAlertType = "high_wind"
+	WeatherAlertStorm       WeatherAlertType = "storm"
+	WeatherAlertLowPressure WeatherAlertType = "low_pressure"
+)
+
+var WeatherAlertTypes = []WeatherAlertType{
+	WeatherAlertHeat,
+	WeatherAlertFreeze,
+	WeatherAlertWind,
+	WeatherAlertStorm,
+	WeatherAlertLowPressure,
+}
+
+type WeatherAlert struct {
+	ID        string             `json:"id"`
+	Type      WeatherAlertType   `json:"type"`
+	Severity  string             `json:"severity"`
+	Message   string             `json:"message"`
+	Active    bool               `json:"active"`
+	Metrics   map[string]float64 `json:"metrics"`
+	Peak      map[string]float64 `json:"peak"`
+	Onset     time.Time          `json:"onset"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Expires   time.Time          `json:"expires"`
+	ClearedAt time.Time          `json:"cleared_at,omitempty"`
+}
+
+type WeatherPolicy string
+
+const (
+	WeatherPolicyPrecondition     WeatherPolicy = "precondition"
+	WeatherPolicySunShading       WeatherPolicy = "sun_shading"
+	WeatherPolicyFreezeProtection WeatherPolicy = "freeze_protection"
+	WeatherPolicyIrrigationPause  WeatherPolicy = "irrigation_pause"
+)
+
+var WeatherPolicies = []WeatherPolicy{
+	WeatherPolicyPrecondition,
+	WeatherPolicySunShading,
+	WeatherPolicyFreezeProtection,
+	WeatherPolicyIrrigationPause,
+}
+
+type WeatherAction struct {
+	DeviceID string                 `json:"device_id"`
+	Updates  map[string]interface{} `json:"updates"`
+}
+
+type WeatherDecision struct {
+	Policy    WeatherPolicy   `json:"policy"`
+	Decision  string          `json:"decision"`
+	State     string          `json:"state,omitempty"`
+	Reason    string          `json:"reason"`
+	Actions   []WeatherAction `json:"actions"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+type WeatherPolicyStatus struct {
+	Policy  WeatherPolicy `json:"policy"`
+	Enabled bool          `json:"enabled"`
+	State   string        `json:"state"`
+	Reason  string        `json:"reason,omitempty"`
+	Since   time.Time     `json:"since,omitempty"`
+	Devices []string      `json:"devices"`
+}
@@ -0,0 +1,97 @@
+package random
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+type Source struct {
+	mu      sync.Mutex
+	seed    int64
+	streams map[string]*Stream
+}
+
+func NewSource(seed int64) *Source {
+	return &Source{
+		seed:    seed,
+		streams: make(map[string]*Stream),
+	}
+}
+
+func (s *Source) Seed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	return s.seed
+}
+
+func (s *Source) Stream(name string) *Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if stream, ok := s.streams[name]; ok {
+		return stream
+	}
+	
+	stream := &Stream{rng: rand.New(rand.NewSource(deriveSeed(s.seed, name)))}
+	s.streams[name] = stream
+	
+	return stream
+}
+
+func (s *Source) Reseed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.seed = seed
+	for name, stream := range s.streams {
+		stream.reset(deriveSeed(seed, name))
+	}
+}
+
+func deriveSeed(seed int64, name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return seed ^ int64(h.Sum64())
+}
+
+type Stream struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (r *Stream) reset(seed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.rng = rand.New(rand.NewSource(seed))
+}
+
+func (r *Stream) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	return r.rng.Float64()
+}
+
+func (r *Stream) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	return r.rng.Intn(n)
+}
+
+func (r *Stream) Int63() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	return r.rng.Int63()
+}
+
+func (r *Stream) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	return r.rng.Read(p)
+}
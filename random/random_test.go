@@ -0,0 +1,81 @@
+package random
+
+import "testing"
+
+func draw(s *Source, name string, n int) []int64 {
+	stream := s.Stream(name)
+	values := make([]int64, n)
+	for i := range values {
+		values[i] = stream.Int63()
+	}
+	return values
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSameSeedSameSequence(t *testing.T) {
+	for _, seed := range []int64{0, 1, 42, -7} {
+		a := draw(NewSource(seed), "weather", 20)
+		b := draw(NewSource(seed), "weather", 20)
+		if !equal(a, b) {
+			t.Errorf("seed %d: sequences differ", seed)
+		}
+	}
+	
+	if equal(draw(NewSource(1), "weather", 20), draw(NewSource(2), "weather", 20)) {
+		t.Error("seeds 1 and 2 produced the same sequence")
+	}
+}
+
+func TestStreamsAreIndependent(t *testing.T) {
+	alone := draw(NewSource(42), "devices", 10)
+	
+	mixed := NewSource(42)
+	draw(mixed, "weather", 100)
+	if got := draw(mixed, "devices", 10); !equal(got, alone) {
+		t.Error("drawing from another stream changed the devices sequence")
+	}
+	
+	if equal(draw(NewSource(42), "weather", 10), alone) {
+		t.Error("weather and devices streams produced the same sequence")
+	}
+}
+
+func TestStreamReturnsSameInstance(t *testing.T) {
+	s := NewSource(3)
+	if s.Stream("camera/cam_001") != s.Stream("camera/cam_001") {
+		t.Error("Stream returned a new instance for the same name")
+	}
+}
+
+func TestReseedRestartsStreams(t *testing.T) {
+	s := NewSource(5)
+	stream := s.Stream("locks")
+	first := draw(s, "locks", 10)
+	
+	s.Reseed(5)
+	if got := draw(s, "locks", 10); !equal(got, first) {
+		t.Error("reseeding with the same seed did not restart the sequence")
+	}
+	if s.Stream("locks") != stream {
+		t.Error("reseed replaced the stream instance")
+	}
+	
+	s.Reseed(0)
+	if s.Seed() != 0 {
+		t.Errorf("seed = %d, want 0", s.Seed())
+	}
+	if got := draw(s, "locks", 10); !equal(got, draw(NewSource(0), "locks", 10)) {
+		t.Error("reseeded stream does not match a fresh source with the same seed")
+	}
+}
@@ -0,0 +1,269 @@
+package rules
+
+import (
+	"fmt"
+
+	"multi-agent-framework-testing/models"
+)
+
+type Kind int
+
+const (
+	KindNumber Kind = iota
+	KindString
+	KindBool
+	KindDevice
+	KindTime
+	KindSecurity
+	KindWeather
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindBool:
+		return "bool"
+	case KindDevice:
+		return "device"
+	case KindTime:
+		return "time"
+	case KindSecurity:
+		return "security"
+	case KindWeather:
+		return "weather"
+	default:
+		return "unknown"
+	}
+}
+
+type Type struct {
+	Kind       Kind
+	DeviceType models.DeviceType
+}
+
+func (t Type) String() string {
+	if t.Kind == KindDevice && t.DeviceType != "" {
+		return fmt.Sprintf("device(%s)", t.DeviceType)
+	}
+	return t.Kind.String()
+}
+
+type TypeEnv interface {
+	DeviceType(id string) (models.DeviceType, bool)
+}
+
+var timeFields = map[string]Kind{
+	"hour":    KindNumber,
+	"minute":  KindNumber,
+	"weekday": KindString,
+	"weekend": KindBool,
+}
+
+var securityFields = map[string]Kind{
+	"state":        KindString,
+	"mode":         KindString,
+	"triggered_by": KindString,
+	"armed":        KindBool,
+}
+
+var weatherFields = map[string]Kind{
+	"temperature":    KindNumber,
+	"humidity":       KindNumber,
+	"pressure":       KindNumber,
+	"wind_speed":     KindNumber,
+	"condition":      KindString,
+	"wind_direction": KindString,
+}
+
+func propertyKind(kind models.PropertyKind) Kind {
+	switch kind {
+	case models.PropertyNumber:
+		return KindNumber
+	case models.PropertyBool:
+		return KindBool
+	default:
+		return KindString
+	}
+}
+
+func Check(node Node, env TypeEnv) (Type, error) {
+	switch n := node.(type) {
+	case *NumberLit:
+		return Type{Kind: KindNumber}, nil
+		
+	case *StringLit:
+		return Type{Kind: KindString}, nil
+		
+	case *BoolLit:
+		return Type{Kind: KindBool}, nil
+		
+	case *Ident:
+		switch n.Name {
+		case "time":
+			return Type{Kind: KindTime}, nil
+		case "security":
+			return Type{Kind: KindSecurity}, nil
+		case "weather":
+			return Type{Kind: KindWeather}, nil
+		case "device":
+			return Type{}, errorf(n.Pos, "device must be called with a device ID, e.g. device(\"light_001\")")
+		}
+		return Type{}, errorf(n.Pos, "unknown identifier %q", n.Name)
+		
+	case *Unary:
+		x, err := Check(n.X, env)
+		if err != nil {
+			return Type{}, err
+		}
+		if n.Op == "!" {
+			if x.Kind != KindBool {
+				return Type{}, errorf(n.Pos, "operator ! requires bool, found %s", x)
+			}
+			return x, nil
+		}
+		if x.Kind != KindNumber {
+			return Type{}, errorf(n.Pos, "operator - requires number, found %s", x)
+		}
+		return x, nil
+		
+	case *Binary:
+		return checkBinary(n, env)
+		
+	case *Selector:
+		return checkSelector(n, env)
+		
+	case *Call:
+		return checkCall(n, env)
+	}
+	
+	return Type{}, errorf(node.Position(), "unsupported expression")
+}
+
+func checkBinary(n *Binary, env TypeEnv) (Type, error) {
+	x, err := Check(n.X, env)
+	if err != nil {
+		return Type{}, err
+	}
+	y, err := Check(n.Y, env)
+	if err != nil {
+		return Type{}, err
+	}
+	
+	switch n.Op {
+	case "&&", "||":
+		if x.Kind != KindBool || y.Kind != KindBool {
+			return Type{}, errorf(n.Pos, "operator %s requires bool operands, found %s and %s", n.Op, x, y)
+		}
+		return Type{Kind: KindBool}, nil
+		
+	case "==", "!=":
+		if x.Kind != y.Kind || !isScalar(x.Kind) {
+			return Type{}, errorf(n.Pos, "cannot compare %s with %s", x, y)
+		}
+		return Type{Kind: KindBool}, nil
+		
+	case "<", "<=", ">", ">=":
+		if x.Kind != y.Kind || (x.Kind != KindNumber && x.Kind != KindString) {
+			return Type{}, errorf(n.Pos, "operator %s requires two numbers or two strings, found %s and %s", n.Op, x, y)
+		}
+		return Type{Kind: KindBool}, nil
+		
+	case "+":
+		if x.Kind == KindString && y.Kind == KindString {
+			return x, nil
+		}
+		fallthrough
+		
+	default:
+		if x.Kind != KindNumber || y.Kind != KindNumber {
+			return Type{}, errorf(n.Pos, "operator %s requires number operands, found %s and %s", n.Op, x, y)
+		}
+		return Type{Kind: KindNumber}, nil
+	}
+}
+
+func isScalar(kind Kind) bool {
+	return kind == KindNumber || kind == KindString || kind == KindBool
+}
+
+func checkSelector(n *Selector, env TypeEnv) (Type, error) {
+	x, err := Check(n.X, env)
+	if err != nil {
+		return Type{}, err
+	}
+	
+	var fields map[string]Kind
+	switch x.Kind {
+	case KindDevice:
+		kind, ok := models.LookupDeviceProperty(x.DeviceType, n.Name)
+		if !ok {
+			return Type{}, errorf(n.Pos, "%s devices have no property %q", x.DeviceType, n.Name)
+		}
+		return Type{Kind: propertyKind(kind)}, nil
+	case KindTime:
+		fields = timeFields
+	case KindSecurity:
+		fields = securityFields
+	case KindWeather:
+		fields = weatherFields
+	default:
+		return Type{}, errorf(n.Pos, "%s has no field %q", x, n.Name)
+	}
+	
+	kind, ok := fields[n.Name]
+	if !ok {
+		return Type{}, errorf(n.Pos, "%s has no field %q", x, n.Name)
+	}
+	return Type{Kind: kind}, nil
+}
+
+func checkCall(n *Call, env TypeEnv) (Type, error) {
+	switch fn := n.Fn.(type) {
+	case *Ident:
+		if fn.Name != "device" {
+			return Type{}, errorf(fn.Pos, "unknown function %q", fn.Name)
+		}
+		if len(n.Args) != 1 {
+			return Type{}, errorf(n.Pos, "device expects 1 argument, found %d", len(n.Args))
+		}
+		id, ok := n.Args[0].(*StringLit)
+		if !ok {
+			return Type{}, errorf(n.Args[0].Position(), "device ID must be a string literal")
+		}
+		deviceType, ok := env.DeviceType(id.Value)
+		if !ok {
+			return Type{}, errorf(id.Pos, "unknown device %q", id.Value)
+		}
+		if _, ok := models.DeviceTypeRegistry[deviceType]; !ok {
+			return Type{}, errorf(id.Pos, "device %q has unregistered type %q", id.Value, deviceType)
+		}
+		return Type{Kind: KindDevice, DeviceType: deviceType}, nil
+		
+	case *Selector:
+		x, err := Check(fn.X, env)
+		if err != nil {
+			return Type{}, err
+		}
+		if x.Kind != KindTime || fn.Name != "between" {
+			return Type{}, errorf(fn.Pos, "%s has no method %q", x, fn.Name)
+		}
+		if len(n.Args) != 2 {
+			return Type{}, errorf(n.Pos, "time.between expects 2 arguments, found %d", len(n.Args))
+		}
+		for _, arg := range n.Args {
+			lit, ok := arg.(*StringLit)
+			if !ok {
+				return Type{}, errorf(arg.Position(), "time.between arguments must be \"HH:MM\" string literals")
+			}
+			if _, err := parseClock(lit.Value); err != nil {
+				return Type{}, errorf(lit.Pos, "%v", err)
+			}
+		}
+		return Type{Kind: KindBool}, nil
+	}
+	
+	return Type{}, errorf(n.Pos, "expression is not callable")
+}
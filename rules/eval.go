@@ -0,0 +1,379 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+type Snapshot struct {
+	Devices  map[string]models.Device
+	Security models.SecuritySystem
+	Weather  models.WeatherData
+	Time     time.Time
+}
+
+func NewSnapshot(state *models.SystemState, now time.Time) *Snapshot {
+	snap := &Snapshot{
+		Devices:  make(map[string]models.Device, len(state.Devices)),
+		Security: state.Security,
+		Weather:  state.Weather,
+		Time:     now,
+	}
+	
+	for _, device := range state.Devices {
+		properties := make(map[string]interface{}, len(device.Properties))
+		for key, value := range device.Properties {
+			properties[key] = value
+		}
+		device.Properties = properties
+		snap.Devices[device.ID] = device
+	}
+	
+	return snap
+}
+
+func (s *Snapshot) DeviceType(id string) (models.DeviceType, bool) {
+	device, ok := s.Devices[id]
+	if !ok {
+		return "", false
+	}
+	return device.Type, true
+}
+
+type Expr struct {
+	Source string
+	root   Node
+}
+
+func Compile(src string, env TypeEnv) (*Expr, error) {
+	root, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	
+	typ, err := Check(root, env)
+	if err != nil {
+		return nil, err
+	}
+	if typ.Kind != KindBool {
+		return nil, errorf(root.Position(), "condition must evaluate to bool, found %s", typ)
+	}
+	
+	return &Expr{Source: src, root: root}, nil
+}
+
+func (e *Expr) Eval(snap *Snapshot) (bool, error) {
+	value, err := eval(e.root, snap)
+	if err != nil {
+		return false, err
+	}
+	
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition evaluated to %T, not bool", value)
+	}
+	return result, nil
+}
+
+type deviceValue struct {
+	device models.Device
+}
+
+type timeValue struct{}
+
+type securityValue struct{}
+
+type weatherValue struct{}
+
+func eval(node Node, snap *Snapshot) (interface{}, error) {
+	switch n := node.(type) {
+	case *NumberLit:
+		return n.Value, nil
+		
+	case *StringLit:
+		return n.Value, nil
+		
+	case *BoolLit:
+		return n.Value, nil
+		
+	case *Ident:
+		switch n.Name {
+		case "time":
+			return timeValue{}, nil
+		case "security":
+			return securityValue{}, nil
+		case "weather":
+			return weatherValue{}, nil
+		}
+		return nil, errorf(n.Pos, "unknown identifier %q", n.Name)
+		
+	case *Unary:
+		x, err := eval(n.X, snap)
+		if err != nil {
+			return nil, err
+		}
+		if n.Op == "!" {
+			b, ok := x.(bool)
+			if !ok {
+				return nil, errorf(n.Pos, "operator ! requires bool")
+			}
+			return !b, nil
+		}
+		f, ok := x.(float64)
+		if !ok {
+			return nil, errorf(n.Pos, "operator - requires number")
+		}
+		return -f, nil
+		
+	case *Binary:
+		return evalBinary(n, snap)
+		
+	case *Selector:
+		x, err := eval(n.X, snap)
+		if err != nil {
+			return nil, err
+		}
+		return evalSelector(n, x, snap)
+		
+	case *Call:
+		return evalCall(n, snap)
+	}
+	
+	return nil, errorf(node.Position(), "unsupported expression")
+}
+
+func evalBinary(n *Binary, snap *Snapshot) (interface{}, error) {
+	x, err := eval(n.X, snap)
+	if err != nil {
+		return nil, err
+	}
+	
+	if n.Op == "&&" || n.Op == "||" {
+		left, ok := x.(bool)
+		if !ok {
+			return nil, errorf(n.Pos, "operator %s requires bool operands", n.Op)
+		}
+		if (n.Op == "&&" && !left) || (n.Op == "||" && left) {
+			return left, nil
+		}
+		y, err := eval(n.Y, snap)
+		if err != nil {
+			return nil, err
+		}
+		right, ok := y.(bool)
+		if !ok {
+			return nil, errorf(n.Pos, "operator %s requires bool operands", n.Op)
+		}
+		return right, nil
+	}
+	
+	y, err := eval(n.Y, snap)
+	if err != nil {
+		return nil, err
+	}
+	
+	switch n.Op {
+	case "==":
+		return x == y, nil
+	case "!=":
+		return x != y, nil
+	}
+	
+	if xs, ok := x.(string); ok {
+		ys, ok := y.(string)
+		if !ok {
+			return nil, errorf(n.Pos, "mismatched operands for %s", n.Op)
+		}
+		switch n.Op {
+		case "+":
+			return xs + ys, nil
+		case "<":
+			return xs < ys, nil
+		case "<=":
+			return xs <= ys, nil
+		case ">":
+			return xs > ys, nil
+		case ">=":
+			return xs >= ys, nil
+		}
+		return nil, errorf(n.Pos, "operator %s requires number operands", n.Op)
+	}
+	
+	xf, xok := x.(float64)
+	yf, yok := y.(float64)
+	if !xok || !yok {
+		return nil, errorf(n.Pos, "operator %s requires number operands", n.Op)
+	}
+	
+	switch n.Op {
+	case "<":
+		return xf < yf, nil
+	case "<=":
+		return xf <= yf, nil
+	case ">":
+		return xf > yf, nil
+	case ">=":
+		return xf >= yf, nil
+	case "+":
+		return xf + yf, nil
+	case "-":
+		return xf - yf, nil
+	case "*":
+		return xf * yf, nil
+	case "/":
+		if yf == 0 {
+			return nil, errorf(n.Pos, "division by zero")
+		}
+		return xf / yf, nil
+	case "%":
+		if yf == 0 {
+			return nil, errorf(n.Pos, "division by zero")
+		}
+		return math.Mod(xf, yf), nil
+	}
+	
+	return nil, errorf(n.Pos, "unknown operator %s", n.Op)
+}
+
+func evalSelector(n *Selector, x interface{}, snap *Snapshot) (interface{}, error) {
+	switch v := x.(type) {
+	case deviceValue:
+		return deviceField(v.device, n.Name), nil
+		
+	case timeValue:
+		switch n.Name {
+		case "hour":
+			return float64(snap.Time.Hour()), nil
+		case "minute":
+			return float64(snap.Time.Minute()), nil
+		case "weekday":
+			return strings.ToLower(snap.Time.Weekday().String()), nil
+		case "weekend":
+			day := snap.Time.Weekday()
+			return day == time.Saturday || day == time.Sunday, nil
+		}
+		
+	case securityValue:
+		switch n.Name {
+		case "state":
+			return string(snap.Security.State), nil
+		case "mode":
+			return string(snap.Security.Mode), nil
+		case "triggered_by":
+			return snap.Security.TriggeredBy, nil
+		case "armed":
+			return snap.Security.State == models.SecurityStateArmed, nil
+		}
+		
+	case weatherValue:
+		switch n.Name {
+		case "temperature":
+			return snap.Weather.Temperature, nil
+		case "humidity":
+			return snap.Weather.Humidity, nil
+		case "pressure":
+			return snap.Weather.Pressure, nil
+		case "wind_speed":
+			return snap.Weather.WindSpeed, nil
+		case "condition":
+			return snap.Weather.Condition, nil
+		case "wind_direction":
+			return snap.Weather.WindDir, nil
+		}
+	}
+	
+	return nil, errorf(n.Pos, "no field %q", n.Name)
+}
+
+func deviceField(device models.Device, name string) interface{} {
+	switch name {
+	case "id":
+		return device.ID
+	case "name":
+		return device.Name
+	case "type":
+		return string(device.Type)
+	case "status":
+		return string(device.Status)
+	case "location":
+		return device.Location
+	case "online":
+		return device.Status == models.DeviceStatusOnline
+	}
+	
+	kind, _ := models.LookupDeviceProperty(device.Type, name)
+	value := device.Properties[name]
+	
+	switch kind {
+	case models.PropertyNumber:
+		switch v := value.(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		case int64:
+			return float64(v)
+		}
+		return 0.0
+	case models.PropertyBool:
+		b, _ := value.(bool)
+		return b
+	default:
+		s, _ := value.(string)
+		return s
+	}
+}
+
+func evalCall(n *Call, snap *Snapshot) (interface{}, error) {
+	switch fn := n.Fn.(type) {
+	case *Ident:
+		if fn.Name == "device" && len(n.Args) == 1 {
+			arg, err := eval(n.Args[0], snap)
+			if err != nil {
+				return nil, err
+			}
+			id, _ := arg.(string)
+			device, ok := snap.Devices[id]
+			if !ok {
+				return nil, errorf(n.Args[0].Position(), "device %q not found", id)
+			}
+			return deviceValue{device: device}, nil
+		}
+		
+	case *Selector:
+		if fn.Name == "between" && len(n.Args) == 2 {
+			var bounds [2]int
+			for i, arg := range n.Args {
+				value, err := eval(arg, snap)
+				if err != nil {
+					return nil, err
+				}
+				s, _ := value.(string)
+				minutes, err := parseClock(s)
+				if err != nil {
+					return nil, errorf(arg.Position(), "%v", err)
+				}
+				bounds[i] = minutes
+			}
+			now := snap.Time.Hour()*60 + snap.Time.Minute()
+			if bounds[0] <= bounds[1] {
+				return now >= bounds[0] && now < bounds[1], nil
+			}
+			return now >= bounds[0] || now < bounds[1], nil
+		}
+	}
+	
+	return nil, errorf(n.Pos, "expression is not callable")
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,200 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	MaxSourceLength = 4096
+	MaxDepth        = 64
+)
+
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+type Error struct {
+	Pos     Pos    `json:"position"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+func errorf(pos Pos, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   Pos
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", ".", ","}
+
+type lexer struct {
+	src  []rune
+	off  int
+	line int
+	col  int
+}
+
+func tokenize(src string) ([]token, error) {
+	if len(src) > MaxSourceLength {
+		return nil, errorf(Pos{Line: 1, Column: 1}, "expression exceeds %d characters", MaxSourceLength)
+	}
+	
+	l := &lexer{src: []rune(src), line: 1, col: 1}
+	var tokens []token
+	
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) peek(n int) rune {
+	if l.off+n >= len(l.src) {
+		return 0
+	}
+	return l.src[l.off+n]
+}
+
+func (l *lexer) advance() rune {
+	r := l.src[l.off]
+	l.off++
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+func (l *lexer) next() (token, error) {
+	for l.off < len(l.src) && unicode.IsSpace(l.src[l.off]) {
+		l.advance()
+	}
+	
+	pos := Pos{Line: l.line, Column: l.col}
+	if l.off >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+	
+	r := l.peek(0)
+	switch {
+	case r == '_' || unicode.IsLetter(r):
+		start := l.off
+		for l.off < len(l.src) && (l.src[l.off] == '_' || unicode.IsLetter(l.src[l.off]) || unicode.IsDigit(l.src[l.off])) {
+			l.advance()
+		}
+		return token{kind: tokenIdent, text: string(l.src[start:l.off]), pos: pos}, nil
+		
+	case unicode.IsDigit(r):
+		start := l.off
+		for l.off < len(l.src) && (unicode.IsDigit(l.src[l.off]) || l.src[l.off] == '.') {
+			l.advance()
+		}
+		text := string(l.src[start:l.off])
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return token{}, errorf(pos, "invalid number %q", text)
+		}
+		return token{kind: tokenNumber, text: text, value: value, pos: pos}, nil
+		
+	case r == '"':
+		l.advance()
+		var sb strings.Builder
+		for {
+			if l.off >= len(l.src) || l.peek(0) == '\n' {
+				return token{}, errorf(pos, "unterminated string literal")
+			}
+			c := l.advance()
+			if c == '"' {
+				break
+			}
+			if c == '\\' {
+				if l.off >= len(l.src) {
+					return token{}, errorf(pos, "unterminated string literal")
+				}
+				escPos := Pos{Line: l.line, Column: l.col}
+				switch esc := l.advance(); esc {
+				case '"', '\\':
+					sb.WriteRune(esc)
+				case 'n':
+					sb.WriteRune('\n')
+				case 't':
+					sb.WriteRune('\t')
+				default:
+					return token{}, errorf(escPos, "unknown escape sequence \\%c", esc)
+				}
+				continue
+			}
+			sb.WriteRune(c)
+		}
+		return token{kind: tokenString, text: sb.String(), value: sb.String(), pos: pos}, nil
+	}
+	
+	for _, op := range operators {
+		if l.hasPrefix(op) {
+			for range op {
+				l.advance()
+			}
+			return token{kind: tokenOperator, text: op, pos: pos}, nil
+		}
+	}
+	
+	if r == '&' || r == '|' || r == '=' {
+		return token{}, errorf(pos, "unexpected character %q (did you mean %q?)", r, string([]rune{r, r}))
+	}
+	
+	return token{}, errorf(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) hasPrefix(op string) bool {
+	for i, r := range op {
+		if l.peek(i) != r {
+			return false
+		}
+	}
+	return true
+}
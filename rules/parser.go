@@ -0,0 +1,275 @@
+package rules
+
+type Node interface {
+	Position() Pos
+}
+
+type NumberLit struct {
+	Pos   Pos
+	Value float64
+}
+
+type StringLit struct {
+	Pos   Pos
+	Value string
+}
+
+type BoolLit struct {
+	Pos   Pos
+	Value bool
+}
+
+type Ident struct {
+	Pos  Pos
+	Name string
+}
+
+type Unary struct {
+	Pos Pos
+	Op  string
+	X   Node
+}
+
+type Binary struct {
+	Pos Pos
+	Op  string
+	X   Node
+	Y   Node
+}
+
+type Selector struct {
+	Pos  Pos
+	X    Node
+	Name string
+}
+
+type Call struct {
+	Pos  Pos
+	Fn   Node
+	Args []Node
+}
+
+func (n *NumberLit) Position() Pos { return n.Pos }
+func (n *StringLit) Position() Pos { return n.Pos }
+func (n *BoolLit) Position() Pos   { return n.Pos }
+func (n *Ident) Position() Pos     { return n.Pos }
+func (n *Unary) Position() Pos     { return n.Pos }
+func (n *Binary) Position() Pos    { return n.Pos }
+func (n *Selector) Position() Pos  { return n.Pos }
+func (n *Call) Position() Pos      { return n.Pos }
+
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func Parse(src string) (Node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, errorf(p.peek().pos, "empty expression")
+	}
+	
+	node, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, errorf(tok.pos, "unexpected %s", tok)
+	}
+	
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isOperator(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokenOperator && tok.text == text
+}
+
+func (p *parser) expect(text string) (token, error) {
+	tok := p.next()
+	if tok.kind != tokenOperator || tok.text != text {
+		return tok, errorf(tok.pos, "expected %q, found %s", text, tok)
+	}
+	return tok, nil
+}
+
+func (p *parser) enter(pos Pos) error {
+	p.depth++
+	if p.depth > MaxDepth {
+		return errorf(pos, "expression nested more than %d levels deep", MaxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseBinary(minPrec int) (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	
+	for {
+		tok := p.peek()
+		prec, ok := precedence[tok.text]
+		if tok.kind != tokenOperator || !ok || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+		
+		right, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		
+		left = &Binary{Pos: tok.pos, Op: tok.text, X: left, Y: right}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "!" || tok.text == "-") {
+		if err := p.enter(tok.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Pos: tok.pos, Op: tok.text, X: x}, nil
+	}
+	
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (Node, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	
+	for {
+		switch {
+		case p.isOperator("."):
+			p.next()
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, errorf(name.pos, "expected field name after '.', found %s", name)
+			}
+			node = &Selector{Pos: name.pos, X: node, Name: name.text}
+			
+		case p.isOperator("("):
+			open := p.next()
+			if err := p.enter(open.pos); err != nil {
+				return nil, err
+			}
+			args, err := p.parseArgs()
+			p.leave()
+			if err != nil {
+				return nil, err
+			}
+			node = &Call{Pos: open.pos, Fn: node, Args: args}
+			
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *parser) parseArgs() ([]Node, error) {
+	var args []Node
+	if p.isOperator(")") {
+		p.next()
+		return args, nil
+	}
+	
+	for {
+		arg, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		
+		if p.isOperator(",") {
+			p.next()
+			continue
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	
+	switch tok.kind {
+	case tokenNumber:
+		return &NumberLit{Pos: tok.pos, Value: tok.value.(float64)}, nil
+		
+	case tokenString:
+		return &StringLit{Pos: tok.pos, Value: tok.value.(string)}, nil
+		
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &BoolLit{Pos: tok.pos, Value: true}, nil
+		case "false":
+			return &BoolLit{Pos: tok.pos, Value: false}, nil
+		}
+		return &Ident{Pos: tok.pos, Name: tok.text}, nil
+		
+	case tokenOperator:
+		if tok.text == "(" {
+			if err := p.enter(tok.pos); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+			
+			node, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+	}
+	
+	return nil, errorf(tok.pos, "unexpected %s", tok)
+}
@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+func testSnapshot(now time.Time) *Snapshot {
+	state := &models.SystemState{
+		Devices: []models.Device{
+			{ID: "light_001", Name: "Living Room Light", Type: models.DeviceTypeLight, Status: models.DeviceStatusOnline,
+				Properties: map[string]interface{}{"brightness": 80, "power": true, "color": "warm_white"}},
+			{ID: "sensor_001", Name: "Hall Motion", Type: models.DeviceTypeSensor, Status: models.DeviceStatusOffline,
+				Properties: map[string]interface{}{"motion_detected": true, "sensitivity": 7.5}},
+		},
+		Security: models.SecuritySystem{State: models.SecurityStateArmed, Mode: models.ArmModeAway},
+		Weather:  models.WeatherData{Temperature: -2.5, Condition: "snowy", WindSpeed: 12},
+	}
+	return NewSnapshot(state, now)
+}
+
+func TestEvalConditions(t *testing.T) {
+	// A Saturday at 23:30.
+	snap := testSnapshot(time.Date(2024, 1, 13, 23, 30, 0, 0, time.UTC))
+	
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`device("light_001").brightness > 50`, true},
+		{`device("light_001").brightness * 2 - 60 == 100`, true},
+		{`device("light_001").power && device("light_001").color == "warm_white"`, true},
+		{`device("sensor_001").motion_detected && !device("sensor_001").online`, true},
+		{`device("sensor_001").sensitivity % 2 == 1.5`, true},
+		{`device("sensor_001").status == "offline"`, true},
+		{`device("light_001").name + "!" == "Living Room Light!"`, true},
+		{`time.between("22:00", "06:00")`, true},
+		{`time.between("08:00", "18:00")`, false},
+		{`time.hour == 23 && time.minute >= 30`, true},
+		{`time.weekend && time.weekday == "saturday"`, true},
+		{`security.armed && security.mode == "away"`, true},
+		{`security.state != "armed" || weather.temperature < 0`, true},
+		{`weather.condition == "snowy" && weather.wind_speed > 10`, true},
+		{`-weather.temperature > 2`, true},
+		{`1 + 2 * 3 == 7 && (1 + 2) * 3 == 9`, true},
+		{`"abc" < "abd"`, true},
+		{`false || true && false`, false},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.src, snap)
+		if err != nil {
+			t.Errorf("compile %s: %v", tt.src, err)
+			continue
+		}
+		got, err := expr.Eval(snap)
+		if err != nil {
+			t.Errorf("eval %s: %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestEvalShortCircuits(t *testing.T) {
+	snap := testSnapshot(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	
+	expr, err := Compile(`false && 1 / 0 == 1`, snap)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if got, err := expr.Eval(snap); err != nil || got {
+		t.Errorf("eval = %v, %v, want false without evaluating the division", got, err)
+	}
+	
+	expr, _ = Compile(`true && 1 / 0 == 1`, snap)
+	if _, err := expr.Eval(snap); err == nil || !strings.Contains(err.Error(), "division by zero") {
+		t.Errorf("eval error = %v, want division by zero", err)
+	}
+}
+
+func TestEvalDeviceRemovedAfterCompile(t *testing.T) {
+	snap := testSnapshot(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	expr, err := Compile(`device("light_001").power`, snap)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	
+	delete(snap.Devices, "light_001")
+	if _, err := expr.Eval(snap); err == nil || !strings.Contains(err.Error(), `device "light_001" not found`) {
+		t.Errorf("eval error = %v, want device not found", err)
+	}
+}
+
+func TestSnapshotCopiesProperties(t *testing.T) {
+	state := &models.SystemState{
+		Devices: []models.Device{
+			{ID: "light_001", Type: models.DeviceTypeLight, Properties: map[string]interface{}{"power": true}},
+		},
+	}
+	snap := NewSnapshot(state, time.Now())
+	state.Devices[0].Properties["power"] = false
+	
+	if power, _ := snap.Devices["light_001"].Properties["power"].(bool); !power {
+		t.Error("snapshot property changed with the source device")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	snap := testSnapshot(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	
+	tests := []struct {
+		src     string
+		line    int
+		column  int
+		message string
+	}{
+		{``, 1, 1, "empty expression"},
+		{`device("light_001").power & true`, 1, 27, `unexpected character '&' (did you mean "&&"?)`},
+		{`weather.condition == "sunny`, 1, 22, "unterminated string literal"},
+		{`"a\q" == "b"`, 1, 4, `unknown escape sequence \q`},
+		{`(true`, 1, 6, `expected ")"`},
+		{`true false`, 1, 6, "unexpected"},
+		{`weather.`, 1, 9, "expected field name after '.'"},
+		{`device("lamp_9").power`, 1, 8, `unknown device "lamp_9"`},
+		{`device("light_001").locked`, 1, 21, `light devices have no property "locked"`},
+		{"weather.temperature > 0 &&\n  weather.humidity == \"high\"", 2, 20, "cannot compare number with string"},
+		{`weather.temperature`, 1, 9, "condition must evaluate to bool, found number"},
+		{`security.armed + 1 > 0`, 1, 16, "operator + requires number operands, found bool and number"},
+		{`!weather.condition`, 1, 1, "operator ! requires bool"},
+		{`time.between(1, "06:00")`, 1, 14, "string"},
+		{`sun.up`, 1, 1, `unknown identifier "sun"`},
+		{`alarm("x")`, 1, 1, `unknown function "alarm"`},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.src, snap)
+		var ruleErr *Error
+		if !errors.As(err, &ruleErr) {
+			t.Errorf("compile %q: error = %v, want a positioned *Error", tt.src, err)
+			continue
+		}
+		if ruleErr.Pos.Line != tt.line || ruleErr.Pos.Column != tt.column || !strings.Contains(ruleErr.Message, tt.message) {
+			t.Errorf("compile %q: error at %s %q, want %d:%d mentioning %q", tt.src, ruleErr.Pos, ruleErr.Message, tt.line, tt.column, tt.message)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	deep := strings.Repeat("!", MaxDepth+1) + "true"
+	if _, err := Parse(deep); err == nil || !strings.Contains(err.Error(), "nested more than") {
+		t.Errorf("parse of %d nested operators: error = %v, want a depth error", MaxDepth+1, err)
+	}
+	
+	calls := strings.Repeat("f(", MaxDepth+1) + "1" + strings.Repeat(")", MaxDepth+1)
+	if _, err := Parse(calls); err == nil || !strings.Contains(err.Error(), "nested more than") {
+		t.Errorf("parse of %d nested calls: error = %v, want a depth error", MaxDepth+1, err)
+	}
+	
+	long := "true" + strings.Repeat(" ", MaxSourceLength)
+	if _, err := Parse(long); err == nil {
+		t.Error("parse of an over-long expression succeeded")
+	}
+}
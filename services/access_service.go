@@ -0,0 +1,332 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const (
+	pinHashRounds     = 10000
+	maxFailedAttempts = 5
+	lockoutDuration   = time.Minute
+)
+
+var ErrAccessDenied = errors.New("access denied")
+
+var pinPattern = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+type AccessService struct {
+	store       *storage.MemoryStore
+	clock       clock.Clock
+	mu          sync.Mutex
+	failures    int
+	lockedUntil time.Time
+}
+
+func NewAccessService(store *storage.MemoryStore, clk clock.Clock) *AccessService {
+	return &AccessService{
+		store: store,
+		clock: clk,
+	}
+}
+
+func (a *AccessService) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	a.failures = 0
+	a.lockedUntil = time.Time{}
+}
+
+func (a *AccessService) Required() bool {
+	return len(a.store.ListUsers()) > 0
+}
+
+func (a *AccessService) CreateUser(name, pin, duressPIN string, schedule *models.AccessSchedule) (*models.SecurityUser, error) {
+	if name == "" {
+		return nil, fmt.Errorf("user name is required")
+	}
+	
+	if !pinPattern.MatchString(pin) {
+		return nil, fmt.Errorf("PIN must be 4 to 8 digits")
+	}
+	
+	if duressPIN != "" {
+		if !pinPattern.MatchString(duressPIN) {
+			return nil, fmt.Errorf("duress PIN must be 4 to 8 digits")
+		}
+		if duressPIN == pin {
+			return nil, fmt.Errorf("duress PIN must differ from the PIN")
+		}
+	}
+	
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	
+	for _, existing := range a.store.ListUsers() {
+		if matchesPIN(existing.Salt, pin, existing.PINHash) || matchesPIN(existing.Salt, pin, existing.DuressHash) ||
+			(duressPIN != "" && (matchesPIN(existing.Salt, duressPIN, existing.PINHash) || matchesPIN(existing.Salt, duressPIN, existing.DuressHash))) {
+			return nil, a.rejectReusedCode("create_user", "security", "PIN already in use", "PIN not accepted, choose a different PIN")
+		}
+	}
+	
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	
+	user := &models.SecurityUser{
+		ID:        utils.GenerateID("user"),
+		Name:      name,
+		Schedule:  schedule,
+		Locks:     []string{},
+		Salt:      salt,
+		PINHash:   hashPIN(salt, pin),
+		LockCodes: make(map[string]string),
+	}
+	
+	if duressPIN != "" {
+		user.DuressHash = hashPIN(salt, duressPIN)
+		user.HasDuress = true
+	}
+	
+	if err := a.store.AddUser(user); err != nil {
+		return nil, err
+	}
+	
+	return user, nil
+}
+
+func (a *AccessService) ListUsers() []*models.SecurityUser {
+	return a.store.ListUsers()
+}
+
+func (a *AccessService) DeleteUser(id string) error {
+	return a.store.DeleteUser(id)
+}
+
+func (a *AccessService) SetLockCode(userID, lockID, code string) error {
+	user, err := a.store.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	
+	lock, err := a.store.GetDevice(lockID)
+	if err != nil {
+		return err
+	}
+	if lock.Type != models.DeviceTypeLock {
+		return fmt.Errorf("device %s is not a lock", lockID)
+	}
+	
+	if code == "" {
+		return a.store.SetUserLockCode(user.ID, lockID, "")
+	}
+	
+	if !pinPattern.MatchString(code) {
+		return fmt.Errorf("access code must be 4 to 8 digits")
+	}
+	
+	for _, existing := range a.store.ListUsers() {
+		if existing.ID != user.ID && matchesPIN(existing.Salt, code, existing.LockCodes[lockID]) {
+			return a.rejectReusedCode("set_lock_code", lockID, "access code already in use", "access code not accepted, choose a different code")
+		}
+	}
+	
+	return a.store.SetUserLockCode(user.ID, lockID, hashPIN(user.Salt, code))
+}
+
+func (a *AccessService) Authenticate(pin, action, target string) (*models.SecurityUser, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	if err := a.checkLockout(action, target); err != nil {
+		return nil, false, err
+	}
+	
+	for _, user := range a.store.ListUsers() {
+		duress := matchesPIN(user.Salt, pin, user.DuressHash)
+		if !duress && !matchesPIN(user.Salt, pin, user.PINHash) {
+			continue
+		}
+		
+		if !user.Schedule.Allows(a.clock.Now()) {
+			a.Audit(user, action, target, false, "outside schedule")
+			return nil, false, fmt.Errorf("%w: user %s is not allowed at this time", ErrAccessDenied, user.Name)
+		}
+		
+		a.failures = 0
+		return user, duress, nil
+	}
+	
+	return nil, false, a.recordFailure(action, target, "invalid PIN")
+}
+
+func (a *AccessService) AuthenticateLock(lockID, code string) (*models.SecurityUser, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	if err := a.checkLockout("unlock", lockID); err != nil {
+		return nil, err
+	}
+	
+	for _, user := range a.store.ListUsers() {
+		if !matchesPIN(user.Salt, code, user.LockCodes[lockID]) {
+			continue
+		}
+		
+		if !user.Schedule.Allows(a.clock.Now()) {
+			a.Audit(user, "unlock", lockID, false, "outside schedule")
+			return nil, fmt.Errorf("%w: user %s is not allowed at this time", ErrAccessDenied, user.Name)
+		}
+		
+		a.failures = 0
+		return user, nil
+	}
+	
+	return nil, a.recordFailure("unlock", lockID, "invalid access code")
+}
+
+func (a *AccessService) AuthorizeUser(userID, action, target string) (*models.SecurityUser, error) {
+	user, err := a.store.GetUser(userID)
+	if err != nil {
+		a.Audit(nil, action, target, false, "unknown user")
+		return nil, fmt.Errorf("%w: %v", ErrAccessDenied, err)
+	}
+	
+	if action == "unlock" && user.LockCodes[target] == "" {
+		a.Audit(user, action, target, false, "no access code for lock")
+		return nil, fmt.Errorf("%w: user %s has no access code for lock %s", ErrAccessDenied, user.Name, target)
+	}
+	
+	if !user.Schedule.Allows(a.clock.Now()) {
+		a.Audit(user, action, target, false, "outside schedule")
+		return nil, fmt.Errorf("%w: user %s is not allowed at this time", ErrAccessDenied, user.Name)
+	}
+	
+	return user, nil
+}
+
+func (a *AccessService) AuthorizeAdmin(pin, action, target string) (*models.SecurityUser, error) {
+	if !a.Required() {
+		return nil, nil
+	}
+	
+	user, duress, err := a.Authenticate(pin, action, target)
+	if err != nil {
+		return nil, err
+	}
+	
+	if duress {
+		a.RaiseDuress(user, target)
+		a.Audit(user, action, target, false, "duress PIN")
+		return nil, fmt.Errorf("%w: duress PIN cannot authorize changes", ErrAccessDenied)
+	}
+	
+	return a.AuthorizeUser(user.ID, action, target)
+}
+
+func (a *AccessService) RaiseDuress(user *models.SecurityUser, target string) {
+	a.store.AddSystemEvent(models.SystemEvent{
+		ID:      utils.GenerateID("duress_alarm"),
+		Type:    "duress_alarm",
+		Source:  "security",
+		Message: fmt.Sprintf("Duress code entered by %s", user.Name),
+		Data: map[string]interface{}{
+			"user_id": user.ID,
+			"target":  target,
+		},
+		Timestamp: a.clock.Now(),
+		Severity:  "critical",
+	})
+}
+
+func (a *AccessService) Audit(user *models.SecurityUser, action, target string, success bool, reason string) {
+	entry := models.AuditEntry{
+		ID:        utils.GenerateID("audit"),
+		Action:    action,
+		Target:    target,
+		Success:   success,
+		Reason:    reason,
+		Timestamp: a.clock.Now(),
+	}
+	
+	if user != nil {
+		entry.UserID = user.ID
+		entry.UserName = user.Name
+	}
+	
+	a.store.AddAuditEntry(entry)
+}
+
+func (a *AccessService) GetAuditLog(limit int) []models.AuditEntry {
+	return a.store.GetAuditLog(limit)
+}
+
+func (a *AccessService) checkLockout(action, target string) error {
+	if a.clock.Now().Before(a.lockedUntil) {
+		a.Audit(nil, action, target, false, "locked out")
+		return fmt.Errorf("%w: too many failed attempts, try again after %s", ErrAccessDenied, a.lockedUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (a *AccessService) rejectReusedCode(action, target, reason, message string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	if err := a.checkLockout(action, target); err != nil {
+		return err
+	}
+	
+	a.recordFailure(action, target, reason)
+	return errors.New(message)
+}
+
+func (a *AccessService) recordFailure(action, target, reason string) error {
+	a.failures++
+	if a.failures >= maxFailedAttempts {
+		a.failures = 0
+		a.lockedUntil = a.clock.Now().Add(lockoutDuration)
+		reason = reason + ", keypad locked out"
+	}
+	
+	a.Audit(nil, action, target, false, reason)
+	return fmt.Errorf("%w: %s", ErrAccessDenied, reason)
+}
+
+func hashPIN(salt, pin string) string {
+	sum := sha256.Sum256([]byte(salt + pin))
+	for i := 1; i < pinHashRounds; i++ {
+		sum = sha256.Sum256(append(sum[:], salt...))
+	}
+	return hex.EncodeToString(sum[:])
+}
+
+func matchesPIN(salt, pin, hash string) bool {
+	if hash == "" || pin == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashPIN(salt, pin)), []byte(hash)) == 1
+}
+
+func newSalt() (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestAccess(t *testing.T) (*AccessService, *storage.MemoryStore, *clock.Simulated) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	return NewAccessService(store, clk), store, clk
+}
+
+func lastAudit(store *storage.MemoryStore) models.AuditEntry {
+	log := store.GetAuditLog(1)
+	if len(log) == 0 {
+		return models.AuditEntry{}
+	}
+	return log[0]
+}
+
+func TestAuthenticateLocksOutAfterRepeatedFailures(t *testing.T) {
+	access, store, clk := newTestAccess(t)
+	if _, err := access.CreateUser("Alice", "1234", "", nil); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	
+	for i := 1; i < maxFailedAttempts; i++ {
+		if _, _, err := access.Authenticate("0000", "disarm", "security"); !errors.Is(err, ErrAccessDenied) {
+			t.Fatalf("attempt %d: error = %v, want access denied", i, err)
+		}
+	}
+	if _, _, err := access.Authenticate("0000", "disarm", "security"); err == nil || !strings.Contains(err.Error(), "keypad locked out") {
+		t.Fatalf("attempt %d: error = %v, want a lockout", maxFailedAttempts, err)
+	}
+	if entry := lastAudit(store); entry.Success || entry.Reason != "invalid PIN, keypad locked out" {
+		t.Errorf("audit entry = %+v, want the failed attempt that caused the lockout", entry)
+	}
+	
+	if _, _, err := access.Authenticate("1234", "disarm", "security"); err == nil || !strings.Contains(err.Error(), "too many failed attempts") {
+		t.Errorf("correct PIN during lockout: error = %v, want locked out", err)
+	}
+	
+	clk.Step(lockoutDuration)
+	user, duress, err := access.Authenticate("1234", "disarm", "security")
+	if err != nil || user == nil || user.Name != "Alice" || duress {
+		t.Fatalf("correct PIN after lockout = %v, %v, %v, want Alice", user, duress, err)
+	}
+	
+	// A success clears the failure count, so the next lockout takes a full set of failures.
+	for i := 1; i < maxFailedAttempts; i++ {
+		access.Authenticate("0000", "disarm", "security")
+	}
+	if _, _, err := access.Authenticate("1234", "disarm", "security"); err != nil {
+		t.Errorf("correct PIN after %d failures: %v", maxFailedAttempts-1, err)
+	}
+}
+
+func TestDuressPINRaisesSilentAlarm(t *testing.T) {
+	access, store, _ := newTestAccess(t)
+	user, err := access.CreateUser("Alice", "1234", "9999", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	
+	authenticated, duress, err := access.Authenticate("9999", "disarm", "security")
+	if err != nil || authenticated.ID != user.ID || !duress {
+		t.Fatalf("duress PIN = %v, %v, %v, want Alice under duress", authenticated, duress, err)
+	}
+	
+	if _, err := access.AuthorizeAdmin("9999", "create_user", "security"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("duress PIN authorizing a change: error = %v, want access denied", err)
+	}
+	if entry := lastAudit(store); entry.Success || entry.Reason != "duress PIN" || entry.UserID != user.ID {
+		t.Errorf("audit entry = %+v, want a failed duress entry for Alice", entry)
+	}
+	
+	alarms := 0
+	for _, event := range store.GetSystemEvents(0) {
+		if event.Type == "duress_alarm" {
+			alarms++
+			if event.Data["user_id"] != user.ID || event.Severity != "critical" {
+				t.Errorf("duress alarm = %+v, want a critical alarm for Alice", event)
+			}
+		}
+	}
+	if alarms != 1 {
+		t.Errorf("duress alarms = %d, want 1", alarms)
+	}
+}
+
+func TestCreateUserRejectsReusedPINWithoutRevealingIt(t *testing.T) {
+	access, store, _ := newTestAccess(t)
+	if _, err := access.CreateUser("Alice", "1234", "9999", nil); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	
+	for _, pins := range [][2]string{{"1234", ""}, {"9999", ""}, {"5555", "1234"}} {
+		_, err := access.CreateUser("Bob", pins[0], pins[1], nil)
+		if err == nil || err.Error() != "PIN not accepted, choose a different PIN" {
+			t.Errorf("create with PIN %s and duress %q: error = %v, want the generic rejection", pins[0], pins[1], err)
+		}
+		if entry := lastAudit(store); entry.Action != "create_user" || entry.Success || entry.Reason != "PIN already in use" {
+			t.Errorf("audit entry = %+v, want a failed create_user", entry)
+		}
+	}
+	
+	// Collisions count towards the lockout like failed PIN entries.
+	for i := 3; i < maxFailedAttempts; i++ {
+		access.CreateUser("Bob", "1234", "", nil)
+	}
+	if _, err := access.CreateUser("Bob", "1234", "", nil); err == nil || !strings.Contains(err.Error(), "too many failed attempts") {
+		t.Errorf("create after %d collisions: error = %v, want locked out", maxFailedAttempts, err)
+	}
+	if _, _, err := access.Authenticate("1234", "disarm", "security"); err == nil {
+		t.Error("keypad not locked out after repeated PIN collisions")
+	}
+	if users := access.ListUsers(); len(users) != 1 {
+		t.Errorf("users = %d, want only Alice", len(users))
+	}
+}
+
+func TestScheduleRestrictsAccess(t *testing.T) {
+	access, _, clk := newTestAccess(t)
+	schedule := &models.AccessSchedule{Days: []string{"monday"}, Start: "09:00", End: "13:00"}
+	if _, err := access.CreateUser("Cleaner", "4321", "", schedule); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	
+	if _, _, err := access.Authenticate("4321", "disarm", "security"); err != nil {
+		t.Errorf("within schedule: %v", err)
+	}
+	clk.Step(2 * time.Hour)
+	if _, _, err := access.Authenticate("4321", "disarm", "security"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("outside schedule: error = %v, want access denied", err)
+	}
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const lightFlashInterval = 2 * time.Second
+
+type alarmResponse struct {
+	status  models.AlarmResponseStatus
+	restore map[string]map[string]interface{}
+	timers  []clock.Timer
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+type AlarmResponseService struct {
+	store    *storage.MemoryStore
+	devices  *DeviceService
+	notifier *NotificationService
+	clock    clock.Clock
+	config   *config.Config
+	mu       sync.Mutex
+	active   *alarmResponse
+}
+
+func NewAlarmResponseService(store *storage.MemoryStore, security *SecurityService, devices *DeviceService,
+	notifier *NotificationService, clk clock.Clock, cfg *config.Config) *AlarmResponseService {
+	
+	service := &AlarmResponseService{
+		store:    store,
+		devices:  devices,
+		notifier: notifier,
+		clock:    clk,
+		config:   cfg,
+	}
+	
+	security.OnTransition(service.handleTransition)
+	
+	return service
+}
+
+func (a *AlarmResponseService) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	response := a.active
+	if response == nil {
+		return
+	}
+	a.active = nil
+	
+	for _, timer := range response.timers {
+		timer.Stop()
+	}
+	close(response.done)
+	<-response.stopped
+}
+
+func (a *AlarmResponseService) Status() models.AlarmResponseStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	if a.active == nil {
+		return models.AlarmResponseStatus{Devices: []string{}}
+	}
+	
+	status := a.active.status
+	status.Devices = append([]string(nil), a.active.status.Devices...)
+	return status
+}
+
+func (a *AlarmResponseService) handleTransition(transition models.SecurityTransition, security models.SecuritySystem) {
+	if transition.To == models.SecurityStateTriggered {
+		a.start(security)
+	} else if transition.From == models.SecurityStateTriggered {
+		a.cancel(transition.Cause, transition.Source)
+	}
+}
+
+func (a *AlarmResponseService) start(security models.SecuritySystem) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	if a.active != nil || a.store.GetSecurity().State != models.SecurityStateTriggered {
+		return
+	}
+	
+	settings := a.config.AlarmResponse
+	response := &alarmResponse{
+		status: models.AlarmResponseStatus{
+			Active:      true,
+			TriggeredBy: security.TriggeredBy,
+			StartedAt:   a.clock.Now(),
+			Devices:     []string{},
+		},
+		restore: make(map[string]map[string]interface{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	
+	zone, location := a.affectedArea(security.TriggeredBy)
+	lights := make([]string, 0)
+	
+	for _, device := range a.devices.ListDevices() {
+		if device.Status != models.DeviceStatusOnline {
+			continue
+		}
+		
+		switch device.Type {
+		case models.DeviceTypeAlarm:
+			if settings.Siren {
+				a.apply(response, device, map[string]interface{}{"active": true})
+			}
+			
+		case models.DeviceTypeCamera:
+			if settings.RecordCameras && cameraCovers(device, zone, location) {
+				a.apply(response, device, map[string]interface{}{"recording": true})
+			}
+			
+		case models.DeviceTypeLight:
+			if settings.Lights != "off" {
+				a.apply(response, device, map[string]interface{}{"power": true, "brightness": 100})
+				lights = append(lights, device.ID)
+			}
+		}
+	}
+	sort.Strings(response.status.Devices)
+	
+	if settings.Lights == "flash" && len(lights) > 0 {
+		go a.flashLights(response, lights)
+	} else {
+		close(response.stopped)
+	}
+	
+	message := fmt.Sprintf("Alarm triggered by %s", security.TriggeredBy)
+	for _, channel := range settings.Notify {
+		a.notifier.Notify(channel, "critical", message, map[string]interface{}{
+			"triggered_by": security.TriggeredBy,
+		})
+	}
+	
+	for i, step := range settings.Escalation {
+		level, step := i+1, step
+		timer := a.clock.AfterFunc(time.Duration(step.AfterSeconds)*time.Second, func() {
+			a.escalate(response, level, step)
+		})
+		response.timers = append(response.timers, timer)
+	}
+	
+	a.active = response
+	
+	a.store.AddSystemEvent(models.SystemEvent{
+		ID:      utils.GenerateID("alarm_response_started"),
+		Type:    "alarm_response_started",
+		Source:  "security",
+		Message: message,
+		Data: map[string]interface{}{
+			"triggered_by": security.TriggeredBy,
+			"devices":      response.status.Devices,
+		},
+		Timestamp: a.clock.Now(),
+		Severity:  "critical",
+	})
+}
+
+func (a *AlarmResponseService) escalate(response *alarmResponse, level int, step config.EscalationStep) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	if a.active != response {
+		return
+	}
+	
+	response.status.EscalationLevel = level
+	message := fmt.Sprintf("Alarm still active after %d seconds (escalation level %d)", step.AfterSeconds, level)
+	
+	for _, channel := range step.Notify {
+		a.notifier.Notify(channel, "critical", message, map[string]interface{}{
+			"triggered_by":     response.status.TriggeredBy,
+			"escalation_level": level,
+		})
+	}
+	
+	a.store.AddSystemEvent(models.SystemEvent{
+		ID:      utils.GenerateID("alarm_escalated"),
+		Type:    "alarm_escalated",
+		Source:  "security",
+		Message: message,
+		Data: map[string]interface{}{
+			"escalation_level": level,
+			"notify":           step.Notify,
+		},
+		Timestamp: a.clock.Now(),
+		Severity:  "critical",
+	})
+}
+
+func (a *AlarmResponseService) cancel(cause, source string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	response := a.active
+	if response == nil {
+		return
+	}
+	a.active = nil
+	
+	for _, timer := range response.timers {
+		timer.Stop()
+	}
+	
+	close(response.done)
+	<-response.stopped
+	
+	for deviceID, properties := range response.restore {
+		a.devices.UpdateDevice(deviceID, properties)
+	}
+	
+	message := fmt.Sprintf("Alarm cleared (%s by %s)", cause, source)
+	for _, channel := range a.config.AlarmResponse.Notify {
+		a.notifier.Notify(channel, "info", message, map[string]interface{}{
+			"cause":  cause,
+			"source": source,
+		})
+	}
+	
+	a.store.AddSystemEvent(models.SystemEvent{
+		ID:      utils.GenerateID("alarm_response_cancelled"),
+		Type:    "alarm_response_cancelled",
+		Source:  "security",
+		Message: message,
+		Data: map[string]interface{}{
+			"cause":            cause,
+			"source":           source,
+			"escalation_level": response.status.EscalationLevel,
+		},
+		Timestamp: a.clock.Now(),
+		Severity:  "info",
+	})
+}
+
+func (a *AlarmResponseService) apply(response *alarmResponse, device *models.Device, updates map[string]interface{}) {
+	previous := make(map[string]interface{}, len(updates))
+	for key := range updates {
+		if value, ok := device.Properties[key]; ok {
+			previous[key] = value
+		}
+	}
+	
+	response.restore[device.ID] = previous
+	response.status.Devices = append(response.status.Devices, device.ID)
+	a.devices.UpdateDevice(device.ID, updates)
+}
+
+func (a *AlarmResponseService) flashLights(response *alarmResponse, lights []string) {
+	defer close(response.stopped)
+	
+	ticker := a.clock.NewTicker(lightFlashInterval)
+	defer ticker.Stop()
+	
+	on := true
+	for {
+		select {
+		case <-response.done:
+			return
+		case <-ticker.C():
+			on = !on
+			for _, id := range lights {
+				a.devices.UpdateDevice(id, map[string]interface{}{
+					"power": on,
+				})
+			}
+		}
+	}
+}
+
+func (a *AlarmResponseService) affectedArea(triggeredBy string) (models.SecurityZone, string) {
+	device, err := a.store.GetDevice(triggeredBy)
+	if err != nil || !models.IsSecuritySensor(device.Type) {
+		return "", ""
+	}
+	return models.SensorZone(device), device.Location
+}
+
+func cameraCovers(camera *models.Device, zone models.SecurityZone, location string) bool {
+	if zone == "" && location == "" {
+		return true
+	}
+	
+	if cameraZone, ok := camera.Properties["zone"].(string); ok && models.SecurityZone(cameraZone) == zone {
+		return true
+	}
+	return camera.Location == location
+}
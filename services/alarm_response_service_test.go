@@ -0,0 +1,109 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestResponse(t *testing.T) (*AlarmResponseService, *SecurityService, *NotificationService, *storage.MemoryStore, *clock.Simulated) {
+	security, store, clk, cfg := newTestSecurity(t)
+	cfg.AlarmResponse = config.AlarmResponseConfig{
+		Siren:         true,
+		RecordCameras: true,
+		Lights:        "on",
+		Notify:        []string{"push"},
+		Escalation: []config.EscalationStep{
+			{AfterSeconds: 30, Notify: []string{"sms"}},
+			{AfterSeconds: 120, Notify: []string{"monitoring_center"}},
+		},
+	}
+	
+	for _, device := range []*models.Device{
+		{ID: "siren_1", Name: "Siren", Type: models.DeviceTypeAlarm, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"active": false}},
+		{ID: "camera_1", Name: "Porch Camera", Type: models.DeviceTypeCamera, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"recording": false, "zone": "perimeter"}},
+		{ID: "camera_2", Name: "Garden Camera", Type: models.DeviceTypeCamera, Status: models.DeviceStatusOnline, Location: "Garden",
+			Properties: map[string]interface{}{"recording": false, "zone": "interior"}},
+		{ID: "light_1", Name: "Hall Light", Type: models.DeviceTypeLight, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": false, "brightness": 20}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	// Without the simulator, so stepping the clock only runs alarm timers.
+	devices := &DeviceService{store: store, security: security}
+	notifier := NewNotificationService(store, clk)
+	return NewAlarmResponseService(store, security, devices, notifier, clk, cfg), security, notifier, store, clk
+}
+
+func notificationsOn(notifier *NotificationService, channel string) []models.Notification {
+	var sent []models.Notification
+	for _, notification := range notifier.GetNotifications(0) {
+		if notification.Channel == channel {
+			sent = append(sent, notification)
+		}
+	}
+	return sent
+}
+
+func TestAlarmResponseRunsAndEscalates(t *testing.T) {
+	responses, security, notifier, store, clk := newTestResponse(t)
+	
+	security.Arm("test", models.ArmModeAway, false)
+	clk.Step(30 * time.Second)
+	security.SensorTripped("door_1")
+	clk.Step(20 * time.Second)
+	assertSecurityState(t, security, models.SecurityStateTriggered)
+	
+	status := responses.Status()
+	if !status.Active || status.TriggeredBy != "door_1" || strings.Join(status.Devices, ",") != "camera_1,light_1,siren_1" {
+		t.Fatalf("status = %+v, want an active response on camera_1, light_1 and siren_1", status)
+	}
+	assertProperty(t, store, "siren_1", "active", true)
+	assertProperty(t, store, "camera_1", "recording", true)
+	assertProperty(t, store, "camera_2", "recording", false)
+	assertProperty(t, store, "light_1", "brightness", 100)
+	if sent := notificationsOn(notifier, "push"); len(sent) != 1 || sent[0].Severity != "critical" {
+		t.Errorf("push notifications = %+v, want one critical alert", sent)
+	}
+	
+	clk.Step(30 * time.Second)
+	if level := responses.Status().EscalationLevel; level != 1 || len(notificationsOn(notifier, "sms")) != 1 {
+		t.Errorf("escalation level %d with %d sms, want level 1 with one sms", level, len(notificationsOn(notifier, "sms")))
+	}
+}
+
+func TestDisarmCancelsResponseAndRestoresDevices(t *testing.T) {
+	responses, security, notifier, store, clk := newTestResponse(t)
+	
+	security.Trigger("keypad")
+	assertProperty(t, store, "siren_1", "active", true)
+	// An unknown trigger source is not tied to an area, so every camera records.
+	assertProperty(t, store, "camera_2", "recording", true)
+	
+	security.Disarm("test")
+	if responses.Status().Active {
+		t.Fatal("response still active after disarm")
+	}
+	assertProperty(t, store, "siren_1", "active", false)
+	assertProperty(t, store, "camera_1", "recording", false)
+	assertProperty(t, store, "light_1", "power", false)
+	assertProperty(t, store, "light_1", "brightness", 20)
+	if got := countEvents(store, "alarm_response_cancelled"); got != 1 {
+		t.Errorf("alarm_response_cancelled events = %d, want 1", got)
+	}
+	
+	clk.Step(3 * time.Minute)
+	if sent := notificationsOn(notifier, "sms"); len(sent) != 0 {
+		t.Errorf("sms notifications after disarm = %d, want escalation stopped", len(sent))
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/utils"
+)
+
+type sensorTrip struct {
+	sensorID string
+	weight   float64
+	at       time.Time
+}
+
+func (s *SecurityService) resetVerification() {
+	s.trips = nil
+	s.tripCounts = make(map[string]int)
+}
+
+func (s *SecurityService) verifyTrip(security *models.SecuritySystem, device *models.Device) bool {
+	settings := s.config.AlarmVerification
+	now := s.clock.Now()
+	
+	s.tripCounts[device.ID]++
+	if settings.SwingerLimit > 0 && s.tripCounts[device.ID] > settings.SwingerLimit {
+		security.ActiveSensors = utils.RemoveFromSlice(security.ActiveSensors, device.ID)
+		security.BypassedSensors = append(security.BypassedSensors, device.ID)
+		updated := *security
+		s.store.UpdateSecurity(&updated)
+		
+		remaining := make([]sensorTrip, 0, len(s.trips))
+		for _, trip := range s.trips {
+			if trip.sensorID != device.ID {
+				remaining = append(remaining, trip)
+			}
+		}
+		s.trips = remaining
+		
+		s.recordSuppression("sensor_swinger_shutdown", device.ID,
+			fmt.Sprintf("Sensor %s shut down after %d trips in this arming period", device.ID, s.tripCounts[device.ID]),
+			map[string]interface{}{
+				"trips": s.tripCounts[device.ID],
+				"limit": settings.SwingerLimit,
+			})
+		return false
+	}
+	
+	window := time.Duration(settings.WindowSeconds) * time.Second
+	trips := make([]sensorTrip, 0, len(s.trips)+1)
+	for _, trip := range s.trips {
+		if now.Sub(trip.at) < window {
+			trips = append(trips, trip)
+		}
+	}
+	trips = append(trips, sensorTrip{
+		sensorID: device.ID,
+		weight:   sensorWeight(device, settings.SensitivityWeighting),
+		at:       now,
+	})
+	s.trips = trips
+	
+	distinct := make(map[string]bool)
+	score := 0.0
+	for _, trip := range trips {
+		distinct[trip.sensorID] = true
+		score += trip.weight
+	}
+	score = utils.RoundToDecimal(score, 2)
+	
+	reasons := make([]string, 0)
+	if settings.CrossZone && len(distinct) < 2 {
+		reasons = append(reasons, "waiting for a second sensor")
+	}
+	if settings.SensitivityWeighting && score < 1.0 {
+		reasons = append(reasons, fmt.Sprintf("weighted score %.2f below 1.00", score))
+	}
+	
+	if len(reasons) > 0 {
+		s.recordSuppression("alarm_unverified", device.ID,
+			fmt.Sprintf("Unverified trip of %s: %s", device.ID, strings.Join(reasons, ", ")),
+			map[string]interface{}{
+				"distinct_sensors": len(distinct),
+				"score":            score,
+				"window_seconds":   settings.WindowSeconds,
+			})
+		return false
+	}
+	
+	s.trips = nil
+	return true
+}
+
+func (s *SecurityService) recordSuppression(eventType, sensorID, message string, data map[string]interface{}) {
+	data["sensor_id"] = sensorID
+	
+	s.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID(eventType),
+		Type:      eventType,
+		Source:    "security",
+		Message:   message,
+		Data:      data,
+		Timestamp: s.clock.Now(),
+		Severity:  "warning",
+	})
+}
+
+func sensorWeight(device *models.Device, weighted bool) float64 {
+	if !weighted {
+		return 1.0
+	}
+	
+	sensitivity, ok := numberProperty(device, "sensitivity")
+	if !ok {
+		return 1.0
+	}
+	
+	return utils.LimitValue(sensitivity/10, 0.1, 1.0)
+}
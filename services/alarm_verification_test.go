@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+func TestCrossZoneVerificationNeedsSecondSensor(t *testing.T) {
+	security, store, clk, cfg := newTestSecurity(t)
+	cfg.AlarmVerification.CrossZone = true
+	cfg.AlarmVerification.SensitivityWeighting = false
+	
+	security.Arm("test", models.ArmModeAway, false)
+	clk.Step(30 * time.Second)
+	
+	security.SensorTripped("door_1")
+	assertSecurityState(t, security, models.SecurityStateArmed)
+	if got := countEvents(store, "alarm_unverified"); got != 1 {
+		t.Errorf("alarm_unverified events = %d, want 1", got)
+	}
+	
+	// A second sensor outside the window starts over.
+	clk.Step(61 * time.Second)
+	security.SensorTripped("motion_1")
+	assertSecurityState(t, security, models.SecurityStateArmed)
+	
+	clk.Step(10 * time.Second)
+	security.SensorTripped("door_1")
+	assertSecurityState(t, security, models.SecurityStatePending)
+}
+
+func TestSensitivityWeightingAddsUpTrips(t *testing.T) {
+	security, store, clk, _ := newTestSecurity(t)
+	store.UpdateDevice("motion_1", map[string]interface{}{"sensitivity": 5})
+	
+	security.Arm("test", models.ArmModeAway, false)
+	clk.Step(30 * time.Second)
+	
+	security.SensorTripped("motion_1")
+	assertSecurityState(t, security, models.SecurityStateArmed)
+	
+	clk.Step(20 * time.Second)
+	security.SensorTripped("motion_1")
+	assertSecurityState(t, security, models.SecurityStatePending)
+}
+
+func TestSwingerSensorIsShutDown(t *testing.T) {
+	security, store, clk, _ := newTestSecurity(t)
+	store.UpdateDevice("motion_1", map[string]interface{}{"sensitivity": 1})
+	
+	security.Arm("test", models.ArmModeAway, false)
+	clk.Step(30 * time.Second)
+	
+	for i := 0; i < 4; i++ {
+		security.SensorTripped("motion_1")
+		clk.Step(5 * time.Second)
+	}
+	
+	state := assertSecurityState(t, security, models.SecurityStateArmed)
+	if len(state.BypassedSensors) != 1 || state.BypassedSensors[0] != "motion_1" {
+		t.Fatalf("bypassed sensors = %v, want [motion_1]", state.BypassedSensors)
+	}
+	if got := countEvents(store, "sensor_swinger_shutdown"); got != 1 {
+		t.Errorf("sensor_swinger_shutdown events = %d, want 1", got)
+	}
+	
+	security.SensorTripped("motion_1")
+	if got := countEvents(store, "alarm_unverified"); got != 3 {
+		t.Errorf("alarm_unverified events = %d, want trips after the shutdown ignored", got)
+	}
+	
+	// Other sensors still verify on their own.
+	security.SensorTripped("door_1")
+	assertSecurityState(t, security, models.SecurityStatePending)
+}
@@ -0,0 +1,494 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+type anomalyStats struct {
+	mean float64
+	std  float64
+	n    int
+}
+
+type anomalyBaseline struct {
+	byWeek [7 * 24][]float64
+	byDay  [24][]float64
+	all    []float64
+}
+
+type powerSample struct {
+	at     time.Time
+	powerW float64
+	active bool
+}
+
+type AnomalyService struct {
+	store   *storage.MemoryStore
+	tariffs *TariffService
+	clock   clock.Clock
+	config  *config.Config
+	mu      sync.Mutex
+	windows map[string][]powerSample
+	since   map[string]time.Time
+	onSince map[string]time.Time
+	idle    map[string]map[time.Time]float64
+	open    map[string]*models.EnergyAnomaly
+}
+
+func NewAnomalyService(store *storage.MemoryStore, tariffs *TariffService, clk clock.Clock, cfg *config.Config) *AnomalyService {
+	service := &AnomalyService{
+		store:   store,
+		tariffs: tariffs,
+		clock:   clk,
+		config:  cfg,
+		windows: make(map[string][]powerSample),
+		since:   make(map[string]time.Time),
+		onSince: make(map[string]time.Time),
+		idle:    make(map[string]map[time.Time]float64),
+		open:    make(map[string]*models.EnergyAnomaly),
+	}
+	
+	go service.monitorAnomalies()
+	
+	return service
+}
+
+func (a *AnomalyService) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	a.windows = make(map[string][]powerSample)
+	a.since = make(map[string]time.Time)
+	a.onSince = make(map[string]time.Time)
+	a.idle = make(map[string]map[time.Time]float64)
+	a.open = make(map[string]*models.EnergyAnomaly)
+}
+
+func (a *AnomalyService) List(status models.AnomalyStatus, deviceID string, limit int) []models.EnergyAnomaly {
+	anomalies := make([]models.EnergyAnomaly, 0)
+	for _, anomaly := range a.store.GetAnomalies(0) {
+		if status != "" && anomaly.Status != status {
+			continue
+		}
+		if deviceID != "" && anomaly.DeviceID != deviceID {
+			continue
+		}
+		anomalies = append(anomalies, anomaly)
+		if limit > 0 && len(anomalies) >= limit {
+			break
+		}
+	}
+	return anomalies
+}
+
+func (a *AnomalyService) Acknowledge(anomalyID string) (models.EnergyAnomaly, error) {
+	return a.transition(anomalyID, models.AnomalyAcknowledged)
+}
+
+func (a *AnomalyService) Dismiss(anomalyID string) (models.EnergyAnomaly, error) {
+	return a.transition(anomalyID, models.AnomalyDismissed)
+}
+
+func (a *AnomalyService) transition(anomalyID string, status models.AnomalyStatus) (models.EnergyAnomaly, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	anomaly, err := a.store.GetAnomaly(anomalyID)
+	if err != nil {
+		return anomaly, err
+	}
+	if live, ok := a.open[anomalyKey(anomaly.DeviceID, anomaly.Kind)]; ok && live.ID == anomalyID {
+		anomaly = *live
+	}
+	
+	if !anomaly.Active() || anomaly.Status == status {
+		return anomaly, fmt.Errorf("anomaly %s is already %s", anomalyID, anomaly.Status)
+	}
+	
+	now := a.clock.Now()
+	anomaly.Status = status
+	if status == models.AnomalyAcknowledged {
+		anomaly.AcknowledgedAt = now
+	} else {
+		anomaly.DismissedAt = now
+	}
+	
+	if live, ok := a.open[anomalyKey(anomaly.DeviceID, anomaly.Kind)]; ok && live.ID == anomalyID {
+		*live = anomaly
+	}
+	a.store.UpdateAnomaly(anomaly)
+	return anomaly, nil
+}
+
+func (a *AnomalyService) monitorAnomalies() {
+	ticker := a.clock.NewTicker(time.Duration(a.config.EnergyUpdateInterval) * time.Second)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			a.checkAnomalies()
+		}
+	}
+}
+
+func (a *AnomalyService) checkAnomalies() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	cfg := a.config.Anomalies
+	if !cfg.Enabled {
+		return
+	}
+	
+	now := a.clock.Now()
+	baselines := a.baselines(now)
+	
+	for _, device := range a.store.ListDevices() {
+		if device.Status != models.DeviceStatusOnline || models.IsEnergySource(device.Type) {
+			delete(a.windows, device.ID)
+			delete(a.since, device.ID)
+			delete(a.onSince, device.ID)
+			delete(a.idle, device.ID)
+			for _, kind := range []models.AnomalyKind{models.AnomalyPowerHigh, models.AnomalyPowerLow, models.AnomalyLongRun, models.AnomalyStandby} {
+				a.resolve(device.ID, kind, now)
+			}
+			continue
+		}
+		
+		baseline := baselines[device.ID]
+		power := devicePower(device)
+		a.checkDeviation(device, baseline, power, now)
+		a.checkLongRun(device, power, now)
+		a.checkStandby(device, power, now)
+	}
+}
+
+func (a *AnomalyService) baselines(now time.Time) map[string]*anomalyBaseline {
+	hour := now.Truncate(time.Hour)
+	
+	baselines := make(map[string]*anomalyBaseline)
+	for _, rollup := range a.store.GetEnergyRollups(hour.AddDate(0, 0, -a.config.ForecastHistoryDays)) {
+		if rollup.Coverage < forecastMinCoverage {
+			continue
+		}
+		
+		baseline, ok := baselines[rollup.DeviceID]
+		if !ok {
+			baseline = &anomalyBaseline{}
+			baselines[rollup.DeviceID] = baseline
+		}
+		
+		powerW := rollup.EnergyWh / rollup.Coverage
+		baseline.byWeek[weekSlot(rollup.Hour)] = append(baseline.byWeek[weekSlot(rollup.Hour)], powerW)
+		baseline.byDay[rollup.Hour.Hour()] = append(baseline.byDay[rollup.Hour.Hour()], powerW)
+		baseline.all = append(baseline.all, powerW)
+	}
+	
+	return baselines
+}
+
+func (a *AnomalyService) checkDeviation(device *models.Device, baseline *anomalyBaseline, power float64, now time.Time) {
+	cfg := a.config.Anomalies
+	window := time.Duration(cfg.MinDurationMinutes) * time.Minute
+	
+	samples := append(a.windows[device.ID], powerSample{at: now, powerW: power, active: deviceActive(device)})
+	for len(samples) > 1 && now.Sub(samples[0].at) > window {
+		samples = samples[1:]
+	}
+	a.windows[device.ID] = samples
+	if _, ok := a.since[device.ID]; !ok {
+		a.since[device.ID] = now
+	}
+	
+	if baseline == nil || now.Sub(a.since[device.ID]) < window {
+		return
+	}
+	stats, scope := baseline.lookup(now, cfg.MinSamples)
+	if stats.n == 0 {
+		a.resolve(device.ID, models.AnomalyPowerHigh, now)
+		a.resolve(device.ID, models.AnomalyPowerLow, now)
+		return
+	}
+	
+	average, active := 0.0, true
+	for _, sample := range samples {
+		average += sample.powerW
+		active = active && sample.active
+	}
+	average /= float64(len(samples))
+	
+	spread := math.Max(stats.std, math.Max(0.1*stats.mean, 1))
+	sigma := (average - stats.mean) / spread
+	context := map[string]interface{}{
+		"average_w":       utils.RoundToDecimal(average, 1),
+		"window_minutes":  cfg.MinDurationMinutes,
+		"baseline_w":      utils.RoundToDecimal(stats.mean, 1),
+		"baseline_std_w":  utils.RoundToDecimal(stats.std, 1),
+		"baseline_scope":  scope,
+		"baseline_slot":   now.Format("Monday 15:00"),
+		"baseline_hours":  stats.n,
+		"sigma":           utils.RoundToDecimal(sigma, 2),
+		"sigma_threshold": cfg.Sigma,
+	}
+	
+	switch {
+	case a.deviates(average, sigma, stats.mean, 1, 1):
+		a.raise(device, models.AnomalyPowerHigh, "warning", fmt.Sprintf("%s averaging %.0f W over %d min, %.1fx its usual %.0f W for %s",
+			device.Name, average, cfg.MinDurationMinutes, average/math.Max(stats.mean, 1), stats.mean, now.Format("Monday 15:00")), average, stats.mean, context, now)
+	case a.deviates(average, sigma, stats.mean, 1, 0.5) && a.open[anomalyKey(device.ID, models.AnomalyPowerHigh)] != nil:
+	default:
+		a.resolve(device.ID, models.AnomalyPowerHigh, now)
+	}
+	
+	switch {
+	case active && a.deviates(average, sigma, stats.mean, -1, 1):
+		a.raise(device, models.AnomalyPowerLow, "info", fmt.Sprintf("%s is on but averaging %.0f W over %d min, well below its usual %.0f W for %s",
+			device.Name, average, cfg.MinDurationMinutes, stats.mean, now.Format("Monday 15:00")), average, stats.mean, context, now)
+	case active && a.deviates(average, sigma, stats.mean, -1, 0.5) && a.open[anomalyKey(device.ID, models.AnomalyPowerLow)] != nil:
+	default:
+		a.resolve(device.ID, models.AnomalyPowerLow, now)
+	}
+}
+
+func (a *AnomalyService) deviates(average, sigma, mean, direction, scale float64) bool {
+	cfg := a.config.Anomalies
+	ratio := 1 + (cfg.MinRatio-1)*scale
+	if direction > 0 {
+		return sigma > cfg.Sigma*scale && average >= mean*ratio
+	}
+	return sigma < -cfg.Sigma*scale && average*ratio <= mean
+}
+
+func (a *AnomalyService) checkLongRun(device *models.Device, power float64, now time.Time) {
+	limit, ok := a.config.Anomalies.LongRunHours[string(device.Type)]
+	if !ok || limit <= 0 {
+		return
+	}
+	
+	if !deviceActive(device) {
+		delete(a.onSince, device.ID)
+		a.resolve(device.ID, models.AnomalyLongRun, now)
+		return
+	}
+	
+	since, ok := a.onSince[device.ID]
+	if !ok {
+		a.onSince[device.ID] = now
+		return
+	}
+	
+	hours := now.Sub(since).Hours()
+	if hours < limit {
+		return
+	}
+	
+	a.raise(device, models.AnomalyLongRun, "warning", fmt.Sprintf("%s has been on for %.1f hours", device.Name, hours), power, 0, map[string]interface{}{
+		"on_since":    since,
+		"hours_on":    utils.RoundToDecimal(hours, 2),
+		"limit_hours": limit,
+	}, now)
+}
+
+func (a *AnomalyService) checkStandby(device *models.Device, power float64, now time.Time) {
+	cfg := a.config.Anomalies
+	tracked := false
+	for _, deviceType := range cfg.StandbyTypes {
+		if deviceType == string(device.Type) {
+			tracked = true
+		}
+	}
+	if !tracked {
+		return
+	}
+	
+	hour := now.Truncate(time.Hour)
+	floors, ok := a.idle[device.ID]
+	if !ok {
+		floors = make(map[time.Time]float64)
+		a.idle[device.ID] = floors
+	}
+	for observed := range floors {
+		if now.Sub(observed) > time.Duration(cfg.StandbyHours)*time.Hour {
+			delete(floors, observed)
+		}
+	}
+	if !deviceActive(device) {
+		if floor, ok := floors[hour]; !ok || power < floor {
+			floors[hour] = power
+		}
+	}
+	
+	if now.Sub(a.since[device.ID]) < time.Duration(cfg.StandbyHours)*time.Hour || len(floors) == 0 {
+		return
+	}
+	standby := math.Inf(1)
+	for _, floor := range floors {
+		standby = math.Min(standby, floor)
+	}
+	if standby < cfg.StandbyMinW {
+		a.resolve(device.ID, models.AnomalyStandby, now)
+		return
+	}
+	
+	price := models.EnergyUsage{EnergyWh: standby * 24 * 365, Timestamp: now}
+	a.tariffs.Price(&price)
+	
+	a.raise(device, models.AnomalyStandby, "info", fmt.Sprintf("%s draws %.0f W even when idle (about %.0f kWh a year)",
+		device.Name, standby, price.KWh()), standby, standby, map[string]interface{}{
+		"standby_w":     utils.RoundToDecimal(standby, 1),
+		"idle_hours":    len(floors),
+		"window_hours":  cfg.StandbyHours,
+		"annual_kwh":    utils.RoundToDecimal(price.KWh(), 1),
+		"annual_cost":   utils.RoundToDecimal(price.Cost, 2),
+		"currency":      price.Currency,
+		"standby_min_w": cfg.StandbyMinW,
+	}, now)
+}
+
+func (a *AnomalyService) raise(device *models.Device, kind models.AnomalyKind, severity, message string, power, baseline float64, context map[string]interface{}, now time.Time) {
+	key := anomalyKey(device.ID, kind)
+	if anomaly, ok := a.open[key]; ok {
+		if anomaly.Active() {
+			anomaly.PowerW = utils.RoundToDecimal(power, 1)
+			anomaly.Context = context
+			anomaly.LastSeenAt = now
+			a.store.UpdateAnomaly(*anomaly)
+		}
+		return
+	}
+	
+	anomaly := &models.EnergyAnomaly{
+		ID:         utils.GenerateID("anomaly"),
+		DeviceID:   device.ID,
+		DeviceName: device.Name,
+		DeviceType: device.Type,
+		Location:   device.Location,
+		Kind:       kind,
+		Status:     models.AnomalyOpen,
+		Severity:   severity,
+		Message:    message,
+		PowerW:     utils.RoundToDecimal(power, 1),
+		BaselineW:  utils.RoundToDecimal(baseline, 1),
+		Context:    context,
+		DetectedAt: now,
+		LastSeenAt: now,
+	}
+	a.open[key] = anomaly
+	a.store.AddAnomaly(*anomaly)
+	
+	data := map[string]interface{}{
+		"anomaly_id":  anomaly.ID,
+		"device_id":   device.ID,
+		"device_name": device.Name,
+		"device_type": device.Type,
+		"location":    device.Location,
+		"kind":        kind,
+		"power_w":     anomaly.PowerW,
+		"baseline_w":  anomaly.BaselineW,
+	}
+	for key, value := range context {
+		data[key] = value
+	}
+	a.record("energy_anomaly", severity, message, data, now)
+}
+
+func (a *AnomalyService) resolve(deviceID string, kind models.AnomalyKind, now time.Time) {
+	key := anomalyKey(deviceID, kind)
+	anomaly, ok := a.open[key]
+	if !ok {
+		return
+	}
+	delete(a.open, key)
+	if !anomaly.Active() {
+		return
+	}
+	
+	anomaly.Status = models.AnomalyResolved
+	anomaly.ResolvedAt = now
+	a.store.UpdateAnomaly(*anomaly)
+	
+	a.record("energy_anomaly_resolved", "info", fmt.Sprintf("%s %s anomaly cleared after %s", anomaly.DeviceName, kind, now.Sub(anomaly.DetectedAt).Round(time.Second)), map[string]interface{}{
+		"anomaly_id": anomaly.ID,
+		"device_id":  deviceID,
+		"kind":       kind,
+	}, now)
+}
+
+func (a *AnomalyService) record(eventType, severity, message string, data map[string]interface{}, now time.Time) {
+	a.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID(eventType),
+		Type:      eventType,
+		Source:    "anomalies",
+		Message:   message,
+		Data:      data,
+		Timestamp: now,
+		Severity:  severity,
+	})
+}
+
+func (b *anomalyBaseline) lookup(now time.Time, minSamples int) (anomalyStats, string) {
+	if values := b.byWeek[weekSlot(now)]; len(values) >= minSamples {
+		return statsOf(values), "hour_of_week"
+	}
+	if values := b.byDay[now.Hour()]; len(values) >= minSamples {
+		return statsOf(values), "hour_of_day"
+	}
+	if len(b.all) >= 24 && len(b.all) >= minSamples {
+		return statsOf(b.all), "all_hours"
+	}
+	return anomalyStats{}, ""
+}
+
+func statsOf(values []float64) anomalyStats {
+	stats := anomalyStats{n: len(values)}
+	for _, value := range values {
+		stats.mean += value
+	}
+	stats.mean /= float64(len(values))
+	
+	if len(values) > 1 {
+		squares := 0.0
+		for _, value := range values {
+			squares += (value - stats.mean) * (value - stats.mean)
+		}
+		stats.std = math.Sqrt(squares / float64(len(values)-1))
+	}
+	return stats
+}
+
+func deviceActive(device *models.Device) bool {
+	switch device.Type {
+	case models.DeviceTypeThermostat:
+		heating, _ := device.Properties["heating"].(bool)
+		cooling, _ := device.Properties["cooling"].(bool)
+		return heating || cooling
+		
+	case models.DeviceTypeCamera:
+		recording, _ := device.Properties["recording"].(bool)
+		return recording
+		
+	case models.DeviceTypeEVCharger:
+		return evCharging(device)
+	}
+	
+	power, _ := device.Properties["power"].(bool)
+	return power
+}
+
+func weekSlot(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+func anomalyKey(deviceID string, kind models.AnomalyKind) string {
+	return deviceID + "/" + string(kind)
+}
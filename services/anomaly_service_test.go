@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestAnomalies(t *testing.T) (*AnomalyService, *storage.MemoryStore, *clock.Simulated) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	// Keep the monitor idle; tests check anomalies explicitly.
+	cfg.EnergyUpdateInterval = 30 * 24 * 3600
+	
+	for _, device := range []*models.Device{
+		{ID: "plug_1", Name: "Freezer Plug", Type: models.DeviceTypeSmartPlug, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": true, "load_w": 100.0}},
+		{ID: "light_1", Name: "Porch Light", Type: models.DeviceTypeLight, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": true, "brightness": 40}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	// The plug usually draws about 100 W at this hour.
+	rollups := make([]models.EnergyRollup, 0)
+	for day, energyWh := range []float64{95, 100, 105} {
+		rollups = append(rollups, models.EnergyRollup{Hour: testStart.AddDate(0, 0, -day-1), DeviceID: "plug_1", EnergyWh: energyWh, Coverage: 1})
+	}
+	store.AddEnergyRollups(rollups, testStart.AddDate(0, 0, -30))
+	
+	return NewAnomalyService(store, NewTariffService(clk, cfg), clk, cfg), store, clk
+}
+
+func runAnomalyChecks(anomalies *AnomalyService, clk *clock.Simulated, every time.Duration, count int) {
+	for i := 0; i < count; i++ {
+		anomalies.checkAnomalies()
+		clk.Step(every)
+	}
+}
+
+func TestAnomalyHighPowerRaisedAndResolved(t *testing.T) {
+	anomalies, store, clk := newTestAnomalies(t)
+	store.UpdateDevice("plug_1", map[string]interface{}{"load_w": 400.0})
+	
+	// Nothing is raised before the device has been watched for a full window.
+	runAnomalyChecks(anomalies, clk, time.Minute, 15)
+	if got := len(anomalies.List("", "plug_1", 0)); got != 0 {
+		t.Fatalf("anomalies within the first window = %d, want 0", got)
+	}
+	
+	runAnomalyChecks(anomalies, clk, time.Minute, 1)
+	open := anomalies.List(models.AnomalyOpen, "plug_1", 0)
+	if len(open) != 1 || open[0].Kind != models.AnomalyPowerHigh || open[0].BaselineW != 100 {
+		t.Fatalf("open anomalies = %+v, want power_high against a 100 W baseline", open)
+	}
+	if open[0].Context["baseline_scope"] != "hour_of_week" && open[0].Context["baseline_scope"] != "hour_of_day" {
+		t.Errorf("baseline scope = %v, want an hourly baseline", open[0].Context["baseline_scope"])
+	}
+	if got := countEvents(store, "energy_anomaly"); got != 1 {
+		t.Errorf("energy_anomaly events = %d, want 1", got)
+	}
+	
+	store.UpdateDevice("plug_1", map[string]interface{}{"load_w": 100.0})
+	runAnomalyChecks(anomalies, clk, time.Minute, 16)
+	if got := anomalies.List("", "plug_1", 0); len(got) != 1 || got[0].Status != models.AnomalyResolved {
+		t.Fatalf("anomalies = %+v, want the power_high anomaly resolved", got)
+	}
+	if got := countEvents(store, "energy_anomaly"); got != 1 {
+		t.Errorf("energy_anomaly events = %d, want no new anomaly", got)
+	}
+}
+
+func TestAnomalyLongRunResolvesWhenOffline(t *testing.T) {
+	anomalies, store, clk := newTestAnomalies(t)
+	
+	runAnomalyChecks(anomalies, clk, 30*time.Minute, 24)
+	if got := anomalies.List(models.AnomalyOpen, "light_1", 0); len(got) != 0 {
+		t.Fatalf("open anomalies before 12 hours = %+v, want none", got)
+	}
+	
+	runAnomalyChecks(anomalies, clk, 30*time.Minute, 1)
+	open := anomalies.List(models.AnomalyOpen, "light_1", 0)
+	if len(open) != 1 || open[0].Kind != models.AnomalyLongRun {
+		t.Fatalf("open anomalies = %+v, want long_run", open)
+	}
+	
+	store.UpdateDevice("light_1", map[string]interface{}{"status": string(models.DeviceStatusOffline)})
+	anomalies.checkAnomalies()
+	if got := anomalies.List(models.AnomalyOpen, "light_1", 0); len(got) != 0 {
+		t.Errorf("open anomalies after going offline = %+v, want none", got)
+	}
+	if got := countEvents(store, "energy_anomaly_resolved"); got != 1 {
+		t.Errorf("energy_anomaly_resolved events = %d, want 1", got)
+	}
+}
+
+func TestAnomalyAcknowledgeAndDismiss(t *testing.T) {
+	anomalies, _, clk := newTestAnomalies(t)
+	runAnomalyChecks(anomalies, clk, 30*time.Minute, 25)
+	anomaly := anomalies.List(models.AnomalyOpen, "light_1", 0)[0]
+	
+	acknowledged, err := anomalies.Acknowledge(anomaly.ID)
+	if err != nil || acknowledged.Status != models.AnomalyAcknowledged || acknowledged.AcknowledgedAt.IsZero() {
+		t.Fatalf("acknowledge = %+v, %v", acknowledged, err)
+	}
+	if _, err := anomalies.Acknowledge(anomaly.ID); err == nil {
+		t.Error("acknowledging twice succeeded")
+	}
+	
+	// An acknowledged anomaly stays active and keeps updating.
+	runAnomalyChecks(anomalies, clk, 30*time.Minute, 1)
+	if got := anomalies.List(models.AnomalyAcknowledged, "light_1", 0); len(got) != 1 || !got[0].LastSeenAt.After(acknowledged.LastSeenAt) {
+		t.Errorf("acknowledged anomalies = %+v, want one still being updated", got)
+	}
+	
+	if dismissed, err := anomalies.Dismiss(anomaly.ID); err != nil || dismissed.Status != models.AnomalyDismissed {
+		t.Errorf("dismiss = %+v, %v", dismissed, err)
+	}
+	if _, err := anomalies.Dismiss("anomaly_missing"); err == nil {
+		t.Error("dismissing a missing anomaly succeeded")
+	}
+}
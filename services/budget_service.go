@@ -0,0 +1,304 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const budgetMinObservation = 30 * time.Minute
+
+type budgetState struct {
+	periodStart  time.Time
+	trackedSince time.Time
+	usedKWh      float64
+	usedCost     float64
+	level        float64
+	alertedAt    time.Time
+}
+
+type BudgetService struct {
+	store     *storage.MemoryStore
+	notifier  *NotificationService
+	forecasts *ForecastService
+	clock     clock.Clock
+	config    *config.Config
+	mu        sync.Mutex
+	budgets   []models.EnergyBudget
+	states    map[string]*budgetState
+}
+
+func NewBudgetService(store *storage.MemoryStore, notifier *NotificationService, forecasts *ForecastService, clk clock.Clock, cfg *config.Config) *BudgetService {
+	service := &BudgetService{
+		store:     store,
+		notifier:  notifier,
+		forecasts: forecasts,
+		clock:     clk,
+		config:    cfg,
+		states:    make(map[string]*budgetState),
+	}
+	
+	for i, budget := range cfg.Budgets {
+		if budget.ID == "" {
+			budget.ID = fmt.Sprintf("budget_%d", i+1)
+		}
+		service.budgets = append(service.budgets, budget)
+	}
+	
+	go service.monitorBudgets()
+	
+	return service
+}
+
+func (b *BudgetService) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	b.states = make(map[string]*budgetState)
+}
+
+func (b *BudgetService) Add(budget models.EnergyBudget) (models.EnergyBudget, error) {
+	if err := budget.Validate(); err != nil {
+		return budget, err
+	}
+	
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if budget.ID == "" {
+		budget.ID = utils.GenerateID("budget")
+	}
+	for _, existing := range b.budgets {
+		if existing.ID == budget.ID {
+			return budget, fmt.Errorf("budget %s already exists", budget.ID)
+		}
+	}
+	if budget.Name == "" {
+		budget.Name = budget.ID
+	}
+	
+	b.budgets = append(b.budgets, budget)
+	return budget, nil
+}
+
+func (b *BudgetService) Remove(budgetID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	for i, budget := range b.budgets {
+		if budget.ID == budgetID {
+			b.budgets = append(b.budgets[:i], b.budgets[i+1:]...)
+			delete(b.states, budgetID)
+			return nil
+		}
+	}
+	return fmt.Errorf("budget %s not found", budgetID)
+}
+
+func (b *BudgetService) Statuses() []models.BudgetStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	now := b.clock.Now()
+	statuses := make([]models.BudgetStatus, 0, len(b.budgets))
+	for _, budget := range b.budgets {
+		statuses = append(statuses, b.status(budget, now))
+	}
+	return statuses
+}
+
+func (b *BudgetService) Record(usage models.EnergyUsage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	for _, budget := range b.budgets {
+		if !budget.Matches(usage) {
+			continue
+		}
+		state := b.state(budget, usage.Timestamp)
+		state.usedKWh += usage.KWh()
+		state.usedCost += usage.Cost
+	}
+}
+
+func (b *BudgetService) monitorBudgets() {
+	ticker := b.clock.NewTicker(time.Duration(b.config.EnergyUpdateInterval) * time.Second)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			b.checkBudgets()
+		}
+	}
+}
+
+func (b *BudgetService) checkBudgets() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	now := b.clock.Now()
+	cooldown := time.Duration(b.config.BudgetAlertCooldown) * time.Second
+	
+	for _, budget := range b.budgets {
+		status := b.status(budget, now)
+		state := b.states[budget.ID]
+		
+		level := 0.0
+		for _, threshold := range b.config.BudgetAlertLevels {
+			if status.ProjectedPercent >= threshold && threshold > level {
+				level = threshold
+			}
+		}
+		
+		if level > state.level {
+			state.level = level
+			state.alertedAt = now
+			status.AlertLevel = level
+			b.alert(status, level)
+		} else if level < state.level && now.Sub(state.alertedAt) >= cooldown {
+			state.level = level
+		}
+	}
+}
+
+func (b *BudgetService) state(budget models.EnergyBudget, now time.Time) *budgetState {
+	start, _ := budget.PeriodAt(now)
+	
+	state, ok := b.states[budget.ID]
+	if !ok {
+		state = &budgetState{periodStart: start, trackedSince: now}
+		b.states[budget.ID] = state
+	} else if !state.periodStart.Equal(start) {
+		*state = budgetState{periodStart: start, trackedSince: start}
+	}
+	return state
+}
+
+func (b *BudgetService) status(budget models.EnergyBudget, now time.Time) models.BudgetStatus {
+	state := b.state(budget, now)
+	start, end := budget.PeriodAt(now)
+	
+	status := models.BudgetStatus{
+		Budget:       budget,
+		PeriodStart:  start,
+		PeriodEnd:    end,
+		TrackedSince: state.trackedSince,
+		Unit:         "kWh",
+		Used:         state.usedKWh,
+		AlertLevel:   state.level,
+	}
+	if budget.Metric == models.BudgetCost {
+		status.Unit = b.config.Currency
+		status.Used = state.usedCost
+	}
+	
+	status.Projected = status.Used
+	status.Projection = "none"
+	if observed := now.Sub(state.trackedSince); observed >= budgetMinObservation {
+		if remaining, ok := b.forecastRemaining(budget, now, end); ok {
+			status.Projected += remaining
+			status.Projection = "forecast"
+		} else {
+			status.Projected += status.Used / observed.Hours() * end.Sub(now).Hours()
+			status.Projection = "run_rate"
+		}
+	}
+	
+	status.Remaining = utils.RoundToDecimal(budget.Limit-status.Used, 4)
+	status.UsedPercent = utils.RoundToDecimal(status.Used/budget.Limit*100, 1)
+	status.ProjectedPercent = utils.RoundToDecimal(status.Projected/budget.Limit*100, 1)
+	status.Used = utils.RoundToDecimal(status.Used, 4)
+	status.Projected = utils.RoundToDecimal(status.Projected, 4)
+	
+	switch {
+	case status.UsedPercent >= 100:
+		status.State = "over_budget"
+	case status.ProjectedPercent >= 100:
+		status.State = "at_risk"
+	default:
+		status.State = "ok"
+	}
+	return status
+}
+
+func (b *BudgetService) forecastRemaining(budget models.EnergyBudget, now, end time.Time) (float64, bool) {
+	if b.forecasts == nil {
+		return 0, false
+	}
+	forecast := b.forecasts.Forecast()
+	if forecast == nil || forecast.HistoryHours == 0 {
+		return 0, false
+	}
+	
+	remaining, day := 0.0, 0.0
+	for _, device := range forecast.Devices {
+		for _, point := range device.Hourly {
+			usage := device.Usage(point)
+			if !budget.Matches(usage) {
+				continue
+			}
+			
+			value := usage.KWh()
+			if budget.Metric == models.BudgetCost {
+				value = usage.Cost
+			}
+			day += value
+			
+			start, stop := point.Hour, point.Hour.Add(time.Hour)
+			if start.Before(now) {
+				start = now
+			}
+			if stop.After(end) {
+				stop = end
+			}
+			if stop.After(start) {
+				remaining += value * stop.Sub(start).Hours()
+			}
+		}
+	}
+	
+	if end.After(forecast.To) {
+		remaining += day / 24 * end.Sub(forecast.To).Hours()
+	}
+	return remaining, true
+}
+
+func (b *BudgetService) alert(status models.BudgetStatus, level float64) {
+	severity := "info"
+	if level >= 100 {
+		severity = "critical"
+	} else if level >= 80 {
+		severity = "warning"
+	}
+	
+	message := fmt.Sprintf("%s budget %s projected at %.0f%% (%.2f of %.2f %s used)",
+		status.Budget.Period, status.Budget.Name, status.ProjectedPercent, status.Used, status.Budget.Limit, status.Unit)
+	data := map[string]interface{}{
+		"budget_id":         status.Budget.ID,
+		"level":             level,
+		"used":              status.Used,
+		"projected":         status.Projected,
+		"limit":             status.Budget.Limit,
+		"unit":              status.Unit,
+		"projected_percent": status.ProjectedPercent,
+		"period_end":        status.PeriodEnd,
+	}
+	
+	b.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("energy_budget_alert"),
+		Type:      "energy_budget_alert",
+		Source:    "budgets",
+		Message:   message,
+		Data:      data,
+		Timestamp: b.clock.Now(),
+		Severity:  severity,
+	})
+	b.notifier.Notify("push", severity, message, data)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestBudgets(t *testing.T, budgets ...models.EnergyBudget) (*BudgetService, *storage.MemoryStore, *clock.Simulated) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.Currency = "EUR"
+	cfg.Budgets = budgets
+	cfg.BudgetAlertLevels = []float64{50, 80, 100}
+	// Keep the monitor idle; tests check budgets explicitly.
+	cfg.EnergyUpdateInterval = 30 * 24 * 3600
+	return NewBudgetService(store, NewNotificationService(store, clk), nil, clk, cfg), store, clk
+}
+
+func budgetUsage(deviceID, location string, at time.Time, kWh float64) models.EnergyUsage {
+	return models.EnergyUsage{DeviceID: deviceID, Location: location, EnergyWh: kWh * 1000, Cost: kWh * 0.2, Timestamp: at}
+}
+
+func budgetAlerts(store *storage.MemoryStore) []models.SystemEvent {
+	var alerts []models.SystemEvent
+	for _, event := range store.GetSystemEvents(0) {
+		if event.Type == "energy_budget_alert" {
+			alerts = append(alerts, event)
+		}
+	}
+	return alerts
+}
+
+func TestBudgetAlertsOncePerThreshold(t *testing.T) {
+	budgets, store, clk := newTestBudgets(t, models.EnergyBudget{Name: "Daily", Scope: models.BudgetGlobal, Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 10})
+	
+	steps := []struct {
+		kWh      float64
+		alerts   int
+		severity string
+	}{
+		{4, 0, ""},
+		{2, 1, "info"},
+		{0.5, 1, ""},
+		{2, 2, "warning"},
+		{2, 3, "critical"},
+		{1, 3, ""},
+	}
+	for i, step := range steps {
+		budgets.Record(budgetUsage("heater", "", clk.Now(), step.kWh))
+		budgets.checkBudgets()
+		
+		alerts := budgetAlerts(store)
+		if len(alerts) != step.alerts {
+			t.Fatalf("step %d: alerts = %d, want %d", i+1, len(alerts), step.alerts)
+		}
+		if step.severity != "" && alerts[len(alerts)-1].Severity != step.severity {
+			t.Errorf("step %d: alert severity = %s, want %s", i+1, alerts[len(alerts)-1].Severity, step.severity)
+		}
+	}
+	
+	status := budgets.Statuses()[0]
+	if status.State != "over_budget" || status.Used != 11.5 || status.AlertLevel != 100 {
+		t.Errorf("status = %s with %v used at level %v, want over_budget with 11.5 used at level 100", status.State, status.Used, status.AlertLevel)
+	}
+}
+
+func TestBudgetProjectsFromRunRate(t *testing.T) {
+	budgets, store, clk := newTestBudgets(t, models.EnergyBudget{Name: "Daily", Scope: models.BudgetGlobal, Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 10})
+	
+	budgets.Record(budgetUsage("heater", "", clk.Now(), 1))
+	if status := budgets.Statuses()[0]; status.Projection != "none" || status.Projected != 1 {
+		t.Errorf("projection before the minimum observation = %s %v, want none 1", status.Projection, status.Projected)
+	}
+	
+	// One kWh in the first hour projects to 12 kWh by midnight.
+	clk.Step(time.Hour)
+	status := budgets.Statuses()[0]
+	if status.Projection != "run_rate" || status.Projected != 12 || status.State != "at_risk" {
+		t.Errorf("status = %s %v %s, want run_rate 12 at_risk", status.Projection, status.Projected, status.State)
+	}
+	
+	budgets.checkBudgets()
+	if alerts := budgetAlerts(store); len(alerts) != 1 || alerts[0].Data["level"] != 100.0 {
+		t.Errorf("alerts = %v, want one alert at the 100%% level", alerts)
+	}
+}
+
+func TestBudgetStartsOverEachPeriod(t *testing.T) {
+	budgets, store, clk := newTestBudgets(t, models.EnergyBudget{Name: "Daily", Scope: models.BudgetGlobal, Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 10})
+	
+	budgets.Record(budgetUsage("heater", "", clk.Now(), 6))
+	budgets.checkBudgets()
+	
+	clk.Step(12 * time.Hour)
+	status := budgets.Statuses()[0]
+	if status.Used != 0 || status.AlertLevel != 0 || !status.PeriodStart.Equal(testStart.Add(12*time.Hour)) {
+		t.Errorf("next day status = %v used at level %v from %s, want a fresh period", status.Used, status.AlertLevel, status.PeriodStart)
+	}
+	
+	budgets.Record(budgetUsage("heater", "", clk.Now(), 6))
+	budgets.checkBudgets()
+	if alerts := budgetAlerts(store); len(alerts) != 2 {
+		t.Errorf("alerts = %d, want the 50%% alert again in the new period", len(alerts))
+	}
+}
+
+func TestBudgetScopesAndMetrics(t *testing.T) {
+	budgets, _, clk := newTestBudgets(t,
+		models.EnergyBudget{ID: "heater", Scope: models.BudgetDevice, Target: "heater", Period: models.BudgetWeekly, Metric: models.BudgetEnergy, Limit: 10},
+		models.EnergyBudget{ID: "kitchen", Scope: models.BudgetRoom, Target: "Kitchen", Period: models.BudgetMonthly, Metric: models.BudgetCost, Limit: 5},
+	)
+	
+	budgets.Record(budgetUsage("heater", "Living Room", clk.Now(), 2))
+	budgets.Record(budgetUsage("kettle", "Kitchen", clk.Now(), 3))
+	
+	statuses := budgets.Statuses()
+	if statuses[0].Used != 2 || statuses[0].Unit != "kWh" {
+		t.Errorf("heater budget = %v %s, want 2 kWh", statuses[0].Used, statuses[0].Unit)
+	}
+	if statuses[1].Used != 0.6 || statuses[1].Unit != "EUR" {
+		t.Errorf("kitchen budget = %v %s, want 0.6 EUR", statuses[1].Used, statuses[1].Unit)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !statuses[1].PeriodStart.Equal(want) {
+		t.Errorf("monthly period starts %s, want %s", statuses[1].PeriodStart, want)
+	}
+	if want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC); !statuses[0].PeriodStart.Equal(want) {
+		t.Errorf("weekly period starts %s, want Monday %s", statuses[0].PeriodStart, want)
+	}
+}
+
+func TestBudgetAddAndRemove(t *testing.T) {
+	budgets, _, _ := newTestBudgets(t)
+	
+	invalid := []models.EnergyBudget{
+		{Scope: models.BudgetGlobal, Period: "year", Metric: models.BudgetEnergy, Limit: 1},
+		{Scope: models.BudgetGlobal, Period: models.BudgetDaily, Metric: "water", Limit: 1},
+		{Scope: models.BudgetDevice, Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 1},
+		{Scope: models.BudgetGlobal, Target: "heater", Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 1},
+		{Scope: models.BudgetGlobal, Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 0},
+	}
+	for _, budget := range invalid {
+		if _, err := budgets.Add(budget); err == nil {
+			t.Errorf("add %+v succeeded", budget)
+		}
+	}
+	
+	added, err := budgets.Add(models.EnergyBudget{ID: "daily", Scope: models.BudgetGlobal, Period: models.BudgetDaily, Metric: models.BudgetEnergy, Limit: 5})
+	if err != nil || added.Name != "daily" {
+		t.Fatalf("add = %+v, %v, want a budget named after its ID", added, err)
+	}
+	if _, err := budgets.Add(added); err == nil {
+		t.Error("add with a duplicate ID succeeded")
+	}
+	
+	if err := budgets.Remove("daily"); err != nil {
+		t.Errorf("remove: %v", err)
+	}
+	if err := budgets.Remove("daily"); err == nil {
+		t.Error("remove of a missing budget succeeded")
+	}
+	if got := len(budgets.Statuses()); got != 0 {
+		t.Errorf("budgets = %d after removal, want 0", got)
+	}
+}
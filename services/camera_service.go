@@ -0,0 +1,394 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const (
+	cameraTickInterval    = 5 * time.Second
+	cameraRetentionPeriod = 10 * time.Minute
+	motionStartChance     = 0.05
+	thumbnailWidth        = 160
+	thumbnailHeight       = 90
+)
+
+var clipBytesPerSecond = map[string]int64{
+	"480p":  125000,
+	"720p":  250000,
+	"1080p": 500000,
+	"4k":    2000000,
+}
+
+type activeMotion struct {
+	eventID string
+	endsAt  time.Time
+}
+
+type CameraService struct {
+	store  *storage.MemoryStore
+	clock  clock.Clock
+	random *random.Source
+	config *config.Config
+	mu     sync.Mutex
+	active map[string]activeMotion
+}
+
+func NewCameraService(store *storage.MemoryStore, clk clock.Clock, src *random.Source, cfg *config.Config) *CameraService {
+	service := &CameraService{
+		store:  store,
+		clock:  clk,
+		random: src,
+		config: cfg,
+		active: make(map[string]activeMotion),
+	}
+	
+	go service.simulateMotion()
+	go service.enforceRetentionPeriodically()
+	
+	return service
+}
+
+func (c *CameraService) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.active = make(map[string]activeMotion)
+	for _, event := range c.store.GetCameraEvents("", 0) {
+		c.deleteEvent(event)
+	}
+}
+
+func (c *CameraService) GetCamera(id string) (*models.Device, error) {
+	device, err := c.store.GetDevice(id)
+	if err != nil {
+		return nil, err
+	}
+	if device.Type != models.DeviceTypeCamera {
+		return nil, fmt.Errorf("device %s is not a camera", id)
+	}
+	return device, nil
+}
+
+func (c *CameraService) GetEvents(cameraID string, limit int) []models.CameraEvent {
+	return c.store.GetCameraEvents(cameraID, limit)
+}
+
+func (c *CameraService) GetEvent(cameraID, eventID string) (models.CameraEvent, error) {
+	return c.store.GetCameraEvent(cameraID, eventID)
+}
+
+func (c *CameraService) StartMotion(cameraID, trigger string) (models.CameraEvent, error) {
+	camera, err := c.GetCamera(cameraID)
+	if err != nil {
+		return models.CameraEvent{}, err
+	}
+	
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	if motion, ok := c.active[cameraID]; ok {
+		return c.store.GetCameraEvent(cameraID, motion.eventID)
+	}
+	
+	rng := c.cameraRand(cameraID)
+	now := c.clock.Now()
+	duration := time.Duration(5+rng.Intn(56)) * time.Second
+	
+	event := models.CameraEvent{
+		ID:         utils.GenerateID("cam_event"),
+		CameraID:   camera.ID,
+		Trigger:    trigger,
+		StartedAt:  now,
+		InProgress: true,
+	}
+	
+	c.store.AddCameraEvent(event)
+	c.active[cameraID] = activeMotion{eventID: event.ID, endsAt: now.Add(duration)}
+	c.store.UpdateDevice(cameraID, map[string]interface{}{
+		"motion_active": true,
+	})
+	
+	c.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("camera_motion_started"),
+		Type:      "camera_motion_started",
+		Source:    "cameras",
+		Message:   fmt.Sprintf("Motion started on %s", camera.Name),
+		Data: map[string]interface{}{
+			"camera_id": cameraID,
+			"event_id":  event.ID,
+			"trigger":   trigger,
+		},
+		Timestamp: now,
+		Severity:  "info",
+	})
+	
+	return event, nil
+}
+
+func (c *CameraService) simulateMotion() {
+	ticker := c.clock.NewTicker(cameraTickInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			c.updateCameras()
+		}
+	}
+}
+
+func (c *CameraService) updateCameras() {
+	now := c.clock.Now()
+	
+	for _, device := range c.store.ListDevices() {
+		if device.Type != models.DeviceTypeCamera {
+			continue
+		}
+		
+		c.mu.Lock()
+		motion, active := c.active[device.ID]
+		c.mu.Unlock()
+		
+		watching := device.Status == models.DeviceStatusOnline
+		if detect, ok := device.Properties["motion_detect"].(bool); ok && !detect {
+			watching = false
+		}
+		
+		if active {
+			if !now.Before(motion.endsAt) || !watching {
+				c.finishMotion(device)
+			}
+			continue
+		}
+		
+		if watching && c.cameraRand(device.ID).Float64() < motionStartChance {
+			c.StartMotion(device.ID, "motion")
+		}
+	}
+}
+
+func (c *CameraService) finishMotion(camera *models.Device) {
+	c.mu.Lock()
+	motion, ok := c.active[camera.ID]
+	delete(c.active, camera.ID)
+	c.mu.Unlock()
+	
+	if !ok {
+		return
+	}
+	
+	event, err := c.store.GetCameraEvent(camera.ID, motion.eventID)
+	if err != nil {
+		return
+	}
+	
+	now := c.clock.Now()
+	event.EndedAt = now
+	event.InProgress = false
+	
+	resolution, _ := camera.Properties["resolution"].(string)
+	rate, ok := clipBytesPerSecond[resolution]
+	if !ok {
+		resolution = "1080p"
+		rate = clipBytesPerSecond[resolution]
+	}
+	
+	duration := event.EndedAt.Sub(event.StartedAt).Seconds()
+	event.Clip = &models.ClipMetadata{
+		Resolution:      resolution,
+		Codec:           "h264",
+		DurationSeconds: utils.RoundToDecimal(duration, 1),
+		SizeBytes:       int64(duration * float64(rate)),
+	}
+	
+	night, _ := camera.Properties["night_vision"].(bool)
+	night = night && utils.GetTimeOfDay(event.StartedAt) == "night"
+	
+	path := filepath.Join(c.config.CameraStorageDir, camera.ID, event.ID+".png")
+	size, err := c.writeThumbnail(path, c.cameraRand(camera.ID), night)
+	if err != nil {
+		c.store.AddSystemEvent(models.SystemEvent{
+			ID:        utils.GenerateID("camera_thumbnail_failed"),
+			Type:      "camera_thumbnail_failed",
+			Source:    "cameras",
+			Message:   fmt.Sprintf("Failed to write thumbnail for %s: %v", camera.Name, err),
+			Data: map[string]interface{}{
+				"camera_id": camera.ID,
+				"event_id":  event.ID,
+			},
+			Timestamp: now,
+			Severity:  "warning",
+		})
+	} else {
+		event.ThumbnailPath = path
+		event.ThumbnailBytes = size
+		event.ThumbnailURL = fmt.Sprintf("/cameras/%s/events/%s/thumbnail", camera.ID, event.ID)
+	}
+	
+	c.store.UpdateCameraEvent(event)
+	c.store.UpdateDevice(camera.ID, map[string]interface{}{
+		"motion_active": false,
+	})
+	
+	c.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("camera_motion_ended"),
+		Type:      "camera_motion_ended",
+		Source:    "cameras",
+		Message:   fmt.Sprintf("Motion ended on %s after %.0fs", camera.Name, duration),
+		Data: map[string]interface{}{
+			"camera_id":  camera.ID,
+			"event_id":   event.ID,
+			"size_bytes": event.StorageBytes(),
+		},
+		Timestamp: now,
+		Severity:  "info",
+	})
+	
+	c.EnforceRetention()
+}
+
+func (c *CameraService) enforceRetentionPeriodically() {
+	ticker := c.clock.NewTicker(cameraRetentionPeriod)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			c.EnforceRetention()
+		}
+	}
+}
+
+func (c *CameraService) EnforceRetention() {
+	events := c.store.GetCameraEvents("", 0)
+	cutoff := c.clock.Now().Add(-time.Duration(c.config.CameraRetentionHours) * time.Hour)
+	quota := int64(c.config.CameraQuotaMB) * 1024 * 1024
+	
+	expired := 0
+	overQuota := 0
+	var total int64
+	for _, event := range events {
+		if event.InProgress {
+			continue
+		}
+		
+		if event.EndedAt.Before(cutoff) {
+			c.deleteEvent(event)
+			expired++
+			continue
+		}
+		
+		total += event.StorageBytes()
+		if total > quota {
+			c.deleteEvent(event)
+			total -= event.StorageBytes()
+			overQuota++
+		}
+	}
+	
+	if expired == 0 && overQuota == 0 {
+		return
+	}
+	
+	c.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("camera_retention"),
+		Type:      "camera_retention",
+		Source:    "cameras",
+		Message:   fmt.Sprintf("Removed %d expired and %d over-quota camera events", expired, overQuota),
+		Data: map[string]interface{}{
+			"expired":        expired,
+			"over_quota":     overQuota,
+			"retained_bytes": total,
+		},
+		Timestamp: c.clock.Now(),
+		Severity:  "info",
+	})
+}
+
+func (c *CameraService) deleteEvent(event models.CameraEvent) {
+	if event.ThumbnailPath != "" {
+		if err := os.Remove(event.ThumbnailPath); err != nil && !os.IsNotExist(err) {
+			return
+		}
+	}
+	c.store.DeleteCameraEvent(event.ID)
+}
+
+func (c *CameraService) cameraRand(id string) *random.Stream {
+	return c.random.Stream("camera/" + id)
+}
+
+func (c *CameraService) writeThumbnail(path string, rng *random.Stream, night bool) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	
+	if err := png.Encode(file, renderThumbnail(rng, night)); err != nil {
+		return 0, err
+	}
+	
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func renderThumbnail(rng *random.Stream, night bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, thumbnailWidth, thumbnailHeight))
+	base := 90 + rng.Intn(80)
+	
+	for y := 0; y < thumbnailHeight; y++ {
+		for x := 0; x < thumbnailWidth; x++ {
+			shade := base + y*60/thumbnailHeight + rng.Intn(12)
+			if shade > 255 {
+				shade = 255
+			}
+			
+			if night {
+				img.Set(x, y, color.RGBA{R: uint8(shade / 5), G: uint8(shade / 2), B: uint8(shade / 5), A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{R: uint8(shade), G: uint8(shade), B: uint8(shade * 9 / 10), A: 255})
+			}
+		}
+	}
+	
+	boxWidth := 20 + rng.Intn(40)
+	boxHeight := 20 + rng.Intn(40)
+	left := rng.Intn(thumbnailWidth - boxWidth)
+	top := rng.Intn(thumbnailHeight - boxHeight)
+	highlight := color.RGBA{R: 230, G: 40, B: 40, A: 255}
+	
+	for x := left; x < left+boxWidth; x++ {
+		for _, y := range []int{top, top + 1, top + boxHeight - 2, top + boxHeight - 1} {
+			img.Set(x, y, highlight)
+		}
+	}
+	for y := top; y < top+boxHeight; y++ {
+		for _, x := range []int{left, left + 1, left + boxWidth - 2, left + boxWidth - 1} {
+			img.Set(x, y, highlight)
+		}
+	}
+	
+	return img
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestCameras(t *testing.T) (*CameraService, *storage.MemoryStore, *clock.Simulated) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.CameraStorageDir = t.TempDir()
+	
+	camera := &models.Device{ID: "camera_1", Name: "Driveway Camera", Type: models.DeviceTypeCamera, Status: models.DeviceStatusOnline,
+		Properties: map[string]interface{}{"recording": true, "motion_detect": true, "resolution": "720p"}}
+	if err := store.AddDevice(camera); err != nil {
+		t.Fatalf("add device: %v", err)
+	}
+	
+	return NewCameraService(store, clk, random.NewSource(1), cfg), store, clk
+}
+
+func recordMotion(t *testing.T, cameras *CameraService, store *storage.MemoryStore, clk *clock.Simulated) models.CameraEvent {
+	t.Helper()
+	started, err := cameras.StartMotion("camera_1", "manual")
+	if err != nil {
+		t.Fatalf("start motion: %v", err)
+	}
+	
+	// Shorter than the motion tick, so only the test ends the event.
+	clk.Step(4 * time.Second)
+	camera, _ := store.GetDevice("camera_1")
+	cameras.finishMotion(camera)
+	
+	event, err := cameras.GetEvent("camera_1", started.ID)
+	if err != nil {
+		t.Fatalf("get event: %v", err)
+	}
+	return event
+}
+
+func TestMotionEventProducesClipAndThumbnail(t *testing.T) {
+	cameras, store, clk := newTestCameras(t)
+	
+	event := recordMotion(t, cameras, store, clk)
+	if event.InProgress || event.EndedAt.Sub(event.StartedAt) != 4*time.Second {
+		t.Errorf("event ran from %s to %s in progress %v, want a finished 4s event", event.StartedAt, event.EndedAt, event.InProgress)
+	}
+	if event.Clip == nil || event.Clip.Resolution != "720p" || event.Clip.SizeBytes != 4*250000 {
+		t.Errorf("clip = %+v, want 4s of 720p", event.Clip)
+	}
+	
+	info, err := os.Stat(event.ThumbnailPath)
+	if err != nil || info.Size() != event.ThumbnailBytes {
+		t.Fatalf("thumbnail %s: %v, want %d bytes on disk", event.ThumbnailPath, err, event.ThumbnailBytes)
+	}
+	if event.ThumbnailURL != "/cameras/camera_1/events/"+event.ID+"/thumbnail" {
+		t.Errorf("thumbnail URL = %s", event.ThumbnailURL)
+	}
+	assertProperty(t, store, "camera_1", "motion_active", false)
+}
+
+func TestRetentionAndResetDeleteThumbnails(t *testing.T) {
+	cameras, store, clk := newTestCameras(t)
+	
+	kept := recordMotion(t, cameras, store, clk)
+	
+	expired := kept
+	expired.ID = "cam_event_old"
+	expired.StartedAt = testStart.Add(-73 * time.Hour)
+	expired.EndedAt = expired.StartedAt.Add(4 * time.Second)
+	expired.ThumbnailPath = filepath.Join(filepath.Dir(kept.ThumbnailPath), expired.ID+".png")
+	if err := os.WriteFile(expired.ThumbnailPath, []byte("png"), 0644); err != nil {
+		t.Fatalf("write thumbnail: %v", err)
+	}
+	store.AddCameraEvent(expired)
+	
+	cameras.EnforceRetention()
+	if _, err := os.Stat(expired.ThumbnailPath); !os.IsNotExist(err) {
+		t.Errorf("expired thumbnail still on disk: %v", err)
+	}
+	if events := cameras.GetEvents("camera_1", 0); len(events) != 1 || events[0].ID != kept.ID {
+		t.Fatalf("events = %+v, want only the recent event", events)
+	}
+	
+	cameras.Reset()
+	if events := cameras.GetEvents("", 0); len(events) != 0 {
+		t.Errorf("events after reset = %d, want 0", len(events))
+	}
+	if files, _ := filepath.Glob(filepath.Join(filepath.Dir(kept.ThumbnailPath), "*.png")); len(files) != 0 {
+		t.Errorf("thumbnails after reset = %v, want none", files)
+	}
+}
+
+func TestAddDeviceRejectsPathLikeIDs(t *testing.T) {
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	devices, _ := newTestDevices(t, cfg)
+	
+	for _, id := range []string{"../camera", "camera/1", `camera\1`} {
+		if err := devices.AddDevice(&models.Device{ID: id, Type: models.DeviceTypeCamera}); err == nil {
+			t.Errorf("add device %q succeeded", id)
+		}
+	}
+}
@@ -2,20 +2,59 @@ package services
 
 import (
 	"fmt"
-	"math/rand"
+	"strings"
+	"sync"
 	"time"
 
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
 	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
 	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
 )
 
 type DeviceService struct {
-	store *storage.MemoryStore
+	store      *storage.MemoryStore
+	security   *SecurityService
+	locks      *LockService
+	safety     *LifeSafetyService
+	tariffs    *TariffService
+	budgets    *BudgetService
+	clock      clock.Clock
+	random     *random.Source
+	config     *config.Config
+	batteryMu  sync.Mutex
+	drain      map[string]float64
+	lowBattery map[string]bool
+	energyMu   sync.Mutex
+	lastPower  map[string]models.EnergyUsage
 }
 
-func NewDeviceService(store *storage.MemoryStore) *DeviceService {
+const sensorLowBattery = 20
+
+var batteryDrainPerHour = map[models.DeviceType]float64{
+	models.DeviceTypeSensor:        0.01,
+	models.DeviceTypeContactSensor: 0.005,
+	models.DeviceTypeSmokeSensor:   0.002,
+	models.DeviceTypeCOSensor:      0.002,
+	models.DeviceTypeLeakSensor:    0.004,
+}
+
+func NewDeviceService(store *storage.MemoryStore, security *SecurityService, locks *LockService, safety *LifeSafetyService, tariffs *TariffService, budgets *BudgetService, clk clock.Clock, src *random.Source, cfg *config.Config) *DeviceService {
 	service := &DeviceService{
-		store: store,
+		store:      store,
+		security:   security,
+		locks:      locks,
+		safety:     safety,
+		tariffs:    tariffs,
+		budgets:    budgets,
+		clock:      clk,
+		random:     src,
+		config:     cfg,
+		drain:      make(map[string]float64),
+		lowBattery: make(map[string]bool),
+		lastPower:  make(map[string]models.EnergyUsage),
 	}
 	
 	service.initializeDefaultDevices()
@@ -24,6 +63,19 @@ func NewDeviceService(store *storage.MemoryStore) *DeviceService {
 	return service
 }
 
+func (d *DeviceService) Reset() {
+	d.batteryMu.Lock()
+	d.drain = make(map[string]float64)
+	d.lowBattery = make(map[string]bool)
+	d.batteryMu.Unlock()
+	
+	d.energyMu.Lock()
+	d.lastPower = make(map[string]models.EnergyUsage)
+	d.energyMu.Unlock()
+	
+	d.initializeDefaultDevices()
+}
+
 func (d *DeviceService) initializeDefaultDevices() {
 	defaultDevices := []*models.Device{
 		{
@@ -75,6 +127,149 @@ func (d *DeviceService) initializeDefaultDevices() {
 				"motion_detected": false,
 				"sensitivity":     7,
 				"battery_level":   85,
+				"zone":            "interior",
+			},
+		},
+		{
+			ID:       "sensor_002",
+			Name:     "Front Door Contact",
+			Type:     models.DeviceTypeContactSensor,
+			Status:   models.DeviceStatusOnline,
+			Location: "Front Door",
+			Properties: map[string]interface{}{
+				"open":          false,
+				"battery_level": 90,
+				"zone":          "perimeter",
+			},
+		},
+		{
+			ID:       "alarm_001",
+			Name:     "Hallway Siren",
+			Type:     models.DeviceTypeAlarm,
+			Status:   models.DeviceStatusOnline,
+			Location: "Hallway",
+			Properties: map[string]interface{}{
+				"active": false,
+			},
+		},
+		{
+			ID:       "smoke_001",
+			Name:     "Kitchen Smoke Detector",
+			Type:     models.DeviceTypeSmokeSensor,
+			Status:   models.DeviceStatusOnline,
+			Location: "Kitchen",
+			Properties: map[string]interface{}{
+				"smoke_level":    0.0,
+				"smoke_detected": false,
+				"battery_level":  95,
+			},
+		},
+		{
+			ID:       "co_001",
+			Name:     "Hallway CO Detector",
+			Type:     models.DeviceTypeCOSensor,
+			Status:   models.DeviceStatusOnline,
+			Location: "Hallway",
+			Properties: map[string]interface{}{
+				"co_ppm":        0.0,
+				"co_detected":   false,
+				"battery_level": 95,
+			},
+		},
+		{
+			ID:       "leak_001",
+			Name:     "Basement Leak Sensor",
+			Type:     models.DeviceTypeLeakSensor,
+			Status:   models.DeviceStatusOnline,
+			Location: "Basement",
+			Properties: map[string]interface{}{
+				"leak_detected": false,
+				"battery_level": 88,
+			},
+		},
+		{
+			ID:       "valve_001",
+			Name:     "Main Water Valve",
+			Type:     models.DeviceTypeWaterValve,
+			Status:   models.DeviceStatusOnline,
+			Location: "Basement",
+			Properties: map[string]interface{}{
+				"open": true,
+			},
+		},
+		{
+			ID:       "plug_001",
+			Name:     "Dehumidifier Plug",
+			Type:     models.DeviceTypeSmartPlug,
+			Status:   models.DeviceStatusOnline,
+			Location: "Basement",
+			Properties: map[string]interface{}{
+				"power":  true,
+				"load_w": 300,
+			},
+		},
+		{
+			ID:       "solar_001",
+			Name:     "Roof Solar",
+			Type:     models.DeviceTypeSolarInverter,
+			Status:   models.DeviceStatusOnline,
+			Location: "Roof",
+			Properties: map[string]interface{}{
+				"capacity_w": 6000,
+				"output_w":   0.0,
+			},
+		},
+		{
+			ID:       "battery_001",
+			Name:     "Home Battery",
+			Type:     models.DeviceTypeHomeBattery,
+			Status:   models.DeviceStatusOnline,
+			Location: "Garage",
+			Properties: map[string]interface{}{
+				"capacity_wh":     13500,
+				"soc":             50.0,
+				"max_power_w":     5000,
+				"power_w":         0.0,
+				"reserve_percent": d.config.EnergyFlow.BatteryReserve,
+				"dispatch":        string(d.config.EnergyFlow.BatteryDispatch),
+			},
+		},
+		{
+			ID:       "ev_001",
+			Name:     "EV Charger",
+			Type:     models.DeviceTypeEVCharger,
+			Status:   models.DeviceStatusOnline,
+			Location: "Garage",
+			Properties: map[string]interface{}{
+				"power":               true,
+				"connected":           false,
+				"charging":            false,
+				"max_power_w":         7200,
+				"vehicle_soc":         40.0,
+				"vehicle_capacity_wh": 60000,
+				"target_soc":          80,
+			},
+		},
+		{
+			ID:       "blinds_001",
+			Name:     "Living Room Blinds",
+			Type:     models.DeviceTypeBlinds,
+			Status:   models.DeviceStatusOnline,
+			Location: "Living Room",
+			Properties: map[string]interface{}{
+				"position":   100,
+				"sun_facing": true,
+			},
+		},
+		{
+			ID:       "irrigation_001",
+			Name:     "Garden Irrigation",
+			Type:     models.DeviceTypeIrrigation,
+			Status:   models.DeviceStatusOnline,
+			Location: "Garden",
+			Properties: map[string]interface{}{
+				"power":  true,
+				"paused": false,
 			},
 		},
 		{
@@ -84,9 +279,12 @@ func (d *DeviceService) initializeDefaultDevices() {
 			Status:   models.DeviceStatusOnline,
 			Location: "Front Door",
 			Properties: map[string]interface{}{
-				"locked":        true,
-				"auto_lock":     true,
-				"battery_level": 92,
+				"locked":          true,
+				"auto_lock":       true,
+				"auto_lock_delay": 30,
+				"door_sensor":     "sensor_002",
+				"jammed":          false,
+				"battery_level":   92,
 			},
 		},
 	}
@@ -97,12 +295,12 @@ func (d *DeviceService) initializeDefaultDevices() {
 }
 
 func (d *DeviceService) simulateDeviceUpdates() {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := d.clock.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			d.updateDeviceStates()
 		}
 	}
@@ -112,7 +310,7 @@ func (d *DeviceService) updateDeviceStates() {
 	devices := d.store.ListDevices()
 	
 	for _, device := range devices {
-		if rand.Float64() < 0.1 {
+		if d.deviceRand(device.ID).Float64() < 0.1 {
 			d.simulateDeviceChange(device)
 		}
 		
@@ -120,43 +318,55 @@ func (d *DeviceService) updateDeviceStates() {
 			d.updateThermostat(device)
 		}
 		
-		if device.Type == models.DeviceTypeSensor {
+		if models.IsSecuritySensor(device.Type) {
 			d.updateSensor(device)
 		}
+		
+		if _, ok := models.HazardSensors[device.Type]; ok {
+			d.updateHazardSensor(device)
+		}
+		
+		d.drainBattery(device)
 	}
 }
 
+func (d *DeviceService) deviceRand(id string) *random.Stream {
+	return d.random.Stream("device/" + id)
+}
+
 func (d *DeviceService) simulateDeviceChange(device *models.Device) {
+	if shed, _ := device.Properties["load_shed"].(bool); shed {
+		return
+	}
+	
+	rng := d.deviceRand(device.ID)
 	updates := make(map[string]interface{})
 	
 	switch device.Type {
 	case models.DeviceTypeLight:
-		if rand.Float64() < 0.5 {
-			updates["brightness"] = rand.Intn(100) + 1
+		if rng.Float64() < 0.5 {
+			updates["brightness"] = rng.Intn(100) + 1
 		}
-		if rand.Float64() < 0.3 {
-			updates["power"] = rand.Float64() < 0.7
+		if rng.Float64() < 0.3 {
+			updates["power"] = rng.Float64() < 0.7
 		}
 		
 	case models.DeviceTypeCamera:
-		if rand.Float64() < 0.2 {
-			updates["motion_detect"] = rand.Float64() < 0.8
+		if rng.Float64() < 0.2 {
+			updates["motion_detect"] = rng.Float64() < 0.8
 		}
-		if rand.Float64() < 0.1 {
-			updates["recording"] = rand.Float64() < 0.9
+		if rng.Float64() < 0.1 {
+			updates["recording"] = rng.Float64() < 0.9
 		}
 		
 	case models.DeviceTypeLock:
-		if rand.Float64() < 0.1 {
-			updates["locked"] = rand.Float64() < 0.9
-		}
-		if rand.Float64() < 0.05 {
-			updates["battery_level"] = rand.Intn(100) + 1
+		if rng.Float64() < 0.1 {
+			updates["locked"] = rng.Float64() < 0.9
 		}
 	}
 	
 	if len(updates) > 0 {
-		d.store.UpdateDevice(device.ID, updates)
+		d.UpdateDevice(device.ID, updates)
 	}
 }
 
@@ -171,13 +381,21 @@ func (d *DeviceService) updateThermostat(device *models.Device) {
 	
 	tempDiff := targetTemp - currentTemp
 	
+	deadband, ok := numberProperty(device, "deadband")
+	if !ok {
+		deadband = 1.0
+	}
+	
 	updates := make(map[string]interface{})
 	
-	if tempDiff > 1.0 {
+	if mode, _ := device.Properties["mode"].(string); mode == "off" {
+		updates["heating"] = false
+		updates["cooling"] = false
+	} else if tempDiff > deadband {
 		updates["heating"] = true
 		updates["cooling"] = false
 		updates["temperature"] = currentTemp + 0.5
-	} else if tempDiff < -1.0 {
+	} else if tempDiff < -deadband {
 		updates["heating"] = false
 		updates["cooling"] = true
 		updates["temperature"] = currentTemp - 0.5
@@ -197,30 +415,157 @@ func (d *DeviceService) updateThermostat(device *models.Device) {
 }
 
 func (d *DeviceService) updateSensor(device *models.Device) {
-	if rand.Float64() < 0.05 {
-		motionDetected := rand.Float64() < 0.3
+	zone := models.SensorZone(device)
+	if models.IsAlwaysActiveZone(zone) {
+		return
+	}
+	
+	rng := d.deviceRand(device.ID)
+	if rng.Float64() < 0.05 {
+		tripped := rng.Float64() < 0.3
 		
-		updates := map[string]interface{}{
-			"motion_detected": motionDetected,
+		property := "motion_detected"
+		if zone == models.SecurityZonePerimeter {
+			property = "open"
 		}
 		
-		if motionDetected {
-			security := d.store.GetSecurity()
-			if security.State == models.SecurityStateArmed {
-				security.State = models.SecurityStateTriggered
-				security.LastTriggered = time.Now()
-				security.TriggeredBy = device.ID
-				d.store.UpdateSecurity(security)
-			}
+		d.UpdateDevice(device.ID, map[string]interface{}{
+			property: tripped,
+		})
+	}
+}
+
+func (d *DeviceService) updateHazardSensor(device *models.Device) {
+	rng := d.deviceRand(device.ID)
+	updates := make(map[string]interface{})
+	
+	switch device.Type {
+	case models.DeviceTypeSmokeSensor:
+		level, _ := numberProperty(device, "smoke_level")
+		next := level * 0.95
+		if rng.Float64() < 0.0002 {
+			next = 15 + rng.Float64()*50
+		} else if next < 1 {
+			next = rng.Float64()
+		}
+		if next-level >= 0.5 || level-next >= 0.5 {
+			updates["smoke_level"] = utils.RoundToDecimal(next, 1)
 		}
 		
-		d.store.UpdateDevice(device.ID, updates)
+	case models.DeviceTypeCOSensor:
+		ppm, _ := numberProperty(device, "co_ppm")
+		next := ppm * 0.97
+		if rng.Float64() < 0.0002 {
+			next = 60 + rng.Float64()*190
+		} else if next < 5 {
+			next = rng.Float64() * 5
+		}
+		if next-ppm >= 1 || ppm-next >= 1 {
+			updates["co_ppm"] = utils.RoundToDecimal(next, 1)
+		}
+		
+	case models.DeviceTypeLeakSensor:
+		leaking, _ := device.Properties["leak_detected"].(bool)
+		if !leaking && rng.Float64() < 0.0002 {
+			updates["leak_detected"] = true
+		} else if leaking {
+			dryChance := 0.002
+			if d.waterShutOff() {
+				dryChance = 0.02
+			}
+			if rng.Float64() < dryChance {
+				updates["leak_detected"] = false
+			}
+		}
+	}
+	
+	if len(updates) > 0 {
+		d.UpdateDevice(device.ID, updates)
+	}
+}
+
+func (d *DeviceService) waterShutOff() bool {
+	valves := d.GetDevicesByType(models.DeviceTypeWaterValve)
+	for _, valve := range valves {
+		if open, _ := valve.Properties["open"].(bool); open {
+			return false
+		}
+	}
+	return len(valves) > 0
+}
+
+func (d *DeviceService) drainBattery(device *models.Device) {
+	d.batteryMu.Lock()
+	defer d.batteryMu.Unlock()
+	
+	rate, ok := batteryDrainPerHour[device.Type]
+	if !ok || device.Status != models.DeviceStatusOnline {
+		return
+	}
+	
+	battery, ok := numberProperty(device, "battery_level")
+	if !ok {
+		return
+	}
+	
+	if battery > sensorLowBattery {
+		delete(d.lowBattery, device.ID)
 	}
+	
+	d.drain[device.ID] += rate * 5 / 3600
+	if d.drain[device.ID] < 0.01 {
+		return
+	}
+	
+	battery = utils.RoundToDecimal(utils.LimitValue(battery-d.drain[device.ID], 0, 100), 2)
+	delete(d.drain, device.ID)
+	
+	updates := map[string]interface{}{
+		"battery_level": battery,
+	}
+	
+	if battery <= 0 {
+		updates["status"] = string(models.DeviceStatusOffline)
+		d.store.AddSystemEvent(models.SystemEvent{
+			ID:        utils.GenerateID("battery_depleted"),
+			Type:      "battery_depleted",
+			Source:    "devices",
+			Message:   fmt.Sprintf("%s battery depleted, device is offline", device.Name),
+			Data: map[string]interface{}{
+				"device_id": device.ID,
+			},
+			Timestamp: d.clock.Now(),
+			Severity:  "critical",
+		})
+	} else if battery <= sensorLowBattery && !d.lowBattery[device.ID] {
+		d.lowBattery[device.ID] = true
+		d.store.AddSystemEvent(models.SystemEvent{
+			ID:        utils.GenerateID("battery_low"),
+			Type:      "battery_low",
+			Source:    "devices",
+			Message:   fmt.Sprintf("%s battery low (%.0f%%)", device.Name, battery),
+			Data: map[string]interface{}{
+				"device_id":     device.ID,
+				"battery_level": battery,
+			},
+			Timestamp: d.clock.Now(),
+			Severity:  "warning",
+		})
+	}
+	
+	d.store.UpdateDevice(device.ID, updates)
 }
 
 func (d *DeviceService) AddDevice(device *models.Device) error {
+	if d.config.MaxDevices > 0 && len(d.store.ListDevices()) >= d.config.MaxDevices {
+		return fmt.Errorf("device limit of %d reached", d.config.MaxDevices)
+	}
+	
 	if device.ID == "" {
-		device.ID = fmt.Sprintf("%s_%d", device.Type, time.Now().UnixNano())
+		device.ID = utils.GenerateID(string(device.Type))
+	}
+	if strings.ContainsAny(device.ID, `/\`) || strings.Contains(device.ID, "..") {
+		return fmt.Errorf("device ID %q must not contain path separators or \"..\"", device.ID)
 	}
 	
 	if device.Properties == nil {
@@ -265,6 +610,123 @@ func (d *DeviceService) AddDevice(device *models.Device) error {
 		if device.Properties["battery_level"] == nil {
 			device.Properties["battery_level"] = 100
 		}
+		if device.Properties["zone"] == nil {
+			device.Properties["zone"] = string(models.SecurityZoneInterior)
+		}
+		if zone, ok := device.Properties["zone"].(string); !ok || !models.ValidSecurityZone(models.SecurityZone(zone)) {
+			return fmt.Errorf("invalid security zone: %v", device.Properties["zone"])
+		}
+		
+	case models.DeviceTypeContactSensor:
+		if device.Properties["open"] == nil {
+			device.Properties["open"] = false
+		}
+		if device.Properties["battery_level"] == nil {
+			device.Properties["battery_level"] = 100
+		}
+		if device.Properties["zone"] == nil {
+			device.Properties["zone"] = string(models.SecurityZonePerimeter)
+		}
+		if zone, ok := device.Properties["zone"].(string); !ok || !models.ValidSecurityZone(models.SecurityZone(zone)) {
+			return fmt.Errorf("invalid security zone: %v", device.Properties["zone"])
+		}
+		
+	case models.DeviceTypeSmokeSensor:
+		device.Properties["smoke_level"] = 0.0
+		device.Properties["smoke_detected"] = false
+		if device.Properties["battery_level"] == nil {
+			device.Properties["battery_level"] = 100
+		}
+		
+	case models.DeviceTypeCOSensor:
+		device.Properties["co_ppm"] = 0.0
+		device.Properties["co_detected"] = false
+		if device.Properties["battery_level"] == nil {
+			device.Properties["battery_level"] = 100
+		}
+		
+	case models.DeviceTypeLeakSensor:
+		device.Properties["leak_detected"] = false
+		if device.Properties["battery_level"] == nil {
+			device.Properties["battery_level"] = 100
+		}
+		
+	case models.DeviceTypeWaterValve:
+		if device.Properties["open"] == nil {
+			device.Properties["open"] = true
+		}
+		
+	case models.DeviceTypeSmartPlug:
+		if device.Properties["power"] == nil {
+			device.Properties["power"] = true
+		}
+		if device.Properties["load_w"] == nil {
+			device.Properties["load_w"] = 100
+		}
+		
+	case models.DeviceTypeSolarInverter:
+		if device.Properties["capacity_w"] == nil {
+			device.Properties["capacity_w"] = 4000
+		}
+		device.Properties["output_w"] = 0.0
+		
+	case models.DeviceTypeHomeBattery:
+		if device.Properties["capacity_wh"] == nil {
+			device.Properties["capacity_wh"] = 10000
+		}
+		if device.Properties["soc"] == nil {
+			device.Properties["soc"] = 50.0
+		}
+		if device.Properties["max_power_w"] == nil {
+			device.Properties["max_power_w"] = 5000
+		}
+		if device.Properties["reserve_percent"] == nil {
+			device.Properties["reserve_percent"] = d.config.EnergyFlow.BatteryReserve
+		}
+		if device.Properties["dispatch"] == nil {
+			device.Properties["dispatch"] = string(d.config.EnergyFlow.BatteryDispatch)
+		}
+		if err := validateDispatch(device.Properties["dispatch"]); err != nil {
+			return err
+		}
+		device.Properties["power_w"] = 0.0
+		
+	case models.DeviceTypeEVCharger:
+		if device.Properties["power"] == nil {
+			device.Properties["power"] = true
+		}
+		if device.Properties["connected"] == nil {
+			device.Properties["connected"] = false
+		}
+		if device.Properties["max_power_w"] == nil {
+			device.Properties["max_power_w"] = 7200
+		}
+		if device.Properties["vehicle_soc"] == nil {
+			device.Properties["vehicle_soc"] = 50.0
+		}
+		if device.Properties["vehicle_capacity_wh"] == nil {
+			device.Properties["vehicle_capacity_wh"] = 60000
+		}
+		if device.Properties["target_soc"] == nil {
+			device.Properties["target_soc"] = 80
+		}
+		device.Properties["charging"] = evCharging(device)
+		
+	case models.DeviceTypeBlinds:
+		if device.Properties["position"] == nil {
+			device.Properties["position"] = 100
+		}
+		if device.Properties["sun_facing"] == nil {
+			device.Properties["sun_facing"] = false
+		}
+		
+	case models.DeviceTypeIrrigation:
+		if device.Properties["power"] == nil {
+			device.Properties["power"] = true
+		}
+		if device.Properties["paused"] == nil {
+			device.Properties["paused"] = false
+		}
 		
 	case models.DeviceTypeLock:
 		if device.Properties["locked"] == nil {
@@ -273,6 +735,18 @@ func (d *DeviceService) AddDevice(device *models.Device) error {
 		if device.Properties["battery_level"] == nil {
 			device.Properties["battery_level"] = 100
 		}
+		if device.Properties["auto_lock"] == nil {
+			device.Properties["auto_lock"] = false
+		}
+		device.Properties["jammed"] = false
+		if err := d.validateDoorSensor(device.Properties["door_sensor"]); err != nil {
+			return err
+		}
+		
+	case models.DeviceTypeAlarm:
+		if device.Properties["active"] == nil {
+			device.Properties["active"] = false
+		}
 	}
 	
 	if device.Status == "" {
@@ -287,7 +761,126 @@ func (d *DeviceService) GetDevice(id string) (*models.Device, error) {
 }
 
 func (d *DeviceService) UpdateDevice(id string, updates map[string]interface{}) error {
-	return d.store.UpdateDevice(id, updates)
+	if zone, ok := updates["zone"]; ok {
+		if z, ok := zone.(string); !ok || !models.ValidSecurityZone(models.SecurityZone(z)) {
+			return fmt.Errorf("invalid security zone: %v", zone)
+		}
+	}
+	
+	if value, ok := updates["locked"]; ok {
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("locked must be true or false")
+		}
+	}
+	
+	device, err := d.store.GetDevice(id)
+	if err != nil {
+		return err
+	}
+	
+	if device.Type == models.DeviceTypeLock {
+		if sensor, ok := updates["door_sensor"]; ok {
+			if err := d.validateDoorSensor(sensor); err != nil {
+				return err
+			}
+		}
+		
+		if locked, ok := updates["locked"].(bool); ok && d.locks != nil {
+			if err := d.locks.Operate(id, locked, "manual"); err != nil {
+				return err
+			}
+			delete(updates, "locked")
+		}
+	}
+	
+	if device.Type == models.DeviceTypeWaterValve && d.safety != nil {
+		if open, _ := updates["open"].(bool); open {
+			if err := d.safety.CheckValveOpen(); err != nil {
+				return err
+			}
+		}
+	}
+	
+	if dispatch, ok := updates["dispatch"]; ok && device.Type == models.DeviceTypeHomeBattery {
+		if err := validateDispatch(dispatch); err != nil {
+			return err
+		}
+	}
+	
+	if position, ok := updates["position"]; ok && device.Type == models.DeviceTypeBlinds {
+		if value, ok := numberValue(position); !ok || value < 0 || value > 100 {
+			return fmt.Errorf("position must be between 0 and 100")
+		}
+	}
+	
+	if level, ok := updates["smoke_level"].(float64); ok && device.Type == models.DeviceTypeSmokeSensor {
+		updates["smoke_detected"] = level >= models.SmokeAlarmLevel
+	}
+	if ppm, ok := updates["co_ppm"].(float64); ok && device.Type == models.DeviceTypeCOSensor {
+		updates["co_detected"] = ppm >= models.COAlarmPPM
+	}
+	
+	if len(updates) > 0 {
+		if err := d.store.UpdateDevice(id, updates); err != nil {
+			return err
+		}
+	}
+	
+	if device.Type == models.DeviceTypeLock && d.locks != nil {
+		if _, ok := updates["battery_level"]; ok {
+			d.locks.BatteryReplaced(id)
+		}
+	}
+	
+	if _, ok := models.HazardSensors[device.Type]; ok && d.safety != nil {
+		d.safety.SensorChanged(id)
+	}
+	
+	if !models.IsSecuritySensor(device.Type) {
+		return nil
+	}
+	
+	if _, ok := updates["open"]; ok && d.locks != nil {
+		d.locks.DoorChanged(id)
+	}
+	
+	motion, _ := updates["motion_detected"].(bool)
+	open, _ := updates["open"].(bool)
+	if (motion || open) && d.security != nil {
+		d.security.SensorTripped(id)
+	}
+	
+	return nil
+}
+
+func (d *DeviceService) validateDoorSensor(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	
+	sensorID, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("door_sensor must be a sensor ID")
+	}
+	if sensorID == "" {
+		return nil
+	}
+	
+	sensor, err := d.store.GetDevice(sensorID)
+	if err != nil {
+		return err
+	}
+	if !models.IsSecuritySensor(sensor.Type) {
+		return fmt.Errorf("device %s is not a door sensor", sensorID)
+	}
+	return nil
+}
+
+func validateDispatch(value interface{}) error {
+	if policy, ok := value.(string); !ok || !models.ValidDispatchPolicy(models.DispatchPolicy(policy)) {
+		return fmt.Errorf("invalid dispatch policy: %v", value)
+	}
+	return nil
 }
 
 func (d *DeviceService) ListDevices() []*models.Device {
@@ -337,61 +930,150 @@ func (d *DeviceService) GetDevicesByStatus(status models.DeviceStatus) []*models
 	return filteredDevices
 }
 
-func (d *DeviceService) CalculateEnergyUsage() []models.EnergyUsage {
+func (d *DeviceService) CalculatePower() []models.EnergyUsage {
 	devices := d.store.ListDevices()
-	var usageData []models.EnergyUsage
+	now := d.clock.Now()
+	var readings []models.EnergyUsage
 	
 	for _, device := range devices {
-		var usage float64
-		
-		switch device.Type {
-		case models.DeviceTypeLight:
-			if power, ok := device.Properties["power"].(bool); ok && power {
-				if brightness, ok := device.Properties["brightness"].(int); ok {
-					usage = float64(brightness) * 0.001
-				} else {
-					usage = 0.05
-				}
-			}
-			
-		case models.DeviceTypeThermostat:
-			if heating, ok := device.Properties["heating"].(bool); ok && heating {
-				usage = 2.5
+		if device.Status == models.DeviceStatusOnline && !models.IsEnergySource(device.Type) {
+			reading := models.EnergyUsage{
+				DeviceID:   device.ID,
+				DeviceName: device.Name,
+				DeviceType: device.Type,
+				Location:   device.Location,
+				PowerW:     devicePower(device),
+				Timestamp:  now,
 			}
-			if cooling, ok := device.Properties["cooling"].(bool); ok && cooling {
-				usage = 3.0
+			d.tariffs.Price(&reading)
+			readings = append(readings, reading)
+		}
+	}
+	
+	return readings
+}
+
+func devicePower(device *models.Device) float64 {
+	switch device.Type {
+	case models.DeviceTypeLight:
+		if power, ok := device.Properties["power"].(bool); ok && power {
+			if brightness, ok := numberProperty(device, "brightness"); ok {
+				return brightness
 			}
-			if usage == 0 {
-				usage = 0.1
+			return 50
+		}
+		return 0
+		
+	case models.DeviceTypeThermostat:
+		if cooling, ok := device.Properties["cooling"].(bool); ok && cooling {
+			return 3000
+		}
+		if heating, ok := device.Properties["heating"].(bool); ok && heating {
+			return 2500
+		}
+		return 100
+		
+	case models.DeviceTypeCamera:
+		if recording, ok := device.Properties["recording"].(bool); ok && recording {
+			return 800
+		}
+		return 200
+		
+	case models.DeviceTypeSmartPlug:
+		if power, ok := device.Properties["power"].(bool); ok && power {
+			if load, ok := numberProperty(device, "load_w"); ok {
+				return load
 			}
-			
-		case models.DeviceTypeCamera:
-			if recording, ok := device.Properties["recording"].(bool); ok && recording {
-				usage = 0.8
-			} else {
-				usage = 0.2
+			return 100
+		}
+		return 1
+		
+	case models.DeviceTypeEVCharger:
+		if evCharging(device) {
+			if limit, ok := numberProperty(device, "max_power_w"); ok {
+				return limit
 			}
-			
-		case models.DeviceTypeSensor:
-			usage = 0.01
-			
-		case models.DeviceTypeLock:
-			usage = 0.005
-			
-		default:
-			usage = 0.1
-		}
-		
-		if device.Status == models.DeviceStatusOnline {
-			usageData = append(usageData, models.EnergyUsage{
-				DeviceID:   device.ID,
-				DeviceName: device.Name,
-				Usage:      usage,
-				Cost:       usage * 0.12,
-				Timestamp:  time.Now(),
-			})
+			return 7200
 		}
+		return 2
+		
+	case models.DeviceTypeSolarInverter, models.DeviceTypeHomeBattery:
+		return 0
+		
+	case models.DeviceTypeSensor:
+		return 10
+		
+	case models.DeviceTypeContactSensor, models.DeviceTypeLeakSensor:
+		return 2
+		
+	case models.DeviceTypeSmokeSensor, models.DeviceTypeCOSensor:
+		return 5
+		
+	case models.DeviceTypeWaterValve, models.DeviceTypeBlinds:
+		return 3
+		
+	case models.DeviceTypeIrrigation:
+		power, _ := device.Properties["power"].(bool)
+		paused, _ := device.Properties["paused"].(bool)
+		if power && !paused {
+			return 24
+		}
+		return 2
+		
+	case models.DeviceTypeLock:
+		return 5
 	}
 	
-	return usageData
-}
\ No newline at end of file
+	return 100
+}
+
+func evCharging(device *models.Device) bool {
+	power, _ := device.Properties["power"].(bool)
+	connected, _ := device.Properties["connected"].(bool)
+	soc, _ := numberProperty(device, "vehicle_soc")
+	target, ok := numberProperty(device, "target_soc")
+	if !ok {
+		target = 100
+	}
+	return power && connected && soc < target
+}
+
+func (d *DeviceService) SampleEnergy() []models.EnergyUsage {
+	readings := d.CalculatePower()
+	
+	d.energyMu.Lock()
+	defer d.energyMu.Unlock()
+	
+	online := make(map[string]bool, len(readings))
+	var samples []models.EnergyUsage
+	for _, reading := range readings {
+		online[reading.DeviceID] = true
+		previous, ok := d.lastPower[reading.DeviceID]
+		d.lastPower[reading.DeviceID] = reading
+		
+		elapsed := reading.Timestamp.Sub(previous.Timestamp)
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		
+		reading.Interval = elapsed.Seconds()
+		reading.EnergyWh = utils.RoundToDecimal((previous.PowerW+reading.PowerW)/2*elapsed.Hours(), 4)
+		d.tariffs.Price(&reading)
+		d.RecordEnergyUsage(reading)
+		samples = append(samples, reading)
+	}
+	
+	for deviceID := range d.lastPower {
+		if !online[deviceID] {
+			delete(d.lastPower, deviceID)
+		}
+	}
+	
+	return samples
+}
+
+func (d *DeviceService) RecordEnergyUsage(usage models.EnergyUsage) {
+	d.store.AddEnergyUsage(usage)
+	d.tariffs.Record(usage)
+	d.budgets.Record(usage)
+}
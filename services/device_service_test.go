@@ -0,0 +1,133 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestDevices(t *testing.T, cfg *config.Config) (*DeviceService, *storage.MemoryStore) {
+	clk := newTestClock(t)
+	src := random.NewSource(1)
+	store := storage.NewMemoryStore(clk)
+	
+	security := NewSecurityService(store, clk, cfg)
+	notifier := NewNotificationService(store, clk)
+	locks := NewLockService(store, notifier, clk, src, cfg)
+	safety := NewLifeSafetyService(store, notifier, clk)
+	tariffs := NewTariffService(clk, cfg)
+	weather := NewWeatherService(clk, src, cfg)
+	forecasts := NewForecastService(store, weather, tariffs, clk, cfg)
+	budgets := NewBudgetService(store, notifier, forecasts, clk, cfg)
+	
+	return NewDeviceService(store, security, locks, safety, tariffs, budgets, clk, src, cfg), store
+}
+
+func TestAddDeviceEnforcesMaxDevices(t *testing.T) {
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	devices, store := newTestDevices(t, cfg)
+	
+	installed := len(store.ListDevices())
+	cfg.MaxDevices = installed + 2
+	
+	for i := 0; i < 2; i++ {
+		device := &models.Device{Name: fmt.Sprintf("Extra Light %d", i), Type: models.DeviceTypeLight}
+		if err := devices.AddDevice(device); err != nil {
+			t.Fatalf("add device %d under the limit: %v", i+1, err)
+		}
+	}
+	
+	err := devices.AddDevice(&models.Device{Name: "One Too Many", Type: models.DeviceTypeLight})
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("device limit of %d reached", cfg.MaxDevices)) {
+		t.Fatalf("add device over the limit: error = %v, want the device limit error", err)
+	}
+	if got := len(store.ListDevices()); got != cfg.MaxDevices {
+		t.Errorf("devices = %d, want %d", got, cfg.MaxDevices)
+	}
+}
+
+func TestAddDeviceUnlimitedAtZero(t *testing.T) {
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	cfg.MaxDevices = 0
+	devices, _ := newTestDevices(t, cfg)
+	
+	for i := 0; i < 100; i++ {
+		if err := devices.AddDevice(&models.Device{Type: models.DeviceTypeSensor}); err != nil {
+			t.Fatalf("add device %d with no limit: %v", i+1, err)
+		}
+	}
+}
+
+func TestUpdateDeviceRejectsNonBoolLocked(t *testing.T) {
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	devices, store := newTestDevices(t, cfg)
+	
+	for _, value := range []interface{}{"false", 0, nil} {
+		err := devices.UpdateDevice("lock_001", map[string]interface{}{"locked": value})
+		if err == nil || err.Error() != "locked must be true or false" {
+			t.Errorf("locked %#v: error = %v, want a validation error", value, err)
+		}
+	}
+	
+	lock, _ := store.GetDevice("lock_001")
+	if locked, _ := lock.Properties["locked"].(bool); !locked {
+		t.Error("lock unlocked by an invalid locked value")
+	}
+}
+
+func TestSampleEnergyIntegratesPower(t *testing.T) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.EnergyUpdateInterval = 30 * 24 * 3600
+	tariffs := NewTariffService(clk, cfg)
+	
+	if err := store.AddDevice(&models.Device{ID: "plug_1", Name: "Heater Plug", Type: models.DeviceTypeSmartPlug, Status: models.DeviceStatusOnline,
+		Properties: map[string]interface{}{"power": true, "load_w": 1000.0}}); err != nil {
+		t.Fatalf("add device: %v", err)
+	}
+	
+	// Without the simulator, so the load only changes when the test says so.
+	devices := &DeviceService{store: store, tariffs: tariffs, clock: clk, config: cfg, lastPower: make(map[string]models.EnergyUsage),
+		budgets: NewBudgetService(store, NewNotificationService(store, clk), nil, clk, cfg)}
+		
+	if samples := devices.SampleEnergy(); len(samples) != 0 {
+		t.Fatalf("first sample = %+v, want nothing until there is an interval", samples)
+	}
+	
+	clk.Step(30 * time.Minute)
+	store.UpdateDevice("plug_1", map[string]interface{}{"load_w": 3000.0})
+	samples := devices.SampleEnergy()
+	if len(samples) != 1 {
+		t.Fatalf("samples = %+v, want one", samples)
+	}
+	sample := samples[0]
+	if sample.PowerW != 3000 || sample.Interval != 1800 || sample.EnergyWh != 1000 {
+		t.Errorf("sample = %v W over %vs for %v Wh, want 3000 W over 1800s for 1000 Wh", sample.PowerW, sample.Interval, sample.EnergyWh)
+	}
+	if want := sample.Rate; sample.Cost != want {
+		t.Errorf("cost = %v, want %v for 1 kWh", sample.Cost, want)
+	}
+	if got := len(store.GetEnergyUsageBetween(testStart, clk.Now().Add(time.Second))); got != 1 {
+		t.Errorf("stored samples = %d, want 1", got)
+	}
+	
+	// Time offline is not integrated.
+	store.UpdateDevice("plug_1", map[string]interface{}{"status": string(models.DeviceStatusOffline)})
+	clk.Step(time.Hour)
+	devices.SampleEnergy()
+	store.UpdateDevice("plug_1", map[string]interface{}{"status": string(models.DeviceStatusOnline)})
+	clk.Step(time.Minute)
+	if samples := devices.SampleEnergy(); len(samples) != 0 {
+		t.Errorf("sample after coming back online = %+v, want none", samples)
+	}
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const flowTickInterval = 10 * time.Second
+
+type EnergyFlowService struct {
+	store    *storage.MemoryStore
+	weather  *WeatherService
+	tariffs  *TariffService
+	clock    clock.Clock
+	config   *config.Config
+	mu       sync.Mutex
+	current  *models.EnergyFlow
+	lastTick time.Time
+}
+
+func NewEnergyFlowService(store *storage.MemoryStore, weather *WeatherService, tariffs *TariffService, clk clock.Clock, cfg *config.Config) *EnergyFlowService {
+	service := &EnergyFlowService{
+		store:    store,
+		weather:  weather,
+		tariffs:  tariffs,
+		clock:    clk,
+		config:   cfg,
+		lastTick: clk.Now(),
+	}
+	
+	go service.monitorFlow()
+	
+	return service
+}
+
+func (f *EnergyFlowService) Status() models.EnergyFlowStatus {
+	f.mu.Lock()
+	current := f.current
+	f.mu.Unlock()
+	
+	now := f.clock.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	status := models.EnergyFlowStatus{
+		Current:   current,
+		Today:     f.Summary(midnight, now.Add(time.Nanosecond)),
+		Batteries: []models.BatteryState{},
+	}
+	status.Today.To = now
+	
+	for _, device := range f.store.ListDevices() {
+		if device.Type != models.DeviceTypeHomeBattery {
+			continue
+		}
+		capacity, _ := numberProperty(device, "capacity_wh")
+		soc, _ := numberProperty(device, "soc")
+		power, _ := numberProperty(device, "power_w")
+		status.Batteries = append(status.Batteries, models.BatteryState{
+			DeviceID:       device.ID,
+			DeviceName:     device.Name,
+			Dispatch:       f.dispatchPolicy(device),
+			SoC:            utils.RoundToDecimal(soc, 2),
+			CapacityWh:     capacity,
+			StoredWh:       utils.RoundToDecimal(capacity*soc/100, 1),
+			PowerW:         power,
+			ReservePercent: f.reserve(device),
+		})
+	}
+	
+	return status
+}
+
+func (f *EnergyFlowService) Summary(from, to time.Time) models.EnergyFlowSummary {
+	return f.summarize(from, to, f.store.GetEnergyFlowsBetween(from, to))
+}
+
+func (f *EnergyFlowService) Hourly(from, to time.Time) []models.EnergyFlowSummary {
+	flows := f.store.GetEnergyFlowsBetween(from, to)
+	buckets := make([]models.EnergyFlowSummary, 0)
+	
+	start := from.Truncate(time.Hour)
+	for bucket := start; bucket.Before(to); bucket = bucket.Add(time.Hour) {
+		end := bucket.Add(time.Hour)
+		var inBucket []models.EnergyFlow
+		for _, flow := range flows {
+			if !flow.Timestamp.Before(bucket) && flow.Timestamp.Before(end) {
+				inBucket = append(inBucket, flow)
+			}
+		}
+		buckets = append(buckets, f.summarize(bucket, end, inBucket))
+	}
+	
+	return buckets
+}
+
+func (f *EnergyFlowService) summarize(from, to time.Time, flows []models.EnergyFlow) models.EnergyFlowSummary {
+	summary := models.EnergyFlowSummary{
+		From:     from,
+		To:       to,
+		Currency: f.tariffs.Active().Currency,
+	}
+	
+	for _, flow := range flows {
+		summary.ConsumptionWh += flow.ConsumptionWh
+		summary.SolarWh += flow.SolarWh
+		summary.BatteryChargeWh += flow.BatteryChargeWh
+		summary.BatteryDischargeWh += flow.BatteryDischargeWh
+		summary.ImportWh += flow.ImportWh
+		summary.ExportWh += flow.ExportWh
+		summary.ImportCost += flow.ImportCost
+		summary.ExportCredit += flow.ExportCredit
+	}
+	
+	if summary.SolarWh > 0 {
+		ratio := utils.RoundToDecimal((summary.SolarWh-summary.ExportWh)/summary.SolarWh, 4)
+		summary.SelfConsumption = &ratio
+	}
+	if summary.ConsumptionWh > 0 {
+		ratio := utils.RoundToDecimal(math.Max(1-summary.ImportWh/summary.ConsumptionWh, 0), 4)
+		summary.SelfSufficiency = &ratio
+	}
+	
+	summary.ConsumptionWh = utils.RoundToDecimal(summary.ConsumptionWh, 2)
+	summary.SolarWh = utils.RoundToDecimal(summary.SolarWh, 2)
+	summary.BatteryChargeWh = utils.RoundToDecimal(summary.BatteryChargeWh, 2)
+	summary.BatteryDischargeWh = utils.RoundToDecimal(summary.BatteryDischargeWh, 2)
+	summary.ImportWh = utils.RoundToDecimal(summary.ImportWh, 2)
+	summary.ExportWh = utils.RoundToDecimal(summary.ExportWh, 2)
+	summary.NetCost = utils.RoundToDecimal(summary.ImportCost-summary.ExportCredit, 4)
+	summary.ImportCost = utils.RoundToDecimal(summary.ImportCost, 4)
+	summary.ExportCredit = utils.RoundToDecimal(summary.ExportCredit, 4)
+	
+	return summary
+}
+
+func (f *EnergyFlowService) monitorFlow() {
+	ticker := f.clock.NewTicker(flowTickInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			f.updateFlow()
+		}
+	}
+}
+
+func (f *EnergyFlowService) updateFlow() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	
+	now := f.clock.Now()
+	hours := now.Sub(f.lastTick).Hours()
+	f.lastTick = now
+	if hours <= 0 {
+		return
+	}
+	
+	flow := models.EnergyFlow{
+		Timestamp: now,
+		Interval:  utils.RoundToDecimal(hours*3600, 3),
+	}
+	
+	var batteries []*models.Device
+	for _, device := range f.store.ListDevices() {
+		if device.Status != models.DeviceStatusOnline {
+			continue
+		}
+		
+		switch device.Type {
+		case models.DeviceTypeSolarInverter:
+			output := f.solarOutput(device, now)
+			f.store.UpdateDevice(device.ID, map[string]interface{}{"output_w": output})
+			flow.SolarW += output
+			
+		case models.DeviceTypeHomeBattery:
+			batteries = append(batteries, device)
+			
+		case models.DeviceTypeEVCharger:
+			f.chargeVehicle(device, hours)
+			flow.ConsumptionW += devicePower(device)
+			
+		default:
+			flow.ConsumptionW += devicePower(device)
+		}
+	}
+	
+	surplus := flow.SolarW - flow.ConsumptionW
+	for _, battery := range batteries {
+		power := f.dispatch(battery, surplus, now, hours)
+		surplus -= power
+		flow.BatteryW += power
+		if power > 0 {
+			flow.BatteryChargeWh += power * hours
+		} else {
+			flow.BatteryDischargeWh -= power * hours
+		}
+	}
+	
+	flow.GridW = utils.RoundToDecimal(flow.ConsumptionW-flow.SolarW+flow.BatteryW, 2)
+	flow.ConsumptionWh = utils.RoundToDecimal(flow.ConsumptionW*hours, 4)
+	flow.SolarWh = utils.RoundToDecimal(flow.SolarW*hours, 4)
+	flow.BatteryChargeWh = utils.RoundToDecimal(flow.BatteryChargeWh, 4)
+	flow.BatteryDischargeWh = utils.RoundToDecimal(flow.BatteryDischargeWh, 4)
+	if flow.GridW > 0 {
+		flow.ImportWh = utils.RoundToDecimal(flow.GridW*hours, 4)
+		price := models.EnergyUsage{EnergyWh: flow.ImportWh, Timestamp: now}
+		f.tariffs.Price(&price)
+		flow.ImportCost = price.Cost
+	} else {
+		flow.ExportWh = utils.RoundToDecimal(-flow.GridW*hours, 4)
+		flow.ExportCredit = utils.RoundToDecimal(flow.ExportWh/1000*f.config.EnergyFlow.ExportRate, 6)
+	}
+	
+	flow.SolarW = utils.RoundToDecimal(flow.SolarW, 2)
+	flow.ConsumptionW = utils.RoundToDecimal(flow.ConsumptionW, 2)
+	flow.BatteryW = utils.RoundToDecimal(flow.BatteryW, 2)
+	
+	f.store.AddEnergyFlow(flow)
+	f.current = &flow
+}
+
+func (f *EnergyFlowService) solarOutput(device *models.Device, now time.Time) float64 {
+	capacity, ok := numberProperty(device, "capacity_w")
+	if !ok || capacity <= 0 {
+		return 0
+	}
+	
+	hour := float64(now.Hour()) + float64(now.Minute())/60
+	if hour < 6 || hour >= 18 {
+		return 0
+	}
+	
+	factor, ok := models.SolarConditionFactor[f.weather.GetCurrentWeather().Condition]
+	if !ok {
+		factor = 0.5
+	}
+	
+	return utils.RoundToDecimal(capacity*math.Sin(math.Pi*(hour-6)/12)*factor, 1)
+}
+
+func (f *EnergyFlowService) chargeVehicle(device *models.Device, hours float64) {
+	power := devicePower(device)
+	charging := evCharging(device)
+	updates := map[string]interface{}{"charging": charging}
+	
+	if charging {
+		soc, _ := numberProperty(device, "vehicle_soc")
+		capacity, ok := numberProperty(device, "vehicle_capacity_wh")
+		target, _ := numberProperty(device, "target_soc")
+		if ok && capacity > 0 {
+			soc = math.Min(soc+power*hours/capacity*100, target)
+			updates["vehicle_soc"] = utils.RoundToDecimal(soc, 3)
+		}
+	}
+	
+	f.store.UpdateDevice(device.ID, updates)
+}
+
+func (f *EnergyFlowService) dispatch(battery *models.Device, surplus float64, now time.Time, hours float64) float64 {
+	capacity, ok := numberProperty(battery, "capacity_wh")
+	if !ok || capacity <= 0 {
+		return 0
+	}
+	soc, _ := numberProperty(battery, "soc")
+	maxPower, ok := numberProperty(battery, "max_power_w")
+	if !ok {
+		maxPower = 5000
+	}
+	efficiency := f.config.EnergyFlow.BatteryEfficiency
+	
+	maxCharge := math.Max(math.Min(maxPower, capacity*(100-soc)/100/(hours*efficiency)), 0)
+	maxDischarge := math.Max(math.Min(maxPower, capacity*(soc-f.reserve(battery))/100/hours), 0)
+	
+	power := 0.0
+	switch f.dispatchPolicy(battery) {
+	case models.DispatchBackup:
+		power = maxCharge
+		
+	case models.DispatchTOUArbitrage:
+		current, cheapest, dearest := f.rateWindow(now)
+		switch {
+		case cheapest < dearest && current <= cheapest:
+			power = maxCharge
+		case cheapest < dearest && current >= dearest && surplus < 0:
+			power = -math.Min(-surplus, maxDischarge)
+		case surplus > 0:
+			power = math.Min(surplus, maxCharge)
+		}
+		
+	default:
+		if surplus > 0 {
+			power = math.Min(surplus, maxCharge)
+		} else {
+			power = -math.Min(-surplus, maxDischarge)
+		}
+	}
+	
+	if power > 0 {
+		soc += power * hours * efficiency / capacity * 100
+	} else {
+		soc += power * hours / capacity * 100
+	}
+	power = utils.RoundToDecimal(power, 2)
+	
+	f.store.UpdateDevice(battery.ID, map[string]interface{}{
+		"soc":     utils.RoundToDecimal(math.Min(math.Max(soc, 0), 100), 4),
+		"power_w": power,
+	})
+	
+	return power
+}
+
+func (f *EnergyFlowService) rateWindow(now time.Time) (float64, float64, float64) {
+	plan := f.tariffs.Active()
+	current, _ := plan.RateAt(now, 0)
+	cheapest, dearest := current, current
+	
+	for offset := 30 * time.Minute; offset < 24*time.Hour; offset += 30 * time.Minute {
+		rate, _ := plan.RateAt(now.Add(offset), 0)
+		cheapest = math.Min(cheapest, rate)
+		dearest = math.Max(dearest, rate)
+	}
+	
+	return current, cheapest, dearest
+}
+
+func (f *EnergyFlowService) dispatchPolicy(battery *models.Device) models.DispatchPolicy {
+	if policy, ok := battery.Properties["dispatch"].(string); ok && models.ValidDispatchPolicy(models.DispatchPolicy(policy)) {
+		return models.DispatchPolicy(policy)
+	}
+	return f.config.EnergyFlow.BatteryDispatch
+}
+
+func (f *EnergyFlowService) reserve(battery *models.Device) float64 {
+	if reserve, ok := numberProperty(battery, "reserve_percent"); ok && reserve >= 0 && reserve <= 100 {
+		return reserve
+	}
+	return f.config.EnergyFlow.BatteryReserve
+}
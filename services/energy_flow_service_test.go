@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestFlow(t *testing.T, condition string) (*EnergyFlowService, *storage.MemoryStore, *clock.Simulated) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	cfg.WeatherUpdateInterval = 30 * 24 * 3600
+	cfg.Tariffs = testTariffs()
+	cfg.Tariff = "flat"
+	
+	for _, device := range []*models.Device{
+		{ID: "solar_1", Name: "Roof Solar", Type: models.DeviceTypeSolarInverter, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"capacity_w": 4000.0}},
+		{ID: "battery_1", Name: "Home Battery", Type: models.DeviceTypeHomeBattery, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"capacity_wh": 10000.0, "soc": 50.0, "max_power_w": 5000.0}},
+		{ID: "plug_1", Name: "Heater Plug", Type: models.DeviceTypeSmartPlug, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": true, "load_w": 1000.0}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	weather := NewWeatherService(clk, random.NewSource(1), cfg)
+	weather.SetWeather(&models.WeatherData{Temperature: 20, Humidity: 50, Pressure: 1013, Condition: condition, WindSpeed: 5, WindDir: "N"})
+	
+	// Without the monitor, so flows are only computed when the test asks. The
+	// first hour ends at noon.
+	flow := &EnergyFlowService{store: store, weather: weather, tariffs: NewTariffService(clk, cfg), clock: clk, config: cfg, lastTick: clk.Now().Add(-time.Hour)}
+	return flow, store, clk
+}
+
+func stepFlow(flow *EnergyFlowService, clk *clock.Simulated) models.EnergyFlow {
+	flow.updateFlow()
+	clk.Step(time.Hour)
+	return *flow.Status().Current
+}
+
+func TestBatteryAbsorbsSurplusAndCoversDeficit(t *testing.T) {
+	flow, store, clk := newTestFlow(t, "cloudy")
+	
+	// Cloudy noon sun gives 40% of capacity.
+	current := stepFlow(flow, clk)
+	if current.SolarWh != 1600 || current.ConsumptionWh != 1000 || current.BatteryChargeWh != 600 || current.GridW != 0 {
+		t.Errorf("flow = %+v, want 1600 Wh solar with 600 Wh into the battery and nothing from the grid", current)
+	}
+	assertProperty(t, store, "battery_1", "soc", 55.7)
+	
+	store.UpdateDevice("solar_1", map[string]interface{}{"status": string(models.DeviceStatusOffline)})
+	current = stepFlow(flow, clk)
+	if current.BatteryDischargeWh != 1000 || current.ImportWh != 0 {
+		t.Errorf("flow = %+v, want the battery to cover the 1000 Wh", current)
+	}
+	assertProperty(t, store, "battery_1", "soc", 45.7)
+	
+	// The battery stops at its 20% reserve.
+	store.UpdateDevice("battery_1", map[string]interface{}{"soc": 21.0})
+	current = stepFlow(flow, clk)
+	if current.BatteryDischargeWh != 100 || current.ImportWh != 900 || current.ImportCost != 0.108 {
+		t.Errorf("flow = %+v, want 100 Wh from the battery and 900 Wh imported for 0.108", current)
+	}
+	
+	summary := flow.Summary(testStart.Add(-time.Hour), clk.Now())
+	if summary.ConsumptionWh != 3000 || summary.ImportWh != 900 || summary.NetCost != 0.108 {
+		t.Errorf("summary = %+v, want 3000 Wh used with 900 Wh imported", summary)
+	}
+	if summary.SelfConsumption == nil || *summary.SelfConsumption != 1 || summary.SelfSufficiency == nil || *summary.SelfSufficiency != 0.7 {
+		t.Errorf("self consumption %v and sufficiency %v, want 1 and 0.7", summary.SelfConsumption, summary.SelfSufficiency)
+	}
+}
+
+func TestSurplusExportsWhenBatteryIsFull(t *testing.T) {
+	flow, store, clk := newTestFlow(t, "clear")
+	store.UpdateDevice("battery_1", map[string]interface{}{"soc": 100.0})
+	
+	current := stepFlow(flow, clk)
+	if current.SolarW != 4000 || current.BatteryW != 0 || current.ExportWh != 3000 || current.ExportCredit != 0.15 {
+		t.Errorf("flow = %+v, want 3000 Wh exported for 0.15", current)
+	}
+}
+
+func TestBackupDispatchChargesFromGrid(t *testing.T) {
+	flow, store, clk := newTestFlow(t, "clear")
+	store.UpdateDevice("solar_1", map[string]interface{}{"status": string(models.DeviceStatusOffline)})
+	store.UpdateDevice("battery_1", map[string]interface{}{"dispatch": string(models.DispatchBackup)})
+	
+	current := stepFlow(flow, clk)
+	if current.BatteryW != 5000 || current.ImportWh != 6000 {
+		t.Errorf("flow = %+v, want the battery charging at 5000 W from the grid", current)
+	}
+	if status := flow.Status(); len(status.Batteries) != 1 || status.Batteries[0].Dispatch != models.DispatchBackup {
+		t.Errorf("batteries = %+v, want one on backup dispatch", status.Batteries)
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+type EnergyReportService struct {
+	store   *storage.MemoryStore
+	tariffs *TariffService
+	clock   clock.Clock
+}
+
+type reportSpan struct {
+	start time.Time
+	end   time.Time
+}
+
+func NewEnergyReportService(store *storage.MemoryStore, tariffs *TariffService, clk clock.Clock) *EnergyReportService {
+	return &EnergyReportService{
+		store:   store,
+		tariffs: tariffs,
+		clock:   clk,
+	}
+}
+
+func (e *EnergyReportService) Report(from, to time.Time, groupBy models.EnergyGroupBy) (models.EnergyReport, error) {
+	switch groupBy {
+	case models.GroupByDevice, models.GroupByType, models.GroupByRoom, models.GroupByHour, models.GroupByDay:
+	default:
+		return models.EnergyReport{}, fmt.Errorf("unknown group_by %q, expected device, type, room, hour or day", groupBy)
+	}
+	if !from.Before(to) {
+		return models.EnergyReport{}, fmt.Errorf("from must be before to")
+	}
+	
+	report := models.EnergyReport{
+		From:     from,
+		To:       to,
+		GroupBy:  groupBy,
+		Currency: e.tariffs.Active().Currency,
+		Groups:   []models.EnergyReportGroup{},
+	}
+	
+	earliest, ok := e.store.EarliestEnergyUsage()
+	if ok {
+		report.DataFrom = earliest
+		report.Partial = earliest.After(from)
+	}
+	
+	samples := e.store.GetEnergyUsageBetween(from, to)
+	span := e.span(from, to, samples)
+	report.Totals = summarize(samples, span)
+	report.FixedCharges = e.tariffs.FixedCharges(e.tariffs.Active(), samples)
+	report.TotalCost = utils.RoundToDecimal(report.Totals.Cost+report.FixedCharges, 4)
+	
+	buckets := make(map[string][]models.EnergyUsage)
+	labels := make(map[string]string)
+	for _, sample := range samples {
+		key, label := groupKey(sample, groupBy)
+		buckets[key] = append(buckets[key], sample)
+		labels[key] = label
+	}
+	
+	for key, bucket := range buckets {
+		groupSpan := span
+		if groupBy == models.GroupByHour || groupBy == models.GroupByDay {
+			groupSpan = clip(periodOf(bucket[0].Timestamp, groupBy), span)
+		}
+		
+		group := models.EnergyReportGroup{
+			Key:          key,
+			Label:        labels[key],
+			EnergyTotals: summarize(bucket, groupSpan),
+		}
+		if report.Totals.EnergyWh > 0 {
+			group.SharePercent = utils.RoundToDecimal(group.EnergyWh/report.Totals.EnergyWh*100, 1)
+		}
+		report.Groups = append(report.Groups, group)
+	}
+	
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if groupBy == models.GroupByHour || groupBy == models.GroupByDay || a.EnergyWh == b.EnergyWh {
+			return a.Key < b.Key
+		}
+		return a.EnergyWh > b.EnergyWh
+	})
+	
+	length := to.Sub(from)
+	previousFrom := from.Add(-length)
+	previousSamples := e.store.GetEnergyUsageBetween(previousFrom, from)
+	report.Previous = models.EnergyComparison{
+		From:         previousFrom,
+		To:           from,
+		EnergyTotals: summarize(previousSamples, e.span(previousFrom, from, previousSamples)),
+	}
+	report.Previous.EnergyChangeWh = utils.RoundToDecimal(report.Totals.EnergyWh-report.Previous.EnergyWh, 4)
+	report.Previous.CostChange = utils.RoundToDecimal(report.Totals.Cost-report.Previous.Cost, 4)
+	report.Previous.EnergyChangePercent = percentChange(report.Previous.EnergyWh, report.Totals.EnergyWh)
+	report.Previous.CostChangePercent = percentChange(report.Previous.Cost, report.Totals.Cost)
+	
+	return report, nil
+}
+
+func (e *EnergyReportService) span(from, to time.Time, samples []models.EnergyUsage) reportSpan {
+	span := reportSpan{start: from, end: to}
+	if now := e.clock.Now(); now.Before(span.end) {
+		span.end = now
+	}
+	
+	if earliest, ok := e.store.EarliestEnergyUsage(); ok && earliest.After(span.start) {
+		span.start = earliest
+		if len(samples) > 0 && samples[0].Timestamp.Equal(earliest) {
+			span.start = earliest.Add(-time.Duration(samples[0].Interval * float64(time.Second)))
+		}
+	}
+	return span
+}
+
+func summarize(samples []models.EnergyUsage, span reportSpan) models.EnergyTotals {
+	var totals models.EnergyTotals
+	demand := make(map[time.Time]float64)
+	
+	for _, sample := range samples {
+		totals.EnergyWh += sample.EnergyWh
+		totals.Cost += sample.Cost
+		demand[sample.Timestamp] += sample.PowerW
+	}
+	
+	for at, watts := range demand {
+		if watts > totals.PeakDemandW || (watts == totals.PeakDemandW && at.Before(totals.PeakAt)) {
+			totals.PeakDemandW = watts
+			totals.PeakAt = at
+		}
+	}
+	
+	if hours := span.end.Sub(span.start).Hours(); hours > 0 {
+		totals.AveragePowerW = utils.RoundToDecimal(totals.EnergyWh/hours, 1)
+	}
+	
+	totals.Samples = len(samples)
+	totals.EnergyWh = utils.RoundToDecimal(totals.EnergyWh, 4)
+	totals.Cost = utils.RoundToDecimal(totals.Cost, 4)
+	totals.PeakDemandW = utils.RoundToDecimal(totals.PeakDemandW, 1)
+	return totals
+}
+
+func groupKey(sample models.EnergyUsage, groupBy models.EnergyGroupBy) (string, string) {
+	switch groupBy {
+	case models.GroupByType:
+		return string(sample.DeviceType), string(sample.DeviceType)
+		
+	case models.GroupByRoom:
+		if sample.Location == "" {
+			return "unassigned", "Unassigned"
+		}
+		return sample.Location, sample.Location
+		
+	case models.GroupByHour:
+		key := periodOf(sample.Timestamp, groupBy).start.Format("2006-01-02T15:00")
+		return key, key
+		
+	case models.GroupByDay:
+		key := periodOf(sample.Timestamp, groupBy).start.Format("2006-01-02")
+		return key, key
+	}
+	
+	return sample.DeviceID, sample.DeviceName
+}
+
+func periodOf(t time.Time, groupBy models.EnergyGroupBy) reportSpan {
+	if groupBy == models.GroupByDay {
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return reportSpan{start: start, end: start.AddDate(0, 0, 1)}
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	return reportSpan{start: start, end: start.Add(time.Hour)}
+}
+
+func clip(period, span reportSpan) reportSpan {
+	if span.start.After(period.start) {
+		period.start = span.start
+	}
+	if span.end.Before(period.end) {
+		period.end = span.end
+	}
+	return period
+}
+
+func percentChange(previous, current float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := utils.RoundToDecimal((current-previous)/previous*100, 1)
+	return &change
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestReports(t *testing.T) (*EnergyReportService, *storage.MemoryStore) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.Tariffs = testTariffs()
+	cfg.Tariff = "tiered"
+	return NewEnergyReportService(store, NewTariffService(clk, cfg), clk), store
+}
+
+func addSample(store *storage.MemoryStore, deviceID, location string, at time.Time, powerW float64) {
+	energyWh := powerW / 2
+	store.AddEnergyUsage(models.EnergyUsage{
+		DeviceID:   deviceID,
+		DeviceName: deviceID,
+		DeviceType: models.DeviceTypeLight,
+		Location:   location,
+		PowerW:     powerW,
+		EnergyWh:   energyWh,
+		Interval:   1800,
+		Cost:       energyWh / 1000 * 0.12,
+		Timestamp:  at,
+	})
+}
+
+func addReportSamples(store *storage.MemoryStore) {
+	addSample(store, "heater", "Living Room", testStart.Add(-4*time.Hour), 200)
+	addSample(store, "heater", "Living Room", testStart.Add(-2*time.Hour), 200)
+	addSample(store, "lamp", "", testStart.Add(-2*time.Hour), 50)
+	addSample(store, "heater", "Living Room", testStart.Add(-time.Hour), 400)
+	addSample(store, "lamp", "", testStart.Add(-time.Hour), 50)
+}
+
+func TestReportTotalsAndComparison(t *testing.T) {
+	reports, store := newTestReports(t)
+	addReportSamples(store)
+	
+	report, err := reports.Report(testStart.Add(-2*time.Hour), testStart, models.GroupByDevice)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	
+	totals := report.Totals
+	if totals.EnergyWh != 350 || totals.Cost != 0.042 || totals.Samples != 4 {
+		t.Errorf("totals = %+v, want 350 Wh costing 0.042 from 4 samples", totals)
+	}
+	if totals.PeakDemandW != 450 || !totals.PeakAt.Equal(testStart.Add(-time.Hour)) {
+		t.Errorf("peak = %v W at %s, want 450 W an hour ago", totals.PeakDemandW, totals.PeakAt)
+	}
+	if totals.AveragePowerW != 175 {
+		t.Errorf("average power = %v W, want 175", totals.AveragePowerW)
+	}
+	if report.FixedCharges != 0.5 || report.TotalCost != 0.542 {
+		t.Errorf("fixed charges %v and total cost %v, want 0.5 and 0.542", report.FixedCharges, report.TotalCost)
+	}
+	if report.Partial {
+		t.Error("report marked partial although older samples are retained")
+	}
+	
+	if len(report.Groups) != 2 || report.Groups[0].Key != "heater" || report.Groups[1].Key != "lamp" {
+		t.Fatalf("groups = %+v, want heater then lamp", report.Groups)
+	}
+	if report.Groups[0].SharePercent != 85.7 || report.Groups[1].SharePercent != 14.3 {
+		t.Errorf("shares = %v and %v, want 85.7 and 14.3", report.Groups[0].SharePercent, report.Groups[1].SharePercent)
+	}
+	
+	previous := report.Previous
+	if previous.EnergyWh != 100 || previous.EnergyChangeWh != 250 {
+		t.Errorf("previous = %v Wh with change %v, want 100 and 250", previous.EnergyWh, previous.EnergyChangeWh)
+	}
+	if previous.EnergyChangePercent == nil || *previous.EnergyChangePercent != 250 {
+		t.Errorf("energy change percent = %v, want 250", previous.EnergyChangePercent)
+	}
+}
+
+func TestReportGroupings(t *testing.T) {
+	reports, store := newTestReports(t)
+	addReportSamples(store)
+	from := testStart.Add(-2 * time.Hour)
+	
+	tests := []struct {
+		groupBy models.EnergyGroupBy
+		keys    []string
+		labels  []string
+		energy  []float64
+	}{
+		{models.GroupByRoom, []string{"Living Room", "unassigned"}, []string{"Living Room", "Unassigned"}, []float64{300, 50}},
+		{models.GroupByType, []string{"light"}, []string{"light"}, []float64{350}},
+		{models.GroupByHour, []string{"2024-01-15T10:00", "2024-01-15T11:00"}, []string{"2024-01-15T10:00", "2024-01-15T11:00"}, []float64{125, 225}},
+		{models.GroupByDay, []string{"2024-01-15"}, []string{"2024-01-15"}, []float64{350}},
+	}
+	for _, tt := range tests {
+		report, err := reports.Report(from, testStart, tt.groupBy)
+		if err != nil {
+			t.Errorf("report by %s: %v", tt.groupBy, err)
+			continue
+		}
+		if len(report.Groups) != len(tt.keys) {
+			t.Errorf("report by %s has %d groups, want %d", tt.groupBy, len(report.Groups), len(tt.keys))
+			continue
+		}
+		for i, group := range report.Groups {
+			if group.Key != tt.keys[i] || group.Label != tt.labels[i] || group.EnergyWh != tt.energy[i] {
+				t.Errorf("report by %s group %d = %s %q %v Wh, want %s %q %v Wh", tt.groupBy, i, group.Key, group.Label, group.EnergyWh, tt.keys[i], tt.labels[i], tt.energy[i])
+			}
+		}
+	}
+	
+	// Hour groups average over their own hour rather than the whole range.
+	report, _ := reports.Report(from, testStart, models.GroupByHour)
+	if got := report.Groups[1].AveragePowerW; got != 225 {
+		t.Errorf("11:00 average power = %v W, want 225", got)
+	}
+}
+
+func TestReportRejectsInvalidRequests(t *testing.T) {
+	reports, _ := newTestReports(t)
+	
+	if _, err := reports.Report(testStart.Add(-time.Hour), testStart, "zone"); err == nil {
+		t.Error("report grouped by zone succeeded")
+	}
+	if _, err := reports.Report(testStart, testStart, models.GroupByDevice); err == nil {
+		t.Error("report with an empty range succeeded")
+	}
+}
+
+func TestReportPartialWhenHistoryIsShorter(t *testing.T) {
+	reports, store := newTestReports(t)
+	addSample(store, "heater", "Living Room", testStart.Add(-time.Hour), 400)
+	
+	report, err := reports.Report(testStart.Add(-24*time.Hour), testStart, models.GroupByDevice)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if !report.Partial || !report.DataFrom.Equal(testStart.Add(-time.Hour)) {
+		t.Errorf("partial %v with data from %s, want partial data from an hour ago", report.Partial, report.DataFrom)
+	}
+	// The average covers the sampled interval only.
+	if report.Totals.AveragePowerW != 133.3 {
+		t.Errorf("average power = %v W, want 133.3 over the last 90 minutes", report.Totals.AveragePowerW)
+	}
+}
+
+func TestEnergyRetentionIsTimeBased(t *testing.T) {
+	_, store := newTestReports(t)
+	store.SetEnergyRetention(48 * time.Hour)
+	
+	start := testStart.Add(-72 * time.Hour)
+	for at := start; !at.After(testStart); at = at.Add(time.Minute) {
+		for _, device := range []string{"heater", "lamp", "fridge"} {
+			addSample(store, device, "", at, 100)
+		}
+	}
+	
+	earliest, ok := store.EarliestEnergyUsage()
+	if want := testStart.Add(-48 * time.Hour); !ok || !earliest.Equal(want) {
+		t.Errorf("earliest sample = %s, want %s", earliest, want)
+	}
+	if got, want := len(store.GetEnergyUsageBetween(testStart.Add(-24*time.Hour), testStart)), 24*60*3; got != want {
+		t.Errorf("samples in the last 24 hours = %d, want %d", got, want)
+	}
+}
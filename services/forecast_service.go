@@ -0,0 +1,395 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const (
+	forecastHorizon     = 24
+	forecastRefresh     = 5 * time.Minute
+	forecastMinCoverage = 0.25
+	forecastMinFit      = 6
+)
+
+type ForecastService struct {
+	store     *storage.MemoryStore
+	weather   *WeatherService
+	tariffs   *TariffService
+	clock     clock.Clock
+	config    *config.Config
+	mu        sync.Mutex
+	cached    *models.EnergyForecast
+	rolledTo  time.Time
+	tempHour  time.Time
+	tempSum   float64
+	tempCount int
+	hourTemps map[time.Time]float64
+}
+
+func NewForecastService(store *storage.MemoryStore, weather *WeatherService, tariffs *TariffService, clk clock.Clock, cfg *config.Config) *ForecastService {
+	service := &ForecastService{
+		store:     store,
+		weather:   weather,
+		tariffs:   tariffs,
+		clock:     clk,
+		config:    cfg,
+		rolledTo:  clk.Now().Truncate(time.Hour),
+		hourTemps: make(map[time.Time]float64),
+	}
+	
+	go service.monitorHistory()
+	
+	return service
+}
+
+func (f *ForecastService) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	
+	f.cached = nil
+	f.rolledTo = f.clock.Now().Truncate(time.Hour)
+	f.tempHour, f.tempSum, f.tempCount = time.Time{}, 0, 0
+	f.hourTemps = make(map[time.Time]float64)
+}
+
+func (f *ForecastService) Forecast() *models.EnergyForecast {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	
+	now := f.clock.Now()
+	if f.cached != nil && now.Sub(f.cached.GeneratedAt) < forecastRefresh && f.cached.From.Equal(now.Truncate(time.Hour)) {
+		return f.cached
+	}
+	
+	f.cached = f.build(now)
+	return f.cached
+}
+
+func (f *ForecastService) monitorHistory() {
+	ticker := f.clock.NewTicker(time.Duration(f.config.EnergyUpdateInterval) * time.Second)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			f.rollUp()
+		}
+	}
+}
+
+func (f *ForecastService) rollUp() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	
+	now := f.clock.Now()
+	hour := now.Truncate(time.Hour)
+	if !hour.Equal(f.tempHour) {
+		if f.tempCount > 0 {
+			f.hourTemps[f.tempHour] = f.tempSum / float64(f.tempCount)
+		}
+		f.tempHour, f.tempSum, f.tempCount = hour, 0, 0
+	}
+	f.tempSum += f.weather.GetCurrentWeather().Temperature
+	f.tempCount++
+	
+	var rollups []models.EnergyRollup
+	for ; !f.rolledTo.Add(time.Hour).After(now); f.rolledTo = f.rolledTo.Add(time.Hour) {
+		rollups = append(rollups, f.rollUpHour(f.rolledTo)...)
+	}
+	if rollups == nil {
+		return
+	}
+	
+	f.store.AddEnergyRollups(rollups, hour.AddDate(0, 0, -f.config.ForecastHistoryDays))
+	f.cached = nil
+}
+
+func (f *ForecastService) rollUpHour(hour time.Time) []models.EnergyRollup {
+	temperature, ok := f.hourTemps[hour]
+	if !ok {
+		temperature = f.weather.GetCurrentWeather().Temperature
+	}
+	delete(f.hourTemps, hour)
+	
+	byDevice := make(map[string]*models.EnergyRollup)
+	var order []string
+	for _, sample := range f.store.GetEnergyUsageBetween(hour, hour.Add(time.Hour)) {
+		rollup, ok := byDevice[sample.DeviceID]
+		if !ok {
+			rollup = &models.EnergyRollup{
+				Hour:        hour,
+				DeviceID:    sample.DeviceID,
+				DeviceName:  sample.DeviceName,
+				DeviceType:  sample.DeviceType,
+				Location:    sample.Location,
+				OutdoorTemp: utils.RoundToDecimal(temperature, 2),
+			}
+			byDevice[sample.DeviceID] = rollup
+			order = append(order, sample.DeviceID)
+		}
+		rollup.EnergyWh += sample.EnergyWh
+		rollup.Cost += sample.Cost
+		rollup.Coverage += sample.Interval / 3600
+	}
+	
+	rollups := make([]models.EnergyRollup, 0, len(order))
+	for _, deviceID := range order {
+		rollup := byDevice[deviceID]
+		rollup.EnergyWh = utils.RoundToDecimal(rollup.EnergyWh, 4)
+		rollup.Cost = utils.RoundToDecimal(rollup.Cost, 6)
+		rollup.Coverage = utils.RoundToDecimal(math.Min(rollup.Coverage, 1), 4)
+		rollups = append(rollups, *rollup)
+	}
+	return rollups
+}
+
+func (f *ForecastService) build(now time.Time) *models.EnergyForecast {
+	from := now.Truncate(time.Hour)
+	z := models.ForecastZScores[f.config.ForecastConfidence]
+	
+	history := make(map[string][]models.EnergyRollup)
+	hours := make(map[time.Time]bool)
+	for _, rollup := range f.store.GetEnergyRollups(from.AddDate(0, 0, -f.config.ForecastHistoryDays)) {
+		if rollup.Coverage >= forecastMinCoverage {
+			history[rollup.DeviceID] = append(history[rollup.DeviceID], rollup)
+			hours[rollup.Hour] = true
+		}
+	}
+	temperatures := f.weather.HourlyTemperatures(from, forecastHorizon)
+	
+	forecast := &models.EnergyForecast{
+		GeneratedAt:  now,
+		From:         from,
+		To:           from.Add(forecastHorizon * time.Hour),
+		Confidence:   f.config.ForecastConfidence,
+		HistoryHours: len(hours),
+		Currency:     f.tariffs.Active().Currency,
+		Hourly:       make([]models.ForecastPoint, forecastHorizon),
+		Devices:      []models.DeviceForecast{},
+	}
+	variances := make([]float64, forecastHorizon)
+	totalVariance := 0.0
+	
+	for _, device := range f.store.ListDevices() {
+		if device.Status != models.DeviceStatusOnline || models.IsEnergySource(device.Type) {
+			continue
+		}
+		
+		model := f.deviceModel(device, history[device.ID])
+		deviceForecast := models.DeviceForecast{
+			DeviceID:   device.ID,
+			DeviceName: device.Name,
+			DeviceType: device.Type,
+			Location:   device.Location,
+			Method:     model.method,
+			Samples:    len(history[device.ID]),
+			Hourly:     make([]models.ForecastPoint, forecastHorizon),
+		}
+		
+		deviceVariance := 0.0
+		for i := 0; i < forecastHorizon; i++ {
+			hour := from.Add(time.Duration(i) * time.Hour)
+			mean, sigma := model.predict(hour, temperatures[i], i)
+			
+			point := f.point(hour, mean, sigma, z)
+			deviceForecast.Hourly[i] = point
+			deviceForecast.EnergyWh += mean
+			deviceForecast.Cost += point.Cost
+			deviceVariance += sigma * sigma
+			
+			forecast.Hourly[i].EnergyWh += mean
+			forecast.Hourly[i].Cost += point.Cost
+			variances[i] += sigma * sigma
+		}
+		
+		spread := z * math.Sqrt(deviceVariance)
+		deviceForecast.LowerWh = utils.RoundToDecimal(math.Max(deviceForecast.EnergyWh-spread, 0), 2)
+		deviceForecast.UpperWh = utils.RoundToDecimal(deviceForecast.EnergyWh+spread, 2)
+		deviceForecast.EnergyWh = utils.RoundToDecimal(deviceForecast.EnergyWh, 2)
+		deviceForecast.Cost = utils.RoundToDecimal(deviceForecast.Cost, 4)
+		forecast.Devices = append(forecast.Devices, deviceForecast)
+		totalVariance += deviceVariance
+	}
+	
+	for i := range forecast.Hourly {
+		point := &forecast.Hourly[i]
+		point.Hour = from.Add(time.Duration(i) * time.Hour)
+		temperature := utils.RoundToDecimal(temperatures[i], 1)
+		point.OutdoorTemp = &temperature
+		
+		if point.EnergyWh > forecast.PeakPowerW {
+			forecast.PeakPowerW = point.EnergyWh
+			forecast.PeakAt = point.Hour
+		}
+		forecast.EnergyWh += point.EnergyWh
+		forecast.Cost += point.Cost
+		
+		spread := z * math.Sqrt(variances[i])
+		point.LowerWh = utils.RoundToDecimal(math.Max(point.EnergyWh-spread, 0), 2)
+		point.UpperWh = utils.RoundToDecimal(point.EnergyWh+spread, 2)
+		point.EnergyWh = utils.RoundToDecimal(point.EnergyWh, 2)
+		point.Cost = utils.RoundToDecimal(point.Cost, 4)
+	}
+	
+	spread := z * math.Sqrt(totalVariance)
+	forecast.LowerWh = utils.RoundToDecimal(math.Max(forecast.EnergyWh-spread, 0), 2)
+	forecast.UpperWh = utils.RoundToDecimal(forecast.EnergyWh+spread, 2)
+	forecast.EnergyWh = utils.RoundToDecimal(forecast.EnergyWh, 2)
+	forecast.Cost = utils.RoundToDecimal(forecast.Cost, 4)
+	forecast.PeakPowerW = utils.RoundToDecimal(forecast.PeakPowerW, 1)
+	
+	return forecast
+}
+
+func (f *ForecastService) point(hour time.Time, mean, sigma, z float64) models.ForecastPoint {
+	price := models.EnergyUsage{EnergyWh: mean, Timestamp: hour.Add(30 * time.Minute)}
+	f.tariffs.Price(&price)
+	
+	return models.ForecastPoint{
+		Hour:     hour,
+		EnergyWh: utils.RoundToDecimal(mean, 2),
+		LowerWh:  utils.RoundToDecimal(math.Max(mean-z*sigma, 0), 2),
+		UpperWh:  utils.RoundToDecimal(mean+z*sigma, 2),
+		Cost:     price.Cost,
+	}
+}
+
+type deviceModel struct {
+	method    string
+	history   []models.EnergyRollup
+	target    float64
+	intercept float64
+	slope     float64
+	residual  float64
+	drawW     float64
+	remaining float64
+}
+
+func (f *ForecastService) deviceModel(device *models.Device, history []models.EnergyRollup) *deviceModel {
+	model := &deviceModel{method: "profile", history: history}
+	
+	if device.Type == models.DeviceTypeThermostat {
+		if target, ok := numberProperty(device, "target_temp"); ok && model.fitTemperature(target) {
+			model.method = "temperature"
+			return model
+		}
+	}
+	
+	if len(history) == 0 {
+		model.method = "current_draw"
+		model.drawW = devicePower(device)
+		model.remaining = -1
+		if device.Type == models.DeviceTypeEVCharger && evCharging(device) {
+			soc, _ := numberProperty(device, "vehicle_soc")
+			target, _ := numberProperty(device, "target_soc")
+			capacity, _ := numberProperty(device, "vehicle_capacity_wh")
+			model.remaining = math.Max((target-soc)/100*capacity, 0)
+		}
+	}
+	
+	return model
+}
+
+func (m *deviceModel) fitTemperature(target float64) bool {
+	if len(m.history) < forecastMinFit {
+		return false
+	}
+	
+	n := float64(len(m.history))
+	sumX, sumY := 0.0, 0.0
+	for _, rollup := range m.history {
+		sumX += math.Abs(target - rollup.OutdoorTemp)
+		sumY += rollup.EnergyWh / rollup.Coverage
+	}
+	meanX, meanY := sumX/n, sumY/n
+	
+	covariance, variance := 0.0, 0.0
+	for _, rollup := range m.history {
+		dx := math.Abs(target-rollup.OutdoorTemp) - meanX
+		covariance += dx * (rollup.EnergyWh/rollup.Coverage - meanY)
+		variance += dx * dx
+	}
+	if variance/n < 0.25 || covariance <= 0 {
+		return false
+	}
+	
+	m.target = target
+	m.slope = covariance / variance
+	m.intercept = meanY - m.slope*meanX
+	
+	squares := 0.0
+	for _, rollup := range m.history {
+		residual := rollup.EnergyWh/rollup.Coverage - (m.intercept + m.slope*math.Abs(target-rollup.OutdoorTemp))
+		squares += residual * residual
+	}
+	m.residual = math.Sqrt(squares / (n - 2))
+	return true
+}
+
+func (m *deviceModel) predict(hour time.Time, temperature float64, index int) (float64, float64) {
+	switch m.method {
+	case "temperature":
+		mean := math.Max(m.intercept+m.slope*math.Abs(m.target-temperature), 0)
+		return mean, math.Max(m.residual, 0.05*mean)
+		
+	case "current_draw":
+		mean := m.drawW
+		if m.remaining >= 0 {
+			mean = math.Min(mean, math.Max(m.remaining-float64(index)*m.drawW, 0))
+		}
+		return mean, 0.25 * mean
+	}
+	
+	weekend := isWeekend(hour)
+	matches := m.matching(func(rollup models.EnergyRollup) bool {
+		return rollup.Hour.Hour() == hour.Hour() && isWeekend(rollup.Hour) == weekend
+	})
+	if len(matches) < 2 {
+		matches = m.matching(func(rollup models.EnergyRollup) bool {
+			return rollup.Hour.Hour() == hour.Hour()
+		})
+	}
+	if len(matches) < 2 {
+		matches = m.matching(func(rollup models.EnergyRollup) bool {
+			return true
+		})
+	}
+	
+	n := float64(len(matches))
+	mean := 0.0
+	for _, value := range matches {
+		mean += value
+	}
+	mean /= n
+	if len(matches) < 2 {
+		return mean, 0.25 * mean
+	}
+	
+	squares := 0.0
+	for _, value := range matches {
+		squares += (value - mean) * (value - mean)
+	}
+	return mean, math.Max(math.Sqrt(squares/(n-1)), 0.05*mean)
+}
+
+func (m *deviceModel) matching(match func(models.EnergyRollup) bool) []float64 {
+	var values []float64
+	for _, rollup := range m.history {
+		if match(rollup) {
+			values = append(values, rollup.EnergyWh/rollup.Coverage)
+		}
+	}
+	return values
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestForecasts(t *testing.T) (*ForecastService, *storage.MemoryStore) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	cfg.Tariffs = testTariffs()
+	cfg.Tariff = "flat"
+	// Keep the monitors idle; tests roll up history explicitly.
+	cfg.EnergyUpdateInterval = 30 * 24 * 3600
+	cfg.WeatherUpdateInterval = 30 * 24 * 3600
+	
+	for _, device := range []*models.Device{
+		{ID: "plug_1", Name: "Fridge Plug", Type: models.DeviceTypeSmartPlug, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": true, "load_w": 150.0}},
+		{ID: "light_1", Name: "Hall Light", Type: models.DeviceTypeLight, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": true, "brightness": 40}},
+		{ID: "thermostat_1", Name: "Thermostat", Type: models.DeviceTypeThermostat, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"mode": "heat", "heating": true, "target_temp": 21.0}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	weather := NewWeatherService(clk, random.NewSource(1), cfg)
+	// Forecast hours follow the current temperature through the daily cycle.
+	weather.forecast = nil
+	weather.SetWeather(&models.WeatherData{Temperature: 5, Humidity: 50, Pressure: 1013, Condition: "cloudy", WindSpeed: 5, WindDir: "N"})
+	
+	return NewForecastService(store, weather, NewTariffService(clk, cfg), clk, cfg), store
+}
+
+func forecastFor(forecast *models.EnergyForecast, deviceID string) models.DeviceForecast {
+	for _, device := range forecast.Devices {
+		if device.DeviceID == deviceID {
+			return device
+		}
+	}
+	return models.DeviceForecast{}
+}
+
+func TestRollUpSumsLastHour(t *testing.T) {
+	forecasts, store := newTestForecasts(t)
+	forecasts.rolledTo = testStart.Add(-time.Hour)
+	
+	for at := testStart.Add(-time.Hour); at.Before(testStart); at = at.Add(10 * time.Minute) {
+		store.AddEnergyUsage(models.EnergyUsage{DeviceID: "plug_1", DeviceType: models.DeviceTypeSmartPlug, EnergyWh: 25, Cost: 0.003, Interval: 600, Timestamp: at})
+	}
+	forecasts.rollUp()
+	
+	rollups := store.GetEnergyRollups(testStart.Add(-24 * time.Hour))
+	if len(rollups) != 1 {
+		t.Fatalf("rollups = %+v, want one", rollups)
+	}
+	rollup := rollups[0]
+	if !rollup.Hour.Equal(testStart.Add(-time.Hour)) || rollup.EnergyWh != 150 || rollup.Coverage != 1 || rollup.OutdoorTemp != 5 {
+		t.Errorf("rollup = %+v, want 150 Wh over the full 11:00 hour at 5°C", rollup)
+	}
+}
+
+func TestForecastModels(t *testing.T) {
+	forecasts, store := newTestForecasts(t)
+	
+	// The plug draws 200 W at noon and 100 W otherwise; the thermostat uses
+	// 100 W plus 50 W per degree below its target.
+	var rollups []models.EnergyRollup
+	for hour := testStart.AddDate(0, 0, -3); hour.Before(testStart); hour = hour.Add(time.Hour) {
+		energyWh := 100.0
+		if hour.Hour() == 12 {
+			energyWh = 200
+		}
+		rollups = append(rollups, models.EnergyRollup{Hour: hour, DeviceID: "plug_1", EnergyWh: energyWh, Coverage: 1})
+	}
+	for i, outdoor := range []float64{5, 7, 9, 11, 13, 15, 17, 19} {
+		rollups = append(rollups, models.EnergyRollup{Hour: testStart.Add(-time.Duration(i+1) * time.Hour), DeviceID: "thermostat_1",
+			EnergyWh: 100 + 50*(21-outdoor), Coverage: 1, OutdoorTemp: outdoor})
+	}
+	store.AddEnergyRollups(rollups, testStart.AddDate(0, 0, -30))
+	
+	forecast := forecasts.Forecast()
+	if !forecast.From.Equal(testStart) || len(forecast.Hourly) != 24 || forecast.HistoryHours != 72 {
+		t.Fatalf("forecast from %s with %d hours and %d history hours, want 24 hours from noon over 72 hours of history", forecast.From, len(forecast.Hourly), forecast.HistoryHours)
+	}
+	
+	plug := forecastFor(forecast, "plug_1")
+	if plug.Method != "profile" || plug.Hourly[0].EnergyWh != 200 || plug.Hourly[1].EnergyWh != 100 || plug.EnergyWh != 2500 {
+		t.Errorf("plug forecast = %s %v then %v for %v Wh, want a profile of 200 at noon then 100", plug.Method, plug.Hourly[0].EnergyWh, plug.Hourly[1].EnergyWh, plug.EnergyWh)
+	}
+	
+	light := forecastFor(forecast, "light_1")
+	if light.Method != "current_draw" || light.Hourly[5].EnergyWh != 40 {
+		t.Errorf("light forecast = %s %v, want its current 40 W draw", light.Method, light.Hourly[5].EnergyWh)
+	}
+	
+	thermostat := forecastFor(forecast, "thermostat_1")
+	if thermostat.Method != "temperature" || thermostat.Hourly[0].EnergyWh != 900 {
+		t.Errorf("thermostat forecast = %s %v, want 900 Wh at 5°C from the temperature fit", thermostat.Method, thermostat.Hourly[0].EnergyWh)
+	}
+	
+	if forecast.Hourly[0].EnergyWh != 1140 {
+		t.Errorf("house noon = %v Wh, want 1140", forecast.Hourly[0].EnergyWh)
+	}
+	// Heating peaks in the coldest hour of the night.
+	coldest := forecast.Hourly[0]
+	for _, point := range forecast.Hourly {
+		if *point.OutdoorTemp < *coldest.OutdoorTemp {
+			coldest = point
+		}
+	}
+	if !forecast.PeakAt.Equal(coldest.Hour) || forecast.PeakPowerW <= 1140 {
+		t.Errorf("peak %v Wh at %s, want above noon at the coldest hour %s", forecast.PeakPowerW, forecast.PeakAt, coldest.Hour)
+	}
+	if point := forecast.Hourly[0]; point.LowerWh >= point.EnergyWh || point.UpperWh <= point.EnergyWh {
+		t.Errorf("noon band = %v..%v around %v, want it to contain the forecast", point.LowerWh, point.UpperWh, point.EnergyWh)
+	}
+	
+	if again := forecasts.Forecast(); again != forecast {
+		t.Error("forecast rebuilt within the refresh interval")
+	}
+}
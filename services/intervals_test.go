@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+type recordingClock struct {
+	*clock.Simulated
+	tickers chan time.Duration
+}
+
+func (c *recordingClock) NewTicker(d time.Duration) clock.Ticker {
+	ticker := c.Simulated.NewTicker(d)
+	c.tickers <- d
+	return ticker
+}
+
+func newRecordingClock(t *testing.T) *recordingClock {
+	return &recordingClock{Simulated: newTestClock(t), tickers: make(chan time.Duration, 16)}
+}
+
+func (c *recordingClock) nextTicker(t *testing.T) time.Duration {
+	t.Helper()
+	select {
+	case d := <-c.tickers:
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("no ticker started")
+		return 0
+	}
+}
+
+type countingWeatherProvider struct {
+	polls chan time.Time
+}
+
+func (p countingWeatherProvider) Name() string {
+	return "counting"
+}
+
+func (p countingWeatherProvider) Current(now time.Time, previous models.WeatherData) (models.WeatherData, error) {
+	p.polls <- now
+	return previous, nil
+}
+
+func (p countingWeatherProvider) Forecast(now time.Time, current models.WeatherData, hours int) ([]models.WeatherData, error) {
+	return nil, nil
+}
+
+func TestWeatherPollsAtConfiguredInterval(t *testing.T) {
+	clk := newRecordingClock(t)
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	cfg.WeatherUpdateInterval = 45
+	
+	weather := NewWeatherService(clk, random.NewSource(1), cfg)
+	provider := countingWeatherProvider{polls: make(chan time.Time, 4)}
+	weather.provider = provider
+	weather.SetStore(storage.NewMemoryStore(clk))
+	
+	if d := clk.nextTicker(t); d != 45*time.Second {
+		t.Fatalf("weather ticker interval = %s, want 45s", d)
+	}
+	
+	clk.Step(44 * time.Second)
+	select {
+	case at := <-provider.polls:
+		t.Fatalf("weather polled at %s, before the 45s interval", at)
+	default:
+	}
+	
+	clk.Step(time.Second)
+	select {
+	case at := <-provider.polls:
+		if want := testStart.Add(45 * time.Second); !at.Equal(want) {
+			t.Errorf("weather polled at %s, want %s", at, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("weather not polled after the 45s interval")
+	}
+}
+
+func TestEnergyMonitorsUseConfiguredInterval(t *testing.T) {
+	clk := newRecordingClock(t)
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	cfg.EnergyUpdateInterval = 15
+	store := storage.NewMemoryStore(clk)
+	
+	tariffs := NewTariffService(clk, cfg)
+	weather := NewWeatherService(clk, random.NewSource(1), cfg)
+	forecasts := NewForecastService(store, weather, tariffs, clk, cfg)
+	if d := clk.nextTicker(t); d != 15*time.Second {
+		t.Errorf("forecast ticker interval = %s, want 15s", d)
+	}
+	
+	NewBudgetService(store, NewNotificationService(store, clk), forecasts, clk, cfg)
+	if d := clk.nextTicker(t); d != 15*time.Second {
+		t.Errorf("budget ticker interval = %s, want 15s", d)
+	}
+	
+	NewAnomalyService(store, tariffs, clk, cfg)
+	if d := clk.nextTicker(t); d != 15*time.Second {
+		t.Errorf("anomaly ticker interval = %s, want 15s", d)
+	}
+}
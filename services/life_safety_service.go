@@ -0,0 +1,259 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+var hazardNames = map[models.Hazard]string{
+	models.HazardSmoke: "Smoke",
+	models.HazardCO:    "Carbon monoxide",
+	models.HazardLeak:  "Water leak",
+}
+
+var hazardNotify = map[models.Hazard][]string{
+	models.HazardSmoke: {"push", "sms", "monitoring_center"},
+	models.HazardCO:    {"push", "sms", "monitoring_center"},
+	models.HazardLeak:  {"push", "sms"},
+}
+
+type hazardResponse struct {
+	alarm   models.LifeSafetyAlarm
+	restore map[string]map[string]interface{}
+}
+
+type LifeSafetyService struct {
+	store    *storage.MemoryStore
+	notifier *NotificationService
+	clock    clock.Clock
+	mu       sync.Mutex
+	active   map[string]*hazardResponse
+}
+
+func NewLifeSafetyService(store *storage.MemoryStore, notifier *NotificationService, clk clock.Clock) *LifeSafetyService {
+	return &LifeSafetyService{
+		store:    store,
+		notifier: notifier,
+		clock:    clk,
+		active:   make(map[string]*hazardResponse),
+	}
+}
+
+func (l *LifeSafetyService) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	l.active = make(map[string]*hazardResponse)
+}
+
+func (l *LifeSafetyService) SensorChanged(sensorID string) {
+	device, err := l.store.GetDevice(sensorID)
+	if err != nil {
+		return
+	}
+	
+	hazard, detected := models.HazardDetected(device)
+	if hazard == "" {
+		return
+	}
+	
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	response, active := l.active[sensorID]
+	if detected && !active {
+		l.raise(device, hazard)
+	} else if !detected && active {
+		l.clear(response)
+	}
+}
+
+func (l *LifeSafetyService) GetAlarms(activeOnly bool, limit int) []models.LifeSafetyAlarm {
+	return l.store.GetSafetyAlarms(activeOnly, limit)
+}
+
+func (l *LifeSafetyService) Silence(alarmID string) (models.LifeSafetyAlarm, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	for _, response := range l.active {
+		if response.alarm.ID != alarmID {
+			continue
+		}
+		
+		if !response.alarm.Silenced {
+			for deviceID, previous := range response.restore {
+				device, err := l.store.GetDevice(deviceID)
+				if err != nil || device.Type != models.DeviceTypeAlarm {
+					continue
+				}
+				l.restoreSiren(deviceID, previous)
+				delete(response.restore, deviceID)
+			}
+			
+			response.alarm.Silenced = true
+			l.store.UpdateSafetyAlarm(response.alarm)
+			l.record(response.alarm, "life_safety_silenced", "info", fmt.Sprintf("%s alarm silenced", hazardNames[response.alarm.Hazard]))
+		}
+		
+		return response.alarm, nil
+	}
+	
+	alarm, err := l.store.GetSafetyAlarm(alarmID)
+	if err != nil {
+		return alarm, err
+	}
+	return alarm, fmt.Errorf("safety alarm %s is no longer active", alarmID)
+}
+
+func (l *LifeSafetyService) CheckValveOpen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	for _, response := range l.active {
+		if response.alarm.Hazard == models.HazardLeak {
+			return fmt.Errorf("water leak still detected by %s", response.alarm.SensorID)
+		}
+	}
+	return nil
+}
+
+func (l *LifeSafetyService) raise(sensor *models.Device, hazard models.Hazard) {
+	response := &hazardResponse{
+		alarm: models.LifeSafetyAlarm{
+			ID:        utils.GenerateID("safety"),
+			Hazard:    hazard,
+			SensorID:  sensor.ID,
+			Location:  sensor.Location,
+			Active:    true,
+			Actions:   []string{},
+			StartedAt: l.clock.Now(),
+		},
+		restore: make(map[string]map[string]interface{}),
+	}
+	
+	for _, device := range l.store.ListDevices() {
+		if device.Status != models.DeviceStatusOnline {
+			continue
+		}
+		
+		switch {
+		case device.Type == models.DeviceTypeWaterValve && hazard == models.HazardLeak:
+			if open, _ := device.Properties["open"].(bool); open {
+				l.store.UpdateDevice(device.ID, map[string]interface{}{"open": false})
+				response.alarm.Actions = append(response.alarm.Actions, "closed "+device.ID)
+			}
+			
+		case hazard == models.HazardLeak:
+			continue
+			
+		case device.Type == models.DeviceTypeAlarm:
+			l.apply(response, device, map[string]interface{}{"active": true}, "sounded "+device.ID)
+			
+		case device.Type == models.DeviceTypeThermostat:
+			l.apply(response, device, map[string]interface{}{"mode": "off", "heating": false, "cooling": false}, "shut down HVAC "+device.ID)
+			
+		case device.Type == models.DeviceTypeLight && hazard == models.HazardSmoke:
+			l.apply(response, device, map[string]interface{}{"power": true, "brightness": 100}, "lit "+device.ID)
+		}
+	}
+	
+	l.active[sensor.ID] = response
+	l.store.AddSafetyAlarm(response.alarm)
+	
+	severity := "critical"
+	if hazard == models.HazardLeak {
+		severity = "warning"
+	}
+	
+	message := fmt.Sprintf("%s detected by %s in %s", hazardNames[hazard], sensor.Name, sensor.Location)
+	l.record(response.alarm, "life_safety_alarm", severity, message)
+	for _, channel := range hazardNotify[hazard] {
+		l.notifier.Notify(channel, severity, message, map[string]interface{}{
+			"alarm_id":  response.alarm.ID,
+			"hazard":    hazard,
+			"sensor_id": sensor.ID,
+		})
+	}
+}
+
+func (l *LifeSafetyService) clear(response *hazardResponse) {
+	delete(l.active, response.alarm.SensorID)
+	
+	for deviceID, previous := range response.restore {
+		device, err := l.store.GetDevice(deviceID)
+		if err != nil {
+			continue
+		}
+		if device.Type == models.DeviceTypeAlarm {
+			l.restoreSiren(deviceID, previous)
+			continue
+		}
+		l.store.UpdateDevice(deviceID, previous)
+	}
+	
+	response.alarm.Active = false
+	response.alarm.ClearedAt = l.clock.Now()
+	l.store.UpdateSafetyAlarm(response.alarm)
+	
+	message := fmt.Sprintf("%s cleared in %s", hazardNames[response.alarm.Hazard], response.alarm.Location)
+	l.record(response.alarm, "life_safety_cleared", "info", message)
+	l.notifier.Notify("push", "info", message, map[string]interface{}{
+		"alarm_id":  response.alarm.ID,
+		"hazard":    response.alarm.Hazard,
+		"sensor_id": response.alarm.SensorID,
+	})
+}
+
+func (l *LifeSafetyService) apply(response *hazardResponse, device *models.Device, updates map[string]interface{}, action string) {
+	if !l.held(device.ID) {
+		previous := make(map[string]interface{}, len(updates))
+		for key := range updates {
+			if value, ok := device.Properties[key]; ok {
+				previous[key] = value
+			}
+		}
+		response.restore[device.ID] = previous
+	}
+	
+	l.store.UpdateDevice(device.ID, updates)
+	response.alarm.Actions = append(response.alarm.Actions, action)
+}
+
+func (l *LifeSafetyService) held(deviceID string) bool {
+	for _, response := range l.active {
+		if _, ok := response.restore[deviceID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *LifeSafetyService) restoreSiren(deviceID string, previous map[string]interface{}) {
+	if l.store.GetSecurity().State == models.SecurityStateTriggered {
+		return
+	}
+	l.store.UpdateDevice(deviceID, previous)
+}
+
+func (l *LifeSafetyService) record(alarm models.LifeSafetyAlarm, eventType, severity, message string) {
+	l.store.AddSystemEvent(models.SystemEvent{
+		ID:      utils.GenerateID(eventType),
+		Type:    eventType,
+		Source:  "life_safety",
+		Message: message,
+		Data: map[string]interface{}{
+			"alarm_id":  alarm.ID,
+			"hazard":    alarm.Hazard,
+			"sensor_id": alarm.SensorID,
+			"actions":   alarm.Actions,
+		},
+		Timestamp: l.clock.Now(),
+		Severity:  severity,
+	})
+}
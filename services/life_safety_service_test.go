@@ -0,0 +1,126 @@
+package services
+
+import (
+	"testing"
+
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestSafety(t *testing.T) (*LifeSafetyService, *storage.MemoryStore) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	
+	for _, device := range []*models.Device{
+		{ID: "smoke_1", Name: "Kitchen Smoke", Type: models.DeviceTypeSmokeSensor, Location: "Kitchen", Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"smoke_detected": false}},
+		{ID: "leak_1", Name: "Laundry Leak", Type: models.DeviceTypeLeakSensor, Location: "Laundry", Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"leak_detected": false}},
+		{ID: "siren_1", Name: "Siren", Type: models.DeviceTypeAlarm, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"active": false}},
+		{ID: "thermostat_1", Name: "Thermostat", Type: models.DeviceTypeThermostat, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"mode": "heat", "heating": true, "cooling": false}},
+		{ID: "light_1", Name: "Hall Light", Type: models.DeviceTypeLight, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": false, "brightness": 30}},
+		{ID: "lock_1", Name: "Front Lock", Type: models.DeviceTypeLock, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"locked": true}},
+		{ID: "valve_1", Name: "Main Valve", Type: models.DeviceTypeWaterValve, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"open": true}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	return NewLifeSafetyService(store, NewNotificationService(store, clk), clk), store
+}
+
+func detect(t *testing.T, safety *LifeSafetyService, store *storage.MemoryStore, sensorID, property string, detected bool) {
+	t.Helper()
+	if err := store.UpdateDevice(sensorID, map[string]interface{}{property: detected}); err != nil {
+		t.Fatalf("update sensor: %v", err)
+	}
+	safety.SensorChanged(sensorID)
+}
+
+func TestSmokeAlarmRespondsAndRestores(t *testing.T) {
+	safety, store := newTestSafety(t)
+	
+	detect(t, safety, store, "smoke_1", "smoke_detected", true)
+	
+	assertProperty(t, store, "siren_1", "active", true)
+	assertProperty(t, store, "thermostat_1", "mode", "off")
+	assertProperty(t, store, "thermostat_1", "heating", false)
+	assertProperty(t, store, "light_1", "power", true)
+	assertProperty(t, store, "light_1", "brightness", 100)
+	assertProperty(t, store, "lock_1", "locked", true)
+	assertProperty(t, store, "valve_1", "open", true)
+	
+	alarms := safety.GetAlarms(true, 0)
+	if len(alarms) != 1 || alarms[0].Hazard != models.HazardSmoke || alarms[0].SensorID != "smoke_1" {
+		t.Fatalf("active alarms = %+v, want one smoke alarm from smoke_1", alarms)
+	}
+	if got := countEvents(store, "life_safety_alarm"); got != 1 {
+		t.Errorf("life_safety_alarm events = %d, want 1", got)
+	}
+	
+	// A repeated reading does not raise a second alarm.
+	detect(t, safety, store, "smoke_1", "smoke_detected", true)
+	if got := len(safety.GetAlarms(true, 0)); got != 1 {
+		t.Errorf("active alarms after a repeated reading = %d, want 1", got)
+	}
+	
+	detect(t, safety, store, "smoke_1", "smoke_detected", false)
+	
+	assertProperty(t, store, "siren_1", "active", false)
+	assertProperty(t, store, "thermostat_1", "mode", "heat")
+	assertProperty(t, store, "thermostat_1", "heating", true)
+	assertProperty(t, store, "light_1", "power", false)
+	assertProperty(t, store, "light_1", "brightness", 30)
+	
+	if got := len(safety.GetAlarms(true, 0)); got != 0 {
+		t.Errorf("active alarms after clearing = %d, want 0", got)
+	}
+	if alarms := safety.GetAlarms(false, 0); len(alarms) != 1 || alarms[0].Active || alarms[0].ClearedAt.IsZero() {
+		t.Errorf("alarm history = %+v, want one cleared alarm", alarms)
+	}
+}
+
+func TestLeakClosesValveOnly(t *testing.T) {
+	safety, store := newTestSafety(t)
+	
+	detect(t, safety, store, "leak_1", "leak_detected", true)
+	
+	assertProperty(t, store, "valve_1", "open", false)
+	assertProperty(t, store, "siren_1", "active", false)
+	assertProperty(t, store, "thermostat_1", "mode", "heat")
+	if err := safety.CheckValveOpen(); err == nil {
+		t.Error("valve may be reopened while the leak is detected")
+	}
+	
+	detect(t, safety, store, "leak_1", "leak_detected", false)
+	
+	assertProperty(t, store, "valve_1", "open", false)
+	if err := safety.CheckValveOpen(); err != nil {
+		t.Errorf("valve blocked after the leak cleared: %v", err)
+	}
+}
+
+func TestSilenceStopsSirensOnly(t *testing.T) {
+	safety, store := newTestSafety(t)
+	
+	detect(t, safety, store, "smoke_1", "smoke_detected", true)
+	alarm := safety.GetAlarms(true, 0)[0]
+	
+	silenced, err := safety.Silence(alarm.ID)
+	if err != nil || !silenced.Silenced {
+		t.Fatalf("silence = %+v, %v, want a silenced alarm", silenced, err)
+	}
+	assertProperty(t, store, "siren_1", "active", false)
+	assertProperty(t, store, "thermostat_1", "mode", "off")
+	
+	detect(t, safety, store, "smoke_1", "smoke_detected", false)
+	if _, err := safety.Silence(alarm.ID); err == nil {
+		t.Error("silencing a cleared alarm succeeded")
+	}
+}
@@ -0,0 +1,469 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const loadTickInterval = 10 * time.Second
+
+type LoadService struct {
+	store         *storage.MemoryStore
+	tariffs       *TariffService
+	forecasts     *ForecastService
+	clock         clock.Clock
+	config        *config.Config
+	mu            sync.Mutex
+	shed          map[string]*models.ShedAction
+	gridEventEnds time.Time
+	lastRestore   time.Time
+	lastTick      time.Time
+}
+
+func NewLoadService(store *storage.MemoryStore, tariffs *TariffService, forecasts *ForecastService, clk clock.Clock, cfg *config.Config) *LoadService {
+	service := &LoadService{
+		store:     store,
+		tariffs:   tariffs,
+		forecasts: forecasts,
+		clock:     clk,
+		config:    cfg,
+		shed:      make(map[string]*models.ShedAction),
+		lastTick:  clk.Now(),
+	}
+	
+	go service.monitorLoad()
+	
+	return service
+}
+
+func (l *LoadService) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	l.shed = make(map[string]*models.ShedAction)
+	l.gridEventEnds = time.Time{}
+	l.lastRestore = time.Time{}
+}
+
+func (l *LoadService) StartGridEvent(duration time.Duration) time.Time {
+	if duration <= 0 {
+		duration = time.Duration(l.config.LoadManagement.GridEventMinutes) * time.Minute
+	}
+	
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.gridEventEnds = now.Add(duration)
+	ends := l.gridEventEnds
+	l.record("grid_event_started", "warning", fmt.Sprintf("Grid event: shedding load until %s", ends.Format("15:04")), map[string]interface{}{
+		"ends_at":          ends,
+		"duration_minutes": duration.Minutes(),
+	})
+	l.mu.Unlock()
+	
+	l.checkLoad()
+	return ends
+}
+
+func (l *LoadService) Status() models.LoadStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	now := l.clock.Now()
+	status := models.LoadStatus{
+		Enabled:       l.config.LoadManagement.Enabled,
+		PowerLimitW:   l.config.LoadManagement.PowerLimitW,
+		Reasons:       []models.ShedReason{},
+		GridEventEnds: l.gridEventEnds,
+		Shed:          []models.ShedAction{},
+		Currency:      l.tariffs.Active().Currency,
+	}
+	
+	for _, device := range l.store.ListDevices() {
+		if device.Status == models.DeviceStatusOnline {
+			status.CurrentPowerW += devicePower(device)
+		}
+	}
+	
+	if forecast := l.forecasts.Forecast(); forecast != nil {
+		status.ForecastPeakW = forecast.PeakPowerW
+		status.ForecastPeakAt = forecast.PeakAt
+	}
+	
+	peak, band := l.inPeak(now)
+	status.TariffBand = band
+	if !l.gridEventEnds.IsZero() {
+		status.Reasons = append(status.Reasons, models.ShedGridEvent)
+	}
+	if peak {
+		status.Reasons = append(status.Reasons, models.ShedPeakTariff)
+	}
+	if status.CurrentPowerW > status.PowerLimitW {
+		status.Reasons = append(status.Reasons, models.ShedPowerLimit)
+	}
+	
+	for _, action := range l.store.GetShedActions(0) {
+		if live, ok := l.shed[action.DeviceID]; ok && live.ID == action.ID {
+			action = *live
+			status.Shed = append(status.Shed, action)
+		}
+		status.SavedWh += action.SavedWh
+		status.SavedCost += action.SavedCost
+	}
+	status.SavedWh = utils.RoundToDecimal(status.SavedWh, 2)
+	status.SavedCost = utils.RoundToDecimal(status.SavedCost, 4)
+	
+	return status
+}
+
+func (l *LoadService) Actions(limit int) []models.ShedAction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	actions := l.store.GetShedActions(limit)
+	for i, action := range actions {
+		if live, ok := l.shed[action.DeviceID]; ok && live.ID == action.ID {
+			actions[i] = *live
+		}
+	}
+	return actions
+}
+
+func (l *LoadService) monitorLoad() {
+	ticker := l.clock.NewTicker(loadTickInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			l.checkLoad()
+		}
+	}
+}
+
+func (l *LoadService) checkLoad() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	cfg := l.config.LoadManagement
+	if !cfg.Enabled {
+		return
+	}
+	
+	now := l.clock.Now()
+	l.accumulateSavings(now)
+	
+	if !l.gridEventEnds.IsZero() && !now.Before(l.gridEventEnds) {
+		l.gridEventEnds = time.Time{}
+		l.record("grid_event_ended", "info", "Grid event ended, restoring load", map[string]interface{}{})
+	}
+	
+	if len(l.store.GetSafetyAlarms(true, 1)) > 0 {
+		return
+	}
+	
+	draw := 0.0
+	var candidates []*models.Device
+	for _, device := range l.store.ListDevices() {
+		if device.Status != models.DeviceStatusOnline {
+			continue
+		}
+		draw += devicePower(device)
+		if _, ok := models.ShedStrategies[device.Type]; ok && models.LoadPriority(device) > 0 {
+			candidates = append(candidates, device)
+		}
+	}
+	
+	sort.SliceStable(candidates, func(i, j int) bool {
+		pi, pj := models.LoadPriority(candidates[i]), models.LoadPriority(candidates[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return devicePower(candidates[i]) > devicePower(candidates[j])
+	})
+	
+	peak, _ := l.inPeak(now)
+	grid := !l.gridEventEnds.IsZero()
+	
+	desired := make(map[string]bool)
+	projected := draw
+	for _, device := range candidates {
+		var reason models.ShedReason
+		switch {
+		case grid:
+			reason = models.ShedGridEvent
+		case peak && models.LoadPriority(device) <= cfg.PeakMaxPriority:
+			reason = models.ShedPeakTariff
+		case projected > cfg.PowerLimitW:
+			reason = models.ShedPowerLimit
+		default:
+			continue
+		}
+		
+		desired[device.ID] = true
+		if _, shed := l.shed[device.ID]; !shed {
+			projected -= l.estimateSaving(device)
+			l.shedDevice(device, reason, now)
+		}
+	}
+	
+	l.restoreNext(desired, draw, now)
+}
+
+func (l *LoadService) restoreNext(desired map[string]bool, draw float64, now time.Time) {
+	cfg := l.config.LoadManagement
+	if now.Sub(l.lastRestore) < time.Duration(cfg.RestoreInterval)*time.Second {
+		return
+	}
+	
+	var next *models.ShedAction
+	for deviceID, action := range l.shed {
+		if desired[deviceID] {
+			continue
+		}
+		if next == nil || action.Priority > next.Priority || (action.Priority == next.Priority && action.ShedAt.Before(next.ShedAt)) {
+			next = action
+		}
+	}
+	if next == nil || l.expectedDraw(draw, now)+next.EstimatedSavingW > cfg.PowerLimitW*cfg.RestoreMargin {
+		return
+	}
+	
+	l.restore(next, now)
+	l.lastRestore = now
+}
+
+func (l *LoadService) expectedDraw(draw float64, now time.Time) float64 {
+	forecast := l.forecasts.Forecast()
+	if forecast == nil || forecast.HistoryHours == 0 {
+		return draw
+	}
+	
+	for _, point := range forecast.Hourly {
+		if point.Hour.After(now.Add(time.Hour)) {
+			break
+		}
+		draw = math.Max(draw, point.EnergyWh)
+	}
+	return draw
+}
+
+func (l *LoadService) shedDevice(device *models.Device, reason models.ShedReason, now time.Time) {
+	cfg := l.config.LoadManagement
+	strategy := models.ShedStrategies[device.Type]
+	
+	changes := make(map[string]interface{})
+	previous := make(map[string]interface{})
+	switch strategy {
+	case models.ShedDim:
+		brightness, ok := numberProperty(device, "brightness")
+		if power, _ := device.Properties["power"].(bool); power && (!ok || brightness > float64(cfg.DimBrightness)) {
+			changes["brightness"] = cfg.DimBrightness
+			previous["brightness"] = device.Properties["brightness"]
+		}
+		
+	case models.ShedDeadband:
+		deadband, ok := numberProperty(device, "deadband")
+		if !ok {
+			deadband = 1.0
+		}
+		if deadband < cfg.ShedDeadband {
+			changes["deadband"] = cfg.ShedDeadband
+			previous["deadband"] = deadband
+		}
+		
+	case models.ShedPause:
+		if power, _ := device.Properties["power"].(bool); power {
+			changes["power"] = false
+			previous["power"] = true
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+	
+	saving := l.estimateSaving(device)
+	changes["load_shed"] = true
+	previous["load_shed"] = false
+	l.store.UpdateDevice(device.ID, changes)
+	
+	price := models.EnergyUsage{Timestamp: now}
+	l.tariffs.Price(&price)
+	
+	action := &models.ShedAction{
+		ID:               utils.GenerateID("shed"),
+		DeviceID:         device.ID,
+		DeviceName:       device.Name,
+		Strategy:         strategy,
+		Reason:           reason,
+		Priority:         models.LoadPriority(device),
+		Changes:          changes,
+		Previous:         previous,
+		EstimatedSavingW: saving,
+		Rate:             price.Rate,
+		Active:           true,
+		ShedAt:           now,
+	}
+	l.shed[device.ID] = action
+	l.store.AddShedAction(*action)
+	
+	l.record("load_shed", "info", fmt.Sprintf("Shed %s (%s) for %s, saving about %.0f W", device.Name, strategy, reason, saving), map[string]interface{}{
+		"action_id":          action.ID,
+		"device_id":          device.ID,
+		"strategy":           strategy,
+		"reason":             reason,
+		"estimated_saving_w": saving,
+	})
+}
+
+func (l *LoadService) restore(action *models.ShedAction, now time.Time) {
+	if device, err := l.store.GetDevice(action.DeviceID); err == nil {
+		updates := make(map[string]interface{})
+		for key, value := range action.Previous {
+			if key == "load_shed" || fmt.Sprint(device.Properties[key]) == fmt.Sprint(action.Changes[key]) {
+				updates[key] = value
+			}
+		}
+		l.store.UpdateDevice(action.DeviceID, updates)
+	}
+	
+	delete(l.shed, action.DeviceID)
+	action.Active = false
+	action.RestoredAt = now
+	l.store.UpdateShedAction(*action)
+	
+	l.record("load_restored", "info", fmt.Sprintf("Restored %s after %s", action.DeviceName, now.Sub(action.ShedAt).Round(time.Second)), map[string]interface{}{
+		"action_id":  action.ID,
+		"device_id":  action.DeviceID,
+		"saved_wh":   action.SavedWh,
+		"saved_cost": action.SavedCost,
+	})
+}
+
+func (l *LoadService) estimateSaving(device *models.Device) float64 {
+	cfg := l.config.LoadManagement
+	
+	switch models.ShedStrategies[device.Type] {
+	case models.ShedDim:
+		if power, _ := device.Properties["power"].(bool); !power {
+			return 0
+		}
+		return math.Max(devicePower(device)-float64(cfg.DimBrightness), 0)
+		
+	case models.ShedDeadband:
+		heating, _ := device.Properties["heating"].(bool)
+		cooling, _ := device.Properties["cooling"].(bool)
+		temperature, _ := numberProperty(device, "temperature")
+		target, _ := numberProperty(device, "target_temp")
+		if (heating || cooling) && math.Abs(target-temperature) <= cfg.ShedDeadband {
+			return devicePower(device) - 100
+		}
+		
+	case models.ShedPause:
+		if power, _ := device.Properties["power"].(bool); power {
+			return devicePower(device) - devicePower(withProperty(device, "power", false))
+		}
+	}
+	
+	return 0
+}
+
+func (l *LoadService) accumulateSavings(now time.Time) {
+	hours := now.Sub(l.lastTick).Hours()
+	l.lastTick = now
+	if hours <= 0 {
+		return
+	}
+	
+	price := models.EnergyUsage{Timestamp: now}
+	l.tariffs.Price(&price)
+	
+	for _, action := range l.shed {
+		device, err := l.store.GetDevice(action.DeviceID)
+		if err != nil || device.Status != models.DeviceStatusOnline {
+			action.EstimatedSavingW = 0
+			continue
+		}
+		
+		action.EstimatedSavingW = l.avoidedPower(action, device)
+		saved := action.EstimatedSavingW * hours
+		action.SavedWh = utils.RoundToDecimal(action.SavedWh+saved, 4)
+		action.SavedCost = utils.RoundToDecimal(action.SavedCost+saved/1000*price.Rate, 6)
+		action.Rate = price.Rate
+	}
+}
+
+func (l *LoadService) avoidedPower(action *models.ShedAction, device *models.Device) float64 {
+	baseline := devicePower(device)
+	
+	switch action.Strategy {
+	case models.ShedDim:
+		if previous, ok := numberValue(action.Previous["brightness"]); ok && baseline > 0 {
+			baseline = previous
+		}
+		
+	case models.ShedDeadband:
+		deadband, _ := numberValue(action.Previous["deadband"])
+		temperature, _ := numberProperty(device, "temperature")
+		target, _ := numberProperty(device, "target_temp")
+		if mode, _ := device.Properties["mode"].(string); mode != "off" {
+			if target-temperature > deadband {
+				baseline = 2500
+			} else if target-temperature < -deadband {
+				baseline = 3000
+			}
+		}
+		
+	case models.ShedPause:
+		if power, _ := device.Properties["power"].(bool); !power {
+			baseline = devicePower(withProperty(device, "power", true))
+		}
+	}
+	
+	return math.Max(baseline-devicePower(device), 0)
+}
+
+func withProperty(device *models.Device, name string, value interface{}) *models.Device {
+	copied := *device
+	copied.Properties = make(map[string]interface{}, len(device.Properties))
+	for key, v := range device.Properties {
+		copied.Properties[key] = v
+	}
+	copied.Properties[name] = value
+	return &copied
+}
+
+func (l *LoadService) inPeak(now time.Time) (bool, string) {
+	price := models.EnergyUsage{Timestamp: now}
+	l.tariffs.Price(&price)
+	
+	if !l.config.LoadManagement.ShedOnPeak {
+		return false, price.Band
+	}
+	for _, band := range l.config.LoadManagement.PeakBands {
+		if band == price.Band {
+			return true, price.Band
+		}
+	}
+	return false, price.Band
+}
+
+func (l *LoadService) record(eventType, severity, message string, data map[string]interface{}) {
+	l.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID(eventType),
+		Type:      eventType,
+		Source:    "load_manager",
+		Message:   message,
+		Data:      data,
+		Timestamp: l.clock.Now(),
+		Severity:  severity,
+	})
+}
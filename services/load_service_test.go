@@ -0,0 +1,164 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestLoads(t *testing.T) (*LoadService, *storage.MemoryStore, *clock.Simulated) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	cfg.Tariff = "flat"
+	cfg.LoadManagement.PowerLimitW = 4000
+	cfg.LoadManagement.RestoreInterval = 60
+	// Keep the background monitors idle; tests check the load explicitly.
+	cfg.EnergyUpdateInterval = 30 * 24 * 3600
+	
+	for _, device := range []*models.Device{
+		{ID: "plug_1", Name: "Heater Plug", Type: models.DeviceTypeSmartPlug, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": true, "load_w": 2500.0}},
+		{ID: "light_1", Name: "Hall Light", Type: models.DeviceTypeLight, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"power": true, "brightness": 100}},
+		{ID: "thermostat_1", Name: "Thermostat", Type: models.DeviceTypeThermostat, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"mode": "heat", "heating": true, "cooling": false, "temperature": 20.0, "target_temp": 21.0, "deadband": 1.0}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	tariffs := NewTariffService(clk, cfg)
+	forecasts := NewForecastService(store, NewWeatherService(clk, random.NewSource(1), cfg), tariffs, clk, cfg)
+	return NewLoadService(store, tariffs, forecasts, clk, cfg), store, clk
+}
+
+func activeShed(loads *LoadService) map[string]models.ShedAction {
+	shed := make(map[string]models.ShedAction)
+	for _, action := range loads.Status().Shed {
+		shed[action.DeviceID] = action
+	}
+	return shed
+}
+
+func TestShedsLowestPriorityUntilUnderLimit(t *testing.T) {
+	loads, store, clk := newTestLoads(t)
+	
+	loads.checkLoad()
+	
+	shed := activeShed(loads)
+	if len(shed) != 1 || shed["plug_1"].Reason != models.ShedPowerLimit || shed["plug_1"].Strategy != models.ShedPause {
+		t.Fatalf("shed = %+v, want only the plug paused for the power limit", shed)
+	}
+	assertProperty(t, store, "plug_1", "power", false)
+	assertProperty(t, store, "plug_1", "load_shed", true)
+	assertProperty(t, store, "light_1", "brightness", 100)
+	
+	// The plug stays off while the draw would exceed the limit again.
+	clk.Step(time.Minute)
+	loads.checkLoad()
+	if _, ok := activeShed(loads)["plug_1"]; !ok {
+		t.Fatal("plug restored while the thermostat is still heating")
+	}
+	
+	store.UpdateDevice("thermostat_1", map[string]interface{}{"heating": false})
+	loads.checkLoad()
+	if len(activeShed(loads)) != 0 {
+		t.Fatal("plug not restored once there was room under the limit")
+	}
+	assertProperty(t, store, "plug_1", "power", true)
+	assertProperty(t, store, "plug_1", "load_shed", false)
+	
+	actions := loads.Actions(0)
+	if len(actions) != 1 || actions[0].Active || actions[0].RestoredAt.IsZero() || actions[0].SavedWh <= 0 {
+		t.Errorf("actions = %+v, want one restored action with savings", actions)
+	}
+}
+
+func TestGridEventShedsAllAndRestoresOneAtATime(t *testing.T) {
+	loads, store, clk := newTestLoads(t)
+	store.UpdateDevice("thermostat_1", map[string]interface{}{"heating": false})
+	store.UpdateDevice("plug_1", map[string]interface{}{"load_w": 500.0})
+	
+	loads.StartGridEvent(10 * time.Minute)
+	
+	shed := activeShed(loads)
+	if len(shed) != 3 {
+		t.Fatalf("shed = %+v, want every sheddable device", shed)
+	}
+	for _, action := range shed {
+		if action.Reason != models.ShedGridEvent {
+			t.Errorf("%s shed for %s, want grid_event", action.DeviceID, action.Reason)
+		}
+	}
+	assertProperty(t, store, "light_1", "brightness", 30)
+	assertProperty(t, store, "thermostat_1", "deadband", 3.0)
+	
+	// A user change while shed is kept on restore.
+	store.UpdateDevice("light_1", map[string]interface{}{"brightness": 60})
+	
+	clk.Step(10 * time.Minute)
+	var restored []string
+	for i := 0; i < 3; i++ {
+		loads.checkLoad()
+		before := len(restored)
+		for _, id := range []string{"thermostat_1", "light_1", "plug_1"} {
+			if _, ok := activeShed(loads)[id]; !ok && !contains(restored, id) {
+				restored = append(restored, id)
+			}
+		}
+		if len(restored) != before+1 {
+			t.Fatalf("check %d restored %v, want one more device", i+1, restored[before:])
+		}
+		clk.Step(time.Minute)
+	}
+	
+	want := []string{"thermostat_1", "light_1", "plug_1"}
+	for i := range want {
+		if restored[i] != want[i] {
+			t.Fatalf("restore order = %v, want highest priority first %v", restored, want)
+		}
+	}
+	assertProperty(t, store, "light_1", "brightness", 60)
+	assertProperty(t, store, "thermostat_1", "deadband", 1.0)
+	assertProperty(t, store, "plug_1", "power", true)
+}
+
+func TestSimulatorLeavesShedDevicesAlone(t *testing.T) {
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	devices, store := newTestDevices(t, cfg)
+	
+	store.UpdateDevice("light_001", map[string]interface{}{"power": true, "brightness": 30, "load_shed": true})
+	for i := 0; i < 100; i++ {
+		device, _ := store.GetDevice("light_001")
+		devices.simulateDeviceChange(device)
+	}
+	assertProperty(t, store, "light_001", "brightness", 30)
+	assertProperty(t, store, "light_001", "power", true)
+	
+	store.UpdateDevice("light_001", map[string]interface{}{"load_shed": false})
+	for i := 0; i < 100; i++ {
+		device, _ := store.GetDevice("light_001")
+		devices.simulateDeviceChange(device)
+	}
+	if device, _ := store.GetDevice("light_001"); device.Properties["brightness"] == 30 && device.Properties["power"] == true {
+		t.Error("simulator never changed the light once it was no longer shed")
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
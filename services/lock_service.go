@@ -0,0 +1,406 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const (
+	lockTickInterval     = 10 * time.Second
+	lockOperationDrain   = 0.1
+	lockIdleDrainPerHour = 0.02
+	criticalBatteryLevel = 5
+)
+
+var ErrLockFailed = errors.New("lock operation failed")
+
+type doorWatch struct {
+	openedAt time.Time
+	level    int
+}
+
+type LockService struct {
+	store     *storage.MemoryStore
+	notifier  *NotificationService
+	clock     clock.Clock
+	random    *random.Source
+	config    *config.Config
+	mu        sync.Mutex
+	timers    map[string]clock.Timer
+	doors     map[string]*doorWatch
+	batteries map[string]int
+	dead      map[string]bool
+	drain     map[string]float64
+	lastTick  time.Time
+}
+
+func NewLockService(store *storage.MemoryStore, notifier *NotificationService, clk clock.Clock, src *random.Source, cfg *config.Config) *LockService {
+	service := &LockService{
+		store:     store,
+		notifier:  notifier,
+		clock:     clk,
+		random:    src,
+		config:    cfg,
+		timers:    make(map[string]clock.Timer),
+		doors:     make(map[string]*doorWatch),
+		batteries: make(map[string]int),
+		dead:      make(map[string]bool),
+		drain:     make(map[string]float64),
+		lastTick:  clk.Now(),
+	}
+	
+	go service.monitorLocks()
+	
+	return service
+}
+
+func (l *LockService) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	for _, timer := range l.timers {
+		timer.Stop()
+	}
+	l.timers = make(map[string]clock.Timer)
+	l.doors = make(map[string]*doorWatch)
+	l.batteries = make(map[string]int)
+	l.dead = make(map[string]bool)
+	l.drain = make(map[string]float64)
+}
+
+func (l *LockService) Operate(lockID string, locked bool, source string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	return l.operate(lockID, locked, source)
+}
+
+func (l *LockService) operate(lockID string, locked bool, source string) error {
+	lock, err := l.store.GetDevice(lockID)
+	if err != nil {
+		return err
+	}
+	if lock.Type != models.DeviceTypeLock {
+		return fmt.Errorf("device %s is not a lock", lockID)
+	}
+	
+	action := "unlock"
+	if locked {
+		action = "lock"
+	}
+	
+	battery, _ := numberProperty(lock, "battery_level")
+	if battery <= 0 {
+		return l.fail(lock, action, source, "battery depleted")
+	}
+	
+	if locked {
+		if door := l.pairedDoor(lock); door != nil && models.SensorOpen(door) {
+			return l.fail(lock, action, source, fmt.Sprintf("%s is open", door.Name))
+		}
+	}
+	
+	battery = utils.RoundToDecimal(utils.LimitValue(battery-lockOperationDrain, 0, 100), 2)
+	
+	if l.lockRand(lockID).Float64() < l.config.Locks.JamChance {
+		l.cancelAutoLock(lockID)
+		l.store.UpdateDevice(lockID, map[string]interface{}{
+			"jammed":        true,
+			"battery_level": battery,
+		})
+		l.alert(lock, "lock_jammed", "critical", fmt.Sprintf("%s jammed while trying to %s", lock.Name, action), map[string]interface{}{
+			"action": action,
+			"source": source,
+		})
+		l.checkBattery(lock, battery)
+		return fmt.Errorf("%w: %s jammed", ErrLockFailed, lock.Name)
+	}
+	
+	l.store.UpdateDevice(lockID, map[string]interface{}{
+		"locked":        locked,
+		"jammed":        false,
+		"battery_level": battery,
+	})
+	
+	if locked {
+		l.cancelAutoLock(lockID)
+	} else {
+		l.scheduleAutoLock(lock)
+	}
+	
+	l.checkBattery(lock, battery)
+	return nil
+}
+
+func (l *LockService) DoorChanged(sensorID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	sensor, err := l.store.GetDevice(sensorID)
+	if err != nil {
+		return
+	}
+	
+	for _, lock := range l.pairedLocks(sensorID) {
+		if models.SensorOpen(sensor) {
+			l.cancelAutoLock(lock.ID)
+			if _, ok := l.doors[sensorID]; !ok {
+				l.doors[sensorID] = &doorWatch{openedAt: l.clock.Now()}
+			}
+			continue
+		}
+		
+		if watch, ok := l.doors[sensorID]; ok && watch.level > 0 {
+			l.alert(lock, "door_closed", "info", fmt.Sprintf("%s closed", sensor.Name), map[string]interface{}{
+				"sensor_id":    sensorID,
+				"open_seconds": int(l.clock.Since(watch.openedAt).Seconds()),
+			})
+		}
+		delete(l.doors, sensorID)
+		
+		if locked, _ := lock.Properties["locked"].(bool); !locked {
+			l.scheduleAutoLock(lock)
+		}
+	}
+}
+
+func (l *LockService) BatteryReplaced(lockID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	lock, err := l.store.GetDevice(lockID)
+	if err != nil {
+		return
+	}
+	
+	battery, _ := numberProperty(lock, "battery_level")
+	if battery > float64(l.config.Locks.LowBattery) {
+		delete(l.batteries, lockID)
+	}
+	if battery > 0 && l.dead[lockID] {
+		delete(l.dead, lockID)
+		l.store.UpdateDevice(lockID, map[string]interface{}{
+			"status": string(models.DeviceStatusOnline),
+		})
+	}
+}
+
+func (l *LockService) scheduleAutoLock(lock *models.Device) {
+	l.cancelAutoLock(lock.ID)
+	
+	if enabled, _ := lock.Properties["auto_lock"].(bool); !enabled {
+		return
+	}
+	if door := l.pairedDoor(lock); door != nil && models.SensorOpen(door) {
+		return
+	}
+	
+	delay := time.Duration(l.config.Locks.AutoLockDelay) * time.Second
+	if seconds, ok := numberProperty(lock, "auto_lock_delay"); ok && seconds > 0 {
+		delay = time.Duration(seconds * float64(time.Second))
+	}
+	
+	lockID := lock.ID
+	var timer clock.Timer
+	timer = l.clock.AfterFunc(delay, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		
+		if l.timers[lockID] != timer {
+			return
+		}
+		delete(l.timers, lockID)
+		
+		current, err := l.store.GetDevice(lockID)
+		if err != nil {
+			return
+		}
+		if locked, _ := current.Properties["locked"].(bool); locked {
+			return
+		}
+		l.operate(lockID, true, "auto_lock")
+	})
+	l.timers[lockID] = timer
+}
+
+func (l *LockService) cancelAutoLock(lockID string) {
+	if timer, ok := l.timers[lockID]; ok {
+		timer.Stop()
+		delete(l.timers, lockID)
+	}
+}
+
+func (l *LockService) monitorLocks() {
+	ticker := l.clock.NewTicker(lockTickInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			l.checkLocks()
+		}
+	}
+}
+
+func (l *LockService) checkLocks() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	
+	now := l.clock.Now()
+	elapsed := now.Sub(l.lastTick).Hours()
+	l.lastTick = now
+	
+	for _, lock := range l.store.ListDevices() {
+		if lock.Type != models.DeviceTypeLock {
+			continue
+		}
+		
+		l.drain[lock.ID] += elapsed * lockIdleDrainPerHour
+		if battery, ok := numberProperty(lock, "battery_level"); ok && battery > 0 && l.drain[lock.ID] >= 0.01 {
+			battery = utils.RoundToDecimal(utils.LimitValue(battery-l.drain[lock.ID], 0, 100), 2)
+			delete(l.drain, lock.ID)
+			l.store.UpdateDevice(lock.ID, map[string]interface{}{
+				"battery_level": battery,
+			})
+			l.checkBattery(lock, battery)
+		}
+		
+		door := l.pairedDoor(lock)
+		if door == nil || !models.SensorOpen(door) {
+			continue
+		}
+		
+		watch, ok := l.doors[door.ID]
+		if !ok {
+			watch = &doorWatch{openedAt: now}
+			l.doors[door.ID] = watch
+		}
+		
+		open := now.Sub(watch.openedAt)
+		data := map[string]interface{}{
+			"sensor_id":    door.ID,
+			"open_seconds": int(open.Seconds()),
+		}
+		
+		if watch.level < 2 && open >= time.Duration(l.config.Locks.DoorOpenEscalate)*time.Second {
+			watch.level = 2
+			l.alert(lock, "door_left_open_escalated", "critical", fmt.Sprintf("%s still open after %s", door.Name, utils.FormatDuration(open)), data)
+			l.notifier.Notify("sms", "critical", fmt.Sprintf("%s still open after %s", door.Name, utils.FormatDuration(open)), data)
+		} else if watch.level < 1 && open >= time.Duration(l.config.Locks.DoorOpenAlert)*time.Second {
+			watch.level = 1
+			l.alert(lock, "door_left_open", "warning", fmt.Sprintf("%s left open for %s", door.Name, utils.FormatDuration(open)), data)
+		}
+	}
+}
+
+func (l *LockService) checkBattery(lock *models.Device, battery float64) {
+	level := 0
+	switch {
+	case battery <= 0:
+		level = 3
+	case battery <= criticalBatteryLevel:
+		level = 2
+	case battery <= float64(l.config.Locks.LowBattery):
+		level = 1
+	}
+	
+	if level <= l.batteries[lock.ID] {
+		return
+	}
+	l.batteries[lock.ID] = level
+	
+	data := map[string]interface{}{
+		"battery_level": battery,
+	}
+	
+	switch level {
+	case 1:
+		l.alert(lock, "lock_battery_low", "warning", fmt.Sprintf("%s battery low (%.0f%%)", lock.Name, battery), data)
+	case 2:
+		l.alert(lock, "lock_battery_critical", "critical", fmt.Sprintf("%s battery critical (%.0f%%), replace it now", lock.Name, battery), data)
+	case 3:
+		l.dead[lock.ID] = true
+		l.cancelAutoLock(lock.ID)
+		l.store.UpdateDevice(lock.ID, map[string]interface{}{
+			"status": string(models.DeviceStatusOffline),
+		})
+		l.alert(lock, "lock_battery_dead", "critical", fmt.Sprintf("%s battery depleted, lock is offline", lock.Name), data)
+	}
+}
+
+func (l *LockService) fail(lock *models.Device, action, source, reason string) error {
+	l.alert(lock, "lock_failed", "warning", fmt.Sprintf("%s failed to %s: %s", lock.Name, action, reason), map[string]interface{}{
+		"action": action,
+		"source": source,
+		"reason": reason,
+	})
+	return fmt.Errorf("%w: %s", ErrLockFailed, reason)
+}
+
+func (l *LockService) alert(lock *models.Device, eventType, severity, message string, data map[string]interface{}) {
+	data["lock_id"] = lock.ID
+	
+	l.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID(eventType),
+		Type:      eventType,
+		Source:    "locks",
+		Message:   message,
+		Data:      data,
+		Timestamp: l.clock.Now(),
+		Severity:  severity,
+	})
+	l.notifier.Notify("push", severity, message, data)
+}
+
+func (l *LockService) pairedDoor(lock *models.Device) *models.Device {
+	sensorID, _ := lock.Properties["door_sensor"].(string)
+	if sensorID == "" {
+		return nil
+	}
+	
+	door, err := l.store.GetDevice(sensorID)
+	if err != nil {
+		return nil
+	}
+	return door
+}
+
+func (l *LockService) pairedLocks(sensorID string) []*models.Device {
+	var locks []*models.Device
+	for _, device := range l.store.ListDevices() {
+		if device.Type != models.DeviceTypeLock {
+			continue
+		}
+		if paired, _ := device.Properties["door_sensor"].(string); paired == sensorID {
+			locks = append(locks, device)
+		}
+	}
+	return locks
+}
+
+func (l *LockService) lockRand(id string) *random.Stream {
+	return l.random.Stream("lock/" + id)
+}
+
+func numberProperty(device *models.Device, name string) (float64, bool) {
+	return numberValue(device.Properties[name])
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestLocks(t *testing.T) (*LockService, *storage.MemoryStore, *clock.Simulated, *config.Config) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.Locks.AutoLockDelay = 30
+	cfg.Locks.JamChance = 0
+	
+	for _, device := range []*models.Device{
+		{ID: "lock_1", Name: "Front Lock", Type: models.DeviceTypeLock, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"locked": true, "auto_lock": true, "battery_level": 90.0, "door_sensor": "door_1"}},
+		{ID: "door_1", Name: "Front Door", Type: models.DeviceTypeSensor, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"open": false}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	locks := NewLockService(store, NewNotificationService(store, clk), clk, random.NewSource(1), cfg)
+	return locks, store, clk, cfg
+}
+
+func assertLocked(t *testing.T, store *storage.MemoryStore, want bool) {
+	t.Helper()
+	lock, _ := store.GetDevice("lock_1")
+	if locked, _ := lock.Properties["locked"].(bool); locked != want {
+		t.Errorf("locked = %v, want %v", locked, want)
+	}
+}
+
+func setDoor(t *testing.T, locks *LockService, store *storage.MemoryStore, open bool) {
+	t.Helper()
+	if err := store.UpdateDevice("door_1", map[string]interface{}{"open": open}); err != nil {
+		t.Fatalf("update door: %v", err)
+	}
+	locks.DoorChanged("door_1")
+}
+
+func TestAutoLockAfterDelay(t *testing.T) {
+	locks, store, clk, _ := newTestLocks(t)
+	
+	if err := locks.Operate("lock_1", false, "manual"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	assertLocked(t, store, false)
+	
+	clk.Step(29 * time.Second)
+	assertLocked(t, store, false)
+	
+	clk.Step(time.Second)
+	assertLocked(t, store, true)
+}
+
+func TestAutoLockHonoursPerLockDelay(t *testing.T) {
+	locks, store, clk, _ := newTestLocks(t)
+	store.UpdateDevice("lock_1", map[string]interface{}{"auto_lock_delay": 90.0})
+	
+	locks.Operate("lock_1", false, "manual")
+	clk.Step(time.Minute)
+	assertLocked(t, store, false)
+	
+	clk.Step(30 * time.Second)
+	assertLocked(t, store, true)
+}
+
+func TestAutoLockWaitsForDoorToClose(t *testing.T) {
+	locks, store, clk, _ := newTestLocks(t)
+	
+	locks.Operate("lock_1", false, "manual")
+	setDoor(t, locks, store, true)
+	clk.Step(time.Minute)
+	assertLocked(t, store, false)
+	
+	setDoor(t, locks, store, false)
+	clk.Step(29 * time.Second)
+	assertLocked(t, store, false)
+	clk.Step(time.Second)
+	assertLocked(t, store, true)
+}
+
+func TestLockRefusesWhileDoorOpen(t *testing.T) {
+	locks, store, _, _ := newTestLocks(t)
+	
+	locks.Operate("lock_1", false, "manual")
+	setDoor(t, locks, store, true)
+	
+	if err := locks.Operate("lock_1", true, "manual"); !errors.Is(err, ErrLockFailed) {
+		t.Fatalf("lock with the door open: error = %v, want a lock failure", err)
+	}
+	assertLocked(t, store, false)
+	if got := countEvents(store, "lock_failed"); got != 1 {
+		t.Errorf("lock_failed events = %d, want 1", got)
+	}
+}
+
+func TestJamLeavesLockInPlace(t *testing.T) {
+	locks, store, clk, cfg := newTestLocks(t)
+	
+	locks.Operate("lock_1", false, "manual")
+	cfg.Locks.JamChance = 1
+	
+	// The auto-lock attempt jams and the lock stays unlocked.
+	clk.Step(30 * time.Second)
+	assertLocked(t, store, false)
+	lock, _ := store.GetDevice("lock_1")
+	if jammed, _ := lock.Properties["jammed"].(bool); !jammed {
+		t.Error("lock not marked jammed")
+	}
+	if got := countEvents(store, "lock_jammed"); got != 1 {
+		t.Errorf("lock_jammed events = %d, want 1", got)
+	}
+	
+	if err := locks.Operate("lock_1", true, "manual"); !errors.Is(err, ErrLockFailed) {
+		t.Errorf("lock while jamming: error = %v, want a lock failure", err)
+	}
+	
+	cfg.Locks.JamChance = 0
+	if err := locks.Operate("lock_1", true, "manual"); err != nil {
+		t.Fatalf("lock after clearing the jam: %v", err)
+	}
+	assertLocked(t, store, true)
+	lock, _ = store.GetDevice("lock_1")
+	if jammed, _ := lock.Properties["jammed"].(bool); jammed {
+		t.Error("jammed flag not cleared by a successful operation")
+	}
+}
+
+func TestDepletedBatteryTakesLockOffline(t *testing.T) {
+	locks, store, _, _ := newTestLocks(t)
+	store.UpdateDevice("lock_1", map[string]interface{}{"battery_level": 0.05})
+	
+	if err := locks.Operate("lock_1", false, "manual"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	lock, _ := store.GetDevice("lock_1")
+	if lock.Status != models.DeviceStatusOffline {
+		t.Errorf("status = %s, want offline", lock.Status)
+	}
+	if got := countEvents(store, "lock_battery_dead"); got != 1 {
+		t.Errorf("lock_battery_dead events = %d, want 1", got)
+	}
+	
+	if err := locks.Operate("lock_1", true, "manual"); !errors.Is(err, ErrLockFailed) {
+		t.Errorf("lock with a dead battery: error = %v, want a lock failure", err)
+	}
+}
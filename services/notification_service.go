@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+type NotificationService struct {
+	store     *storage.MemoryStore
+	clock     clock.Clock
+	mu        sync.Mutex
+	listeners []func(models.Notification)
+}
+
+func NewNotificationService(store *storage.MemoryStore, clk clock.Clock) *NotificationService {
+	return &NotificationService{
+		store: store,
+		clock: clk,
+	}
+}
+
+func (n *NotificationService) OnNotify(listener func(models.Notification)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	
+	n.listeners = append(n.listeners, listener)
+}
+
+func (n *NotificationService) Notify(channel, severity, message string, data map[string]interface{}) models.Notification {
+	notification := models.Notification{
+		ID:        utils.GenerateID("notification"),
+		Channel:   channel,
+		Severity:  severity,
+		Message:   message,
+		Data:      data,
+		Timestamp: n.clock.Now(),
+	}
+	
+	n.store.AddNotification(notification)
+	n.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("notification"),
+		Type:      "notification_sent",
+		Source:    "notifications",
+		Message:   fmt.Sprintf("[%s] %s", channel, message),
+		Data:      data,
+		Timestamp: notification.Timestamp,
+		Severity:  severity,
+	})
+	
+	n.mu.Lock()
+	listeners := n.listeners
+	n.mu.Unlock()
+	
+	for _, listener := range listeners {
+		go listener(notification)
+	}
+	
+	return notification
+}
+
+func (n *NotificationService) GetNotifications(limit int) []models.Notification {
+	return n.store.GetNotifications(limit)
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+var securityTransitions = map[models.SecurityState][]models.SecurityState{
+	models.SecurityStateDisarmed:  {models.SecurityStateArming, models.SecurityStateArmed, models.SecurityStateTriggered},
+	models.SecurityStateArming:    {models.SecurityStateArmed, models.SecurityStateTriggered, models.SecurityStateDisarmed},
+	models.SecurityStateArmed:     {models.SecurityStatePending, models.SecurityStateTriggered, models.SecurityStateDisarmed},
+	models.SecurityStatePending:   {models.SecurityStateTriggered, models.SecurityStateDisarmed},
+	models.SecurityStateTriggered: {models.SecurityStateDisarmed, models.SecurityStateArmed},
+}
+
+type securityNotification struct {
+	transition models.SecurityTransition
+	security   models.SecuritySystem
+}
+
+type SecurityService struct {
+	store       *storage.MemoryStore
+	clock       clock.Clock
+	config      *config.Config
+	mu          sync.Mutex
+	timer       clock.Timer
+	listeners   []func(models.SecurityTransition, models.SecuritySystem)
+	pending     []securityNotification
+	dispatching bool
+	trips       []sensorTrip
+	tripCounts  map[string]int
+}
+
+func NewSecurityService(store *storage.MemoryStore, clk clock.Clock, cfg *config.Config) *SecurityService {
+	return &SecurityService{
+		store:      store,
+		clock:      clk,
+		config:     cfg,
+		tripCounts: make(map[string]int),
+	}
+}
+
+func (s *SecurityService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.pending = nil
+	s.trips = nil
+	s.tripCounts = make(map[string]int)
+}
+
+func (s *SecurityService) OnTransition(listener func(models.SecurityTransition, models.SecuritySystem)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *SecurityService) GetState() models.SecuritySystem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	return *s.store.GetSecurity()
+}
+
+func (s *SecurityService) Arm(source string, mode models.ArmMode, bypass bool) (models.SecuritySystem, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	
+	security := *s.store.GetSecurity()
+	if security.State != models.SecurityStateDisarmed {
+		return security, fmt.Errorf("cannot arm security system while %s", security.State)
+	}
+	
+	if mode == "" {
+		mode = models.ArmModeAway
+	}
+	
+	policy, ok := models.ArmModePolicies[mode]
+	if !ok {
+		return security, fmt.Errorf("unknown arm mode: %s", mode)
+	}
+	
+	active := make([]string, 0)
+	open := make([]string, 0)
+	for _, device := range s.store.ListDevices() {
+		if !models.IsSecuritySensor(device.Type) || device.Status != models.DeviceStatusOnline {
+			continue
+		}
+		
+		zone := models.SensorZone(device)
+		if !policy.Includes(zone) {
+			continue
+		}
+		
+		if zone == models.SecurityZonePerimeter && models.SensorOpen(device) {
+			open = append(open, device.ID)
+			continue
+		}
+		
+		active = append(active, device.ID)
+	}
+	
+	if len(open) > 0 && !bypass {
+		return security, fmt.Errorf("perimeter sensors open: %s; arm with bypass to exclude them", strings.Join(open, ", "))
+	}
+	
+	s.resetVerification()
+	
+	security.Mode = mode
+	security.ActiveSensors = active
+	security.BypassedSensors = open
+	security.TriggeredBy = ""
+	
+	exitDelay := time.Duration(s.config.ExitDelay) * time.Second
+	if exitDelay <= 0 {
+		security.LastArmed = s.clock.Now()
+		return s.transition(security, models.SecurityStateArmed, "armed", source)
+	}
+	
+	security.DelayEndsAt = s.clock.Now().Add(exitDelay)
+	security, err := s.transition(security, models.SecurityStateArming, "exit_delay_started", source)
+	if err != nil {
+		return security, err
+	}
+	
+	s.schedule(exitDelay, models.SecurityStateArming, func(current models.SecuritySystem) {
+		current.LastArmed = s.clock.Now()
+		s.transition(current, models.SecurityStateArmed, "exit_delay_elapsed", "security")
+	})
+	
+	return security, nil
+}
+
+func (s *SecurityService) Disarm(source string) (models.SecuritySystem, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	
+	security := *s.store.GetSecurity()
+	if security.State == models.SecurityStateDisarmed {
+		return security, fmt.Errorf("security system is already disarmed")
+	}
+	
+	s.resetVerification()
+	
+	security.ActiveSensors = []string{}
+	security.BypassedSensors = []string{}
+	security.Mode = ""
+	return s.transition(security, models.SecurityStateDisarmed, "disarmed", source)
+}
+
+func (s *SecurityService) SensorTripped(sensorID string) (models.SecuritySystem, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	
+	security := *s.store.GetSecurity()
+	device, err := s.store.GetDevice(sensorID)
+	if err != nil {
+		return security, err
+	}
+	
+	zone := models.SensorZone(device)
+	if models.IsAlwaysActiveZone(zone) {
+		if security.State == models.SecurityStateTriggered {
+			return security, nil
+		}
+		security.TriggeredBy = sensorID
+		return s.trigger(security, fmt.Sprintf("%s_zone_tripped", zone), sensorID)
+	}
+	
+	if security.State != models.SecurityStateArmed || !utils.StringInSlice(sensorID, security.ActiveSensors) {
+		return security, nil
+	}
+	
+	if !s.verifyTrip(&security, device) {
+		return security, nil
+	}
+	
+	security.TriggeredBy = sensorID
+	
+	entryDelay := time.Duration(s.config.EntryDelay) * time.Second
+	if entryDelay <= 0 || !models.ArmModePolicies[security.Mode].EntryDelay {
+		return s.trigger(security, "sensor_tripped", sensorID)
+	}
+	
+	security.DelayEndsAt = s.clock.Now().Add(entryDelay)
+	security, err = s.transition(security, models.SecurityStatePending, "entry_delay_started", sensorID)
+	if err != nil {
+		return security, err
+	}
+	
+	s.schedule(entryDelay, models.SecurityStatePending, func(current models.SecuritySystem) {
+		s.trigger(current, "entry_delay_elapsed", current.TriggeredBy)
+	})
+	
+	return security, nil
+}
+
+func (s *SecurityService) Trigger(source string) (models.SecuritySystem, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	
+	security := *s.store.GetSecurity()
+	security.TriggeredBy = source
+	return s.trigger(security, "triggered", source)
+}
+
+func (s *SecurityService) trigger(security models.SecuritySystem, cause, source string) (models.SecuritySystem, error) {
+	security.LastTriggered = s.clock.Now()
+	security, err := s.transition(security, models.SecurityStateTriggered, cause, source)
+	if err != nil {
+		return security, err
+	}
+	
+	s.schedule(time.Duration(s.config.SecurityTimeout)*time.Second, models.SecurityStateTriggered, func(current models.SecuritySystem) {
+		if current.Mode == "" {
+			s.transition(current, models.SecurityStateDisarmed, "alarm_timeout", "security")
+			return
+		}
+		current.LastArmed = s.clock.Now()
+		s.transition(current, models.SecurityStateArmed, "alarm_timeout", "security")
+	})
+	
+	return security, nil
+}
+
+func (s *SecurityService) schedule(delay time.Duration, expected models.SecurityState, fn func(models.SecuritySystem)) {
+	var timer clock.Timer
+	timer = s.clock.AfterFunc(delay, func() {
+		s.mu.Lock()
+		defer s.unlock()
+		
+		if s.timer != timer {
+			return
+		}
+		s.timer = nil
+		
+		current := *s.store.GetSecurity()
+		if current.State != expected {
+			return
+		}
+		fn(current)
+	})
+	s.timer = timer
+}
+
+func (s *SecurityService) transition(security models.SecuritySystem, to models.SecurityState, cause, source string) (models.SecuritySystem, error) {
+	from := security.State
+	if !canTransition(from, to) {
+		return security, fmt.Errorf("invalid security transition from %s to %s", from, to)
+	}
+	
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	
+	now := s.clock.Now()
+	security.State = to
+	security.StateChangedAt = now
+	if to != models.SecurityStateArming && to != models.SecurityStatePending {
+		security.DelayEndsAt = time.Time{}
+	}
+	
+	updated := security
+	s.store.UpdateSecurity(&updated)
+	
+	event := models.SecurityTransition{
+		From:      from,
+		To:        to,
+		Cause:     cause,
+		Source:    source,
+		Timestamp: now,
+	}
+	
+	severity := "info"
+	if to == models.SecurityStateTriggered {
+		severity = "critical"
+	} else if to == models.SecurityStatePending {
+		severity = "warning"
+	}
+	
+	s.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("security_transition"),
+		Type:      "security_transition",
+		Source:    "security",
+		Message:   fmt.Sprintf("Security system %s -> %s (%s)", from, to, cause),
+		Data: map[string]interface{}{
+			"from":   from,
+			"to":     to,
+			"cause":  cause,
+			"source": source,
+		},
+		Timestamp: now,
+		Severity:  severity,
+	})
+	
+	s.pending = append(s.pending, securityNotification{transition: event, security: security})
+	
+	return security, nil
+}
+
+func (s *SecurityService) unlock() {
+	if s.dispatching {
+		s.mu.Unlock()
+		return
+	}
+	
+	s.dispatching = true
+	for len(s.pending) > 0 {
+		pending := s.pending
+		s.pending = nil
+		listeners := s.listeners
+		s.mu.Unlock()
+		
+		for _, notification := range pending {
+			for _, listener := range listeners {
+				listener(notification.transition, notification.security)
+			}
+		}
+		
+		s.mu.Lock()
+	}
+	s.dispatching = false
+	s.mu.Unlock()
+}
+
+func canTransition(from, to models.SecurityState) bool {
+	for _, allowed := range securityTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/storage"
+)
+
+var testStart = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestClock(t *testing.T) *clock.Simulated {
+	clk := clock.NewSimulated(testStart, 1)
+	clk.Pause()
+	t.Cleanup(clk.Close)
+	return clk
+}
+
+func newTestSecurity(t *testing.T) (*SecurityService, *storage.MemoryStore, *clock.Simulated, *config.Config) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.ExitDelay = 30
+	cfg.EntryDelay = 20
+	cfg.SecurityTimeout = 120
+	
+	for _, device := range []*models.Device{
+		{ID: "door_1", Name: "Front Door", Type: models.DeviceTypeContactSensor, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"zone": "perimeter", "open": false}},
+		{ID: "motion_1", Name: "Hall Motion", Type: models.DeviceTypeSensor, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"zone": "interior"}},
+		{ID: "panic_1", Name: "Panic Button", Type: models.DeviceTypeSensor, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"zone": "24h"}},
+	} {
+		if err := store.AddDevice(device); err != nil {
+			t.Fatalf("add device: %v", err)
+		}
+	}
+	
+	return NewSecurityService(store, clk, cfg), store, clk, cfg
+}
+
+func assertSecurityState(t *testing.T, security *SecurityService, want models.SecurityState) models.SecuritySystem {
+	t.Helper()
+	state := security.GetState()
+	if state.State != want {
+		t.Fatalf("state = %s, want %s", state.State, want)
+	}
+	return state
+}
+
+func assertProperty(t *testing.T, store *storage.MemoryStore, deviceID, property string, want interface{}) {
+	t.Helper()
+	device, err := store.GetDevice(deviceID)
+	if err != nil {
+		t.Fatalf("get device: %v", err)
+	}
+	if got := device.Properties[property]; got != want {
+		t.Errorf("%s %s = %v, want %v", deviceID, property, got, want)
+	}
+}
+
+func countEvents(store *storage.MemoryStore, eventType string) int {
+	count := 0
+	for _, event := range store.GetSystemEvents(0) {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSecurityExitAndEntryDelays(t *testing.T) {
+	security, _, clk, cfg := newTestSecurity(t)
+	
+	state, err := security.Arm("test", models.ArmModeAway, false)
+	if err != nil {
+		t.Fatalf("arm: %v", err)
+	}
+	if state.State != models.SecurityStateArming {
+		t.Fatalf("state after arm = %s, want arming", state.State)
+	}
+	if want := testStart.Add(30 * time.Second); !state.DelayEndsAt.Equal(want) {
+		t.Errorf("delay ends at %s, want %s", state.DelayEndsAt, want)
+	}
+	
+	clk.Step(29 * time.Second)
+	assertSecurityState(t, security, models.SecurityStateArming)
+	clk.Step(time.Second)
+	armed := assertSecurityState(t, security, models.SecurityStateArmed)
+	if len(armed.ActiveSensors) != 3 {
+		t.Errorf("active sensors = %v, want all three", armed.ActiveSensors)
+	}
+	
+	if _, err := security.SensorTripped("door_1"); err != nil {
+		t.Fatalf("trip: %v", err)
+	}
+	pending := assertSecurityState(t, security, models.SecurityStatePending)
+	if pending.TriggeredBy != "door_1" {
+		t.Errorf("triggered by %q, want door_1", pending.TriggeredBy)
+	}
+	
+	clk.Step(time.Duration(cfg.EntryDelay) * time.Second)
+	assertSecurityState(t, security, models.SecurityStateTriggered)
+	
+	clk.Step(time.Duration(cfg.SecurityTimeout) * time.Second)
+	assertSecurityState(t, security, models.SecurityStateArmed)
+}
+
+func TestSecurityDisarmDuringEntryDelayCancelsAlarm(t *testing.T) {
+	security, _, clk, _ := newTestSecurity(t)
+	
+	security.Arm("test", models.ArmModeAway, false)
+	clk.Step(30 * time.Second)
+	security.SensorTripped("motion_1")
+	assertSecurityState(t, security, models.SecurityStatePending)
+	
+	if _, err := security.Disarm("test"); err != nil {
+		t.Fatalf("disarm: %v", err)
+	}
+	clk.Step(time.Minute)
+	state := assertSecurityState(t, security, models.SecurityStateDisarmed)
+	if !state.DelayEndsAt.IsZero() {
+		t.Errorf("delay ends at %s after disarm, want zero", state.DelayEndsAt)
+	}
+}
+
+func TestSecurityNightModeSkipsEntryDelay(t *testing.T) {
+	security, _, clk, _ := newTestSecurity(t)
+	
+	security.Arm("test", models.ArmModeNight, false)
+	clk.Step(30 * time.Second)
+	state := assertSecurityState(t, security, models.SecurityStateArmed)
+	for _, id := range state.ActiveSensors {
+		if id == "motion_1" {
+			t.Fatalf("night mode watches interior sensor motion_1")
+		}
+	}
+	
+	security.SensorTripped("motion_1")
+	assertSecurityState(t, security, models.SecurityStateArmed)
+	
+	security.SensorTripped("door_1")
+	assertSecurityState(t, security, models.SecurityStateTriggered)
+}
+
+func TestSecurityStayModeIgnoresInterior(t *testing.T) {
+	security, _, clk, _ := newTestSecurity(t)
+	
+	if _, err := security.Arm("test", "vacation", false); err == nil {
+		t.Error("arm with an unknown mode succeeded")
+	}
+	
+	security.Arm("test", models.ArmModeStay, false)
+	clk.Step(30 * time.Second)
+	state := assertSecurityState(t, security, models.SecurityStateArmed)
+	if state.Mode != models.ArmModeStay || len(state.ActiveSensors) != 2 {
+		t.Errorf("armed %s watching %v, want stay watching door_1 and panic_1", state.Mode, state.ActiveSensors)
+	}
+	
+	security.SensorTripped("motion_1")
+	assertSecurityState(t, security, models.SecurityStateArmed)
+	
+	security.SensorTripped("door_1")
+	assertSecurityState(t, security, models.SecurityStatePending)
+}
+
+func TestSecurityAlwaysActiveZoneTriggersWhileDisarmed(t *testing.T) {
+	security, _, clk, cfg := newTestSecurity(t)
+	
+	security.SensorTripped("panic_1")
+	assertSecurityState(t, security, models.SecurityStateTriggered)
+	
+	clk.Step(time.Duration(cfg.SecurityTimeout) * time.Second)
+	assertSecurityState(t, security, models.SecurityStateDisarmed)
+}
+
+func TestSecurityRejectsInvalidRequests(t *testing.T) {
+	security, store, _, _ := newTestSecurity(t)
+	
+	if _, err := security.Disarm("test"); err == nil {
+		t.Error("disarm while disarmed succeeded")
+	}
+	
+	store.UpdateDevice("door_1", map[string]interface{}{"open": true})
+	if _, err := security.Arm("test", models.ArmModeAway, false); err == nil {
+		t.Fatal("arm with an open perimeter sensor succeeded")
+	}
+	assertSecurityState(t, security, models.SecurityStateDisarmed)
+	
+	state, err := security.Arm("test", models.ArmModeAway, true)
+	if err != nil {
+		t.Fatalf("arm with bypass: %v", err)
+	}
+	if len(state.BypassedSensors) != 1 || state.BypassedSensors[0] != "door_1" {
+		t.Errorf("bypassed sensors = %v, want [door_1]", state.BypassedSensors)
+	}
+	
+	if _, err := security.Arm("test", models.ArmModeAway, false); err == nil {
+		t.Error("arm while arming succeeded")
+	}
+}
+
+func TestSecurityListenersRunInOrderBeforeReturning(t *testing.T) {
+	security, _, clk, _ := newTestSecurity(t)
+	
+	var seen []models.SecurityState
+	security.OnTransition(func(transition models.SecurityTransition, state models.SecuritySystem) {
+		seen = append(seen, transition.To)
+		if transition.To == models.SecurityStateArmed {
+			security.SensorTripped("door_1")
+		}
+	})
+	
+	security.Arm("test", models.ArmModeAway, false)
+	if len(seen) != 1 {
+		t.Fatalf("listener saw %v after Arm returned, want [arming]", seen)
+	}
+	
+	clk.Step(30 * time.Second)
+	
+	want := []models.SecurityState{models.SecurityStateArming, models.SecurityStateArmed, models.SecurityStatePending}
+	if len(seen) != len(want) {
+		t.Fatalf("listener saw %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("listener saw %v, want %v", seen, want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/utils"
+)
+
+type TariffService struct {
+	clock    clock.Clock
+	config   *config.Config
+	mu       sync.Mutex
+	active   string
+	month    string
+	monthKWh float64
+}
+
+func NewTariffService(clk clock.Clock, cfg *config.Config) *TariffService {
+	return &TariffService{
+		clock:  clk,
+		config: cfg,
+		active: cfg.Tariff,
+	}
+}
+
+func (t *TariffService) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	t.month = ""
+	t.monthKWh = 0
+}
+
+func (t *TariffService) Plans() []models.TariffPlan {
+	return t.config.Tariffs
+}
+
+func (t *TariffService) Plan(name string) (models.TariffPlan, error) {
+	for _, plan := range t.config.Tariffs {
+		if plan.Name == name {
+			return plan, nil
+		}
+	}
+	return models.TariffPlan{}, fmt.Errorf("tariff %s not found", name)
+}
+
+func (t *TariffService) Active() models.TariffPlan {
+	t.mu.Lock()
+	name := t.active
+	t.mu.Unlock()
+	
+	plan, _ := t.Plan(name)
+	return plan
+}
+
+func (t *TariffService) SetActive(name string) error {
+	if _, err := t.Plan(name); err != nil {
+		return err
+	}
+	
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	t.active = name
+	return nil
+}
+
+func (t *TariffService) Price(usage *models.EnergyUsage) {
+	plan := t.Active()
+	
+	t.mu.Lock()
+	monthKWh := t.monthKWh
+	if t.month != usage.Timestamp.Format("2006-01") {
+		monthKWh = 0
+	}
+	t.mu.Unlock()
+	
+	rate, band := plan.RateAt(usage.Timestamp, monthKWh)
+	usage.Rate = rate
+	usage.Band = band
+	usage.Currency = plan.Currency
+	usage.Cost = utils.RoundToDecimal(usage.KWh()*rate, 6)
+}
+
+func (t *TariffService) Record(usage models.EnergyUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	month := usage.Timestamp.Format("2006-01")
+	if t.month != month {
+		t.month = month
+		t.monthKWh = 0
+	}
+	t.monthKWh += usage.KWh()
+}
+
+func (t *TariffService) Quote(plan models.TariffPlan, history []models.EnergyUsage) models.TariffQuote {
+	samples := append([]models.EnergyUsage(nil), history...)
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+	
+	quote := models.TariffQuote{
+		Plan:     plan.Name,
+		Currency: plan.Currency,
+		ByBand:   make(map[string]models.BandSummary),
+	}
+	
+	month := ""
+	monthKWh := 0.0
+	for _, sample := range samples {
+		if key := sample.Timestamp.Format("2006-01"); key != month {
+			month = key
+			monthKWh = 0
+		}
+		
+		kwh := sample.KWh()
+		rate, band := plan.RateAt(sample.Timestamp, monthKWh)
+		cost := kwh * rate
+		monthKWh += kwh
+		
+		quote.EnergyKWh += kwh
+		quote.EnergyCost += cost
+		
+		summary := quote.ByBand[band]
+		summary.EnergyKWh += kwh
+		summary.Cost += cost
+		quote.ByBand[band] = summary
+	}
+	
+	for band, summary := range quote.ByBand {
+		summary.EnergyKWh = utils.RoundToDecimal(summary.EnergyKWh, 3)
+		summary.Cost = utils.RoundToDecimal(summary.Cost, 4)
+		quote.ByBand[band] = summary
+	}
+	
+	quote.Days = chargedDays(samples)
+	quote.FixedCharges = t.FixedCharges(plan, samples)
+	quote.EnergyKWh = utils.RoundToDecimal(quote.EnergyKWh, 3)
+	quote.EnergyCost = utils.RoundToDecimal(quote.EnergyCost, 4)
+	quote.TotalCost = utils.RoundToDecimal(quote.EnergyCost+quote.FixedCharges, 4)
+	
+	return quote
+}
+
+func (t *TariffService) FixedCharges(plan models.TariffPlan, history []models.EnergyUsage) float64 {
+	return utils.RoundToDecimal(float64(chargedDays(history))*plan.DailyCharge, 2)
+}
+
+func chargedDays(history []models.EnergyUsage) int {
+	days := make(map[string]bool)
+	for _, sample := range history {
+		days[sample.Timestamp.Format("2006-01-02")] = true
+	}
+	return len(days)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+)
+
+func newTestTariffs(t *testing.T, active string) *TariffService {
+	cfg := config.Load()
+	cfg.Tariffs = testTariffs()
+	cfg.Tariff = active
+	return NewTariffService(newTestClock(t), cfg)
+}
+
+func testTariffs() []models.TariffPlan {
+	return []models.TariffPlan{
+		{Name: "flat", Type: models.TariffFlat, FlatRate: 0.12},
+		{Name: "time_of_use", Type: models.TariffTimeOfUse, Bands: []models.TariffBand{
+			{Name: "night", Days: "weekday", Start: "21:00", End: "05:00", Rate: 0.08},
+			{Name: "day", Days: "weekday", Start: "05:00", End: "21:00", Rate: 0.20},
+			{Name: "weekend", Days: "weekend", Start: "00:00", End: "24:00", Rate: 0.10},
+		}},
+		{Name: "tiered", Type: models.TariffTiered, DailyCharge: 0.50, Tiers: []models.TariffTier{
+			{UpToKWh: 2, Rate: 0.10},
+			{UpToKWh: 5, Rate: 0.15},
+			{UpToKWh: 0, Rate: 0.20},
+		}},
+	}
+}
+
+func sampleAt(at time.Time, energyWh float64) models.EnergyUsage {
+	return models.EnergyUsage{DeviceID: "heater_1", EnergyWh: energyWh, Timestamp: at}
+}
+
+func TestTimeOfUseBands(t *testing.T) {
+	tariffs := newTestTariffs(t, "time_of_use")
+	
+	tests := []struct {
+		at   time.Time
+		band string
+		rate float64
+	}{
+		{time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), "day", 0.20},
+		{time.Date(2024, 1, 15, 4, 59, 0, 0, time.UTC), "night", 0.08},
+		{time.Date(2024, 1, 15, 5, 0, 0, 0, time.UTC), "day", 0.20},
+		{time.Date(2024, 1, 15, 23, 30, 0, 0, time.UTC), "night", 0.08},
+		{time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC), "weekend", 0.10},
+		{time.Date(2024, 1, 14, 23, 59, 0, 0, time.UTC), "weekend", 0.10},
+	}
+	for _, tt := range tests {
+		usage := sampleAt(tt.at, 500)
+		tariffs.Price(&usage)
+		if usage.Band != tt.band || usage.Rate != tt.rate {
+			t.Errorf("%s priced as %s at %v, want %s at %v", tt.at.Format("Mon 15:04"), usage.Band, usage.Rate, tt.band, tt.rate)
+		}
+		if want := 0.5 * tt.rate; math.Abs(usage.Cost-want) > 1e-9 {
+			t.Errorf("%s cost = %v, want %v", tt.at.Format("Mon 15:04"), usage.Cost, want)
+		}
+	}
+}
+
+func TestTieredPricingResetsMonthly(t *testing.T) {
+	tariffs := newTestTariffs(t, "tiered")
+	
+	var bands []string
+	at := time.Date(2024, 1, 31, 20, 0, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		usage := sampleAt(at, 1000)
+		tariffs.Price(&usage)
+		tariffs.Record(usage)
+		bands = append(bands, usage.Band)
+		at = at.Add(time.Hour)
+	}
+	
+	// 20:00 to 23:00 on January 31 use 4 kWh, then February starts over.
+	want := []string{"tier_1", "tier_1", "tier_2", "tier_2", "tier_1", "tier_1", "tier_2"}
+	if strings.Join(bands, ",") != strings.Join(want, ",") {
+		t.Errorf("bands = %v, want %v", bands, want)
+	}
+}
+
+func TestQuoteIncludesDailyCharge(t *testing.T) {
+	tariffs := newTestTariffs(t, "flat")
+	plan, _ := tariffs.Plan("tiered")
+	
+	history := []models.EnergyUsage{
+		sampleAt(testStart.Add(26*time.Hour), 3000),
+		sampleAt(testStart, 1000),
+		sampleAt(testStart.Add(time.Hour), 1500),
+	}
+	quote := tariffs.Quote(plan, history)
+	
+	if quote.Days != 2 || quote.FixedCharges != 1.0 {
+		t.Errorf("quote charges %d days for %v, want 2 days for 1.00", quote.Days, quote.FixedCharges)
+	}
+	// The day-two sample starts at 2.5 kWh for the month, so all of it is priced in tier 2.
+	if quote.EnergyKWh != 5.5 || quote.EnergyCost != 0.7 {
+		t.Errorf("quote energy = %v kWh for %v, want 5.5 kWh for 0.70", quote.EnergyKWh, quote.EnergyCost)
+	}
+	if quote.TotalCost != 1.7 {
+		t.Errorf("total cost = %v, want 1.70", quote.TotalCost)
+	}
+	if quote.ByBand["tier_1"].EnergyKWh != 2.5 || quote.ByBand["tier_2"].EnergyKWh != 3 {
+		t.Errorf("by band = %+v, want 2.5 kWh in tier 1 and 3 kWh in tier 2", quote.ByBand)
+	}
+	
+	if got := tariffs.FixedCharges(plan, history); got != quote.FixedCharges {
+		t.Errorf("fixed charges = %v, want %v as quoted", got, quote.FixedCharges)
+	}
+	if got := tariffs.FixedCharges(plan, nil); got != 0 {
+		t.Errorf("fixed charges without samples = %v, want 0", got)
+	}
+}
+
+func TestSetActiveRejectsUnknownPlan(t *testing.T) {
+	tariffs := newTestTariffs(t, "flat")
+	
+	if err := tariffs.SetActive("peak_saver"); err == nil {
+		t.Error("set active to an unknown plan succeeded")
+	}
+	if err := tariffs.SetActive("tiered"); err != nil || tariffs.Active().Name != "tiered" {
+		t.Errorf("set active tiered = %v, active %s", err, tariffs.Active().Name)
+	}
+}
+
+func TestTimeOfUseMustCoverEveryMinute(t *testing.T) {
+	plan := models.TariffPlan{Name: "gappy", Type: models.TariffTimeOfUse, Bands: []models.TariffBand{
+		{Name: "day", Days: "all", Start: "06:00", End: "22:00", Rate: 0.2},
+	}}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "no band covers") {
+		t.Errorf("validate = %v, want a coverage error", err)
+	}
+	
+	plan.Bands = append(plan.Bands, models.TariffBand{Name: "night", Days: "all", Start: "22:00", End: "06:00", Rate: 0.1})
+	if err := plan.Validate(); err != nil {
+		t.Errorf("validate with a wrapping night band: %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/utils"
+)
+
+var weatherAlertMetrics = map[models.WeatherAlertType]map[string]float64{
+	models.WeatherAlertHeat:        {"temperature": 1},
+	models.WeatherAlertFreeze:      {"temperature": -1},
+	models.WeatherAlertWind:        {"wind_speed": 1},
+	models.WeatherAlertStorm:       {"wind_speed": 1, "pressure": -1, "humidity": 1},
+	models.WeatherAlertLowPressure: {"pressure": -1},
+}
+
+func (w *WeatherService) alertSeverity(kind models.WeatherAlertType, weather models.WeatherData) (string, string, bool) {
+	cfg := w.config.Weather.Alerts
+	severity := "warning"
+	
+	switch kind {
+	case models.WeatherAlertHeat:
+		if weather.Temperature <= cfg.HeatC {
+			return "", "", false
+		}
+		if weather.Temperature > cfg.SevereHeatC {
+			severity = "critical"
+		}
+		return severity, fmt.Sprintf("Extreme heat warning - Temperature above %.0f°C", cfg.HeatC), true
+		
+	case models.WeatherAlertFreeze:
+		if weather.Temperature >= cfg.FreezeC {
+			return "", "", false
+		}
+		if weather.Temperature < cfg.SevereFreezeC {
+			severity = "critical"
+		}
+		return severity, fmt.Sprintf("Freezing temperature alert - Temperature below %.0f°C", cfg.FreezeC), true
+		
+	case models.WeatherAlertWind:
+		if weather.WindSpeed <= cfg.WindKmh {
+			return "", "", false
+		}
+		if weather.WindSpeed > cfg.SevereWindKmh {
+			severity = "critical"
+		}
+		return severity, fmt.Sprintf("High wind warning - Wind speed above %.0f km/h", cfg.WindKmh), true
+		
+	case models.WeatherAlertStorm:
+		if weather.Condition != "stormy" {
+			return "", "", false
+		}
+		if weather.WindSpeed > cfg.SevereWindKmh {
+			severity = "critical"
+		}
+		return severity, "Storm warning - Severe weather conditions", true
+		
+	case models.WeatherAlertLowPressure:
+		if weather.Pressure >= cfg.LowPressureHPa {
+			return "", "", false
+		}
+		if weather.Pressure < cfg.SevereLowPressureHPa {
+			severity = "critical"
+		}
+		return severity, "Low pressure system - Potential weather instability", true
+	}
+	
+	return "", "", false
+}
+
+func (w *WeatherService) evaluateAlerts() {
+	if w.store == nil {
+		return
+	}
+	
+	w.alertMu.Lock()
+	defer w.alertMu.Unlock()
+	
+	now := w.clock.Now()
+	weather := *w.current
+	clearAfter := time.Duration(w.config.Weather.Alerts.ClearMinutes) * time.Minute
+	
+	for _, kind := range models.WeatherAlertTypes {
+		severity, message, raised := w.alertSeverity(kind, weather)
+		alert := w.alerts[kind]
+		
+		if !raised {
+			if alert != nil && !now.Before(alert.Expires) {
+				w.clearAlert(alert, now)
+			}
+			continue
+		}
+		
+		metrics := alertMetricValues(kind, weather)
+		event := ""
+		if alert == nil {
+			alert = &models.WeatherAlert{
+				ID:       utils.GenerateID("weather_alert"),
+				Type:     kind,
+				Severity: severity,
+				Message:  message,
+				Active:   true,
+				Metrics:  metrics,
+				Peak:     copyMetrics(metrics),
+				Onset:    now,
+			}
+			w.alerts[kind] = alert
+			event = "weather_alert"
+		} else {
+			peak := copyMetrics(alert.Peak)
+			for name, value := range metrics {
+				if (value-peak[name])*weatherAlertMetrics[kind][name] > 0 {
+					peak[name] = value
+				}
+			}
+			alert.Peak = peak
+			alert.Metrics = metrics
+			
+			if severity != alert.Severity || message != alert.Message {
+				alert.Severity = severity
+				alert.Message = message
+				event = "weather_alert_updated"
+			}
+		}
+		
+		alert.UpdatedAt = now
+		alert.Expires = now.Add(clearAfter)
+		w.store.SaveWeatherAlert(*alert)
+		
+		switch event {
+		case "weather_alert":
+			w.recordAlert(event, severity, message, alert, now)
+		case "weather_alert_updated":
+			w.recordAlert(event, severity, fmt.Sprintf("%s (now %s)", message, severity), alert, now)
+		}
+	}
+}
+
+func (w *WeatherService) expireAlerts() {
+	if w.store == nil {
+		return
+	}
+	
+	w.alertMu.Lock()
+	defer w.alertMu.Unlock()
+	
+	now := w.clock.Now()
+	for _, kind := range models.WeatherAlertTypes {
+		if alert := w.alerts[kind]; alert != nil && !now.Before(alert.Expires) {
+			w.clearAlert(alert, now)
+		}
+	}
+}
+
+func (w *WeatherService) clearAlert(alert *models.WeatherAlert, now time.Time) {
+	alert.Active = false
+	alert.ClearedAt = now
+	alert.UpdatedAt = now
+	w.store.SaveWeatherAlert(*alert)
+	delete(w.alerts, alert.Type)
+	
+	w.recordAlert("weather_alert_cleared", "info", fmt.Sprintf("%s cleared after %s", alert.Message, now.Sub(alert.Onset).Round(time.Second)), alert, now)
+}
+
+func (w *WeatherService) recordAlert(eventType, severity, message string, alert *models.WeatherAlert, now time.Time) {
+	data := map[string]interface{}{
+		"alert_id":   alert.ID,
+		"alert_type": alert.Type,
+		"severity":   alert.Severity,
+	}
+	for name, value := range alert.Metrics {
+		data[name] = value
+	}
+	
+	w.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID(eventType),
+		Type:      eventType,
+		Source:    "weather",
+		Message:   message,
+		Data:      data,
+		Timestamp: now,
+		Severity:  severity,
+	})
+}
+
+func (w *WeatherService) GetWeatherAlerts(activeOnly bool, limit int) []models.WeatherAlert {
+	if w.store == nil {
+		return make([]models.WeatherAlert, 0)
+	}
+	return w.store.GetWeatherAlerts(activeOnly, limit)
+}
+
+func (w *WeatherService) ActiveAlerts() []models.WeatherAlert {
+	w.alertMu.Lock()
+	defer w.alertMu.Unlock()
+	
+	alerts := make([]models.WeatherAlert, 0, len(w.alerts))
+	for _, kind := range models.WeatherAlertTypes {
+		if alert, ok := w.alerts[kind]; ok {
+			active := *alert
+			active.Metrics = copyMetrics(alert.Metrics)
+			active.Peak = copyMetrics(alert.Peak)
+			alerts = append(alerts, active)
+		}
+	}
+	return alerts
+}
+
+func alertMetricValues(kind models.WeatherAlertType, weather models.WeatherData) map[string]float64 {
+	values := map[string]float64{
+		"temperature": weather.Temperature,
+		"humidity":    weather.Humidity,
+		"pressure":    weather.Pressure,
+		"wind_speed":  weather.WindSpeed,
+	}
+	
+	metrics := make(map[string]float64)
+	for name := range weatherAlertMetrics[kind] {
+		metrics[name] = utils.RoundToDecimal(values[name], 2)
+	}
+	return metrics
+}
+
+func copyMetrics(metrics map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(metrics))
+	for name, value := range metrics {
+		copied[name] = value
+	}
+	return copied
+}
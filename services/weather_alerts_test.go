@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+type failingWeatherProvider struct{}
+
+func (failingWeatherProvider) Name() string {
+	return "failing"
+}
+
+func (failingWeatherProvider) Current(now time.Time, previous models.WeatherData) (models.WeatherData, error) {
+	return previous, errors.New("feed unavailable")
+}
+
+func (failingWeatherProvider) Forecast(now time.Time, current models.WeatherData, hours int) ([]models.WeatherData, error) {
+	return nil, errors.New("feed unavailable")
+}
+
+func newTestWeather(t *testing.T) (*WeatherService, *storage.MemoryStore, *clock.Simulated) {
+	clk := newTestClock(t)
+	store := storage.NewMemoryStore(clk)
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	
+	weather := NewWeatherService(clk, random.NewSource(1), cfg)
+	weather.store = store
+	return weather, store, clk
+}
+
+func setTemperature(weather *WeatherService, temperature float64) {
+	weather.SetWeather(&models.WeatherData{
+		Temperature: temperature,
+		Humidity:    50,
+		Pressure:    1013,
+		Condition:   "clear",
+		WindSpeed:   5,
+		WindDir:     "N",
+	})
+}
+
+func TestWeatherAlertLifecycle(t *testing.T) {
+	weather, store, clk := newTestWeather(t)
+	
+	setTemperature(weather, 37)
+	active := weather.ActiveAlerts()
+	if len(active) != 1 || active[0].Type != models.WeatherAlertHeat || active[0].Severity != "warning" {
+		t.Fatalf("active alerts = %+v, want one heat warning", active)
+	}
+	alertID := active[0].ID
+	if countEvents(store, "weather_alert") != 1 {
+		t.Errorf("weather_alert events = %d, want 1", countEvents(store, "weather_alert"))
+	}
+	
+	clk.Step(time.Minute)
+	setTemperature(weather, 42)
+	clk.Step(time.Minute)
+	setTemperature(weather, 38)
+	
+	active = weather.ActiveAlerts()
+	if len(active) != 1 || active[0].ID != alertID {
+		t.Fatalf("active alerts = %+v, want the same heat alert", active)
+	}
+	alert := active[0]
+	if alert.Severity != "warning" {
+		t.Errorf("severity = %s, want warning after cooling below the severe threshold", alert.Severity)
+	}
+	if alert.Metrics["temperature"] != 38 || alert.Peak["temperature"] != 42 {
+		t.Errorf("metrics = %v, peak = %v, want 38 now and 42 at peak", alert.Metrics, alert.Peak)
+	}
+	if !alert.Onset.Equal(testStart) {
+		t.Errorf("onset = %s, want %s", alert.Onset, testStart)
+	}
+	if got := countEvents(store, "weather_alert_updated"); got != 2 {
+		t.Errorf("weather_alert_updated events = %d, want 2", got)
+	}
+	
+	setTemperature(weather, 20)
+	if len(weather.ActiveAlerts()) != 1 {
+		t.Fatal("heat alert cleared before clear_minutes passed")
+	}
+	
+	clk.Step(10 * time.Minute)
+	setTemperature(weather, 20)
+	if active := weather.ActiveAlerts(); len(active) != 0 {
+		t.Fatalf("active alerts = %+v after clear_minutes, want none", active)
+	}
+	if countEvents(store, "weather_alert_cleared") != 1 {
+		t.Errorf("weather_alert_cleared events = %d, want 1", countEvents(store, "weather_alert_cleared"))
+	}
+	
+	history := weather.GetWeatherAlerts(false, 0)
+	if len(history) != 1 {
+		t.Fatalf("alert history = %+v, want one alert", history)
+	}
+	if history[0].Active || !history[0].ClearedAt.Equal(clk.Now()) || history[0].Peak["temperature"] != 42 {
+		t.Errorf("stored alert = %+v, want it cleared now with a 42°C peak", history[0])
+	}
+}
+
+func TestWeatherAlertsRunConcurrently(t *testing.T) {
+	weather, _, _ := newTestWeather(t)
+	
+	weather.SetWeather(&models.WeatherData{
+		Temperature: -12,
+		Pressure:    970,
+		Condition:   "stormy",
+		WindSpeed:   40,
+	})
+	
+	severities := make(map[models.WeatherAlertType]string)
+	for _, alert := range weather.ActiveAlerts() {
+		severities[alert.Type] = alert.Severity
+	}
+	for _, kind := range []models.WeatherAlertType{models.WeatherAlertFreeze, models.WeatherAlertWind, models.WeatherAlertStorm, models.WeatherAlertLowPressure} {
+		if severities[kind] != "critical" {
+			t.Errorf("%s severity = %q, want critical", kind, severities[kind])
+		}
+	}
+	if _, ok := severities[models.WeatherAlertHeat]; ok {
+		t.Error("heat alert raised at -12°C")
+	}
+	if message := weather.GetWeatherAlert(); message == nil {
+		t.Error("GetWeatherAlert returned nil with active alerts")
+	}
+}
+
+func TestWeatherAlertsExpireWhenProviderFails(t *testing.T) {
+	weather, store, clk := newTestWeather(t)
+	
+	setTemperature(weather, 37)
+	weather.provider = failingWeatherProvider{}
+	
+	clk.Step(5 * time.Minute)
+	weather.updateWeather()
+	if len(weather.ActiveAlerts()) != 1 {
+		t.Fatal("heat alert cleared before it expired")
+	}
+	
+	clk.Step(5 * time.Minute)
+	weather.updateWeather()
+	if active := weather.ActiveAlerts(); len(active) != 0 {
+		t.Fatalf("active alerts = %+v with a failing provider past expiry, want none", active)
+	}
+	if countEvents(store, "weather_alert_cleared") != 1 {
+		t.Errorf("weather_alert_cleared events = %d, want 1", countEvents(store, "weather_alert_cleared"))
+	}
+}
+
+func TestWeatherAlertsAreCopied(t *testing.T) {
+	weather, _, clk := newTestWeather(t)
+	
+	setTemperature(weather, 42)
+	stored := weather.GetWeatherAlerts(true, 0)[0]
+	active := weather.ActiveAlerts()[0]
+	
+	stored.Peak["temperature"] = 99
+	active.Metrics["temperature"] = 99
+	if peak := weather.GetWeatherAlerts(true, 0)[0].Peak["temperature"]; peak != 42 {
+		t.Errorf("stored peak = %.1f after editing a returned copy, want 42", peak)
+	}
+	if metric := weather.ActiveAlerts()[0].Metrics["temperature"]; metric != 42 {
+		t.Errorf("active metric = %.1f after editing a returned copy, want 42", metric)
+	}
+	
+	before := weather.GetWeatherAlerts(true, 0)[0]
+	clk.Step(time.Minute)
+	setTemperature(weather, 44)
+	if before.Peak["temperature"] != 42 || before.Metrics["temperature"] != 42 {
+		t.Errorf("earlier copy changed to metrics %v, peak %v", before.Metrics, before.Peak)
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+type weatherFeed struct {
+	Current  *models.WeatherData  `json:"current"`
+	Forecast []models.WeatherData `json:"forecast"`
+}
+
+type httpWeatherProvider struct {
+	url    string
+	client *http.Client
+	mu     sync.Mutex
+	last   *weatherFeed
+}
+
+func newHTTPWeatherProvider(url string, timeout time.Duration) *httpWeatherProvider {
+	return &httpWeatherProvider{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *httpWeatherProvider) Name() string {
+	return "http"
+}
+
+func (p *httpWeatherProvider) Current(now time.Time, previous models.WeatherData) (models.WeatherData, error) {
+	feed, err := p.poll()
+	if err != nil {
+		return previous, err
+	}
+	
+	weather := *feed.Current
+	weather.Timestamp = now
+	return weather, nil
+}
+
+func (p *httpWeatherProvider) Forecast(now time.Time, current models.WeatherData, hours int) ([]models.WeatherData, error) {
+	p.mu.Lock()
+	feed := p.last
+	p.mu.Unlock()
+	
+	if feed == nil {
+		var err error
+		if feed, err = p.poll(); err != nil {
+			return nil, err
+		}
+	}
+	
+	end := now.Add(time.Duration(hours) * time.Hour)
+	forecast := make([]models.WeatherData, 0)
+	for _, point := range feed.Forecast {
+		if point.Timestamp.After(now) && !point.Timestamp.After(end) {
+			forecast = append(forecast, point)
+		}
+	}
+	return forecast, nil
+}
+
+func (p *httpWeatherProvider) poll() (*weatherFeed, error) {
+	resp, err := p.client.Get(p.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("weather endpoint returned %s", resp.Status)
+	}
+	
+	var feed weatherFeed
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("invalid weather feed: %v", err)
+	}
+	if feed.Current == nil {
+		return nil, fmt.Errorf("weather feed has no current conditions")
+	}
+	for i, point := range feed.Forecast {
+		if point.Timestamp.IsZero() {
+			return nil, fmt.Errorf("weather feed forecast entry %d has no timestamp", i+1)
+		}
+	}
+	
+	p.mu.Lock()
+	p.last = &feed
+	p.mu.Unlock()
+	
+	return &feed, nil
+}
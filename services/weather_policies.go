@@ -0,0 +1,394 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+type policyHold struct {
+	deviceID string
+	property string
+	original interface{}
+	values   map[models.WeatherPolicy]interface{}
+	order    []models.WeatherPolicy
+}
+
+var rainConditions = map[string]bool{
+	"rainy":  true,
+	"stormy": true,
+}
+
+var sunConditions = map[string]bool{
+	"sunny":         true,
+	"clear":         true,
+	"partly_cloudy": true,
+}
+
+func (w *WeatherService) policyEnabled(policy models.WeatherPolicy) bool {
+	policies := w.config.Weather.Policies
+	switch policy {
+	case models.WeatherPolicyPrecondition:
+		return policies.Precondition.Enabled
+	case models.WeatherPolicySunShading:
+		return policies.SunShading.Enabled
+	case models.WeatherPolicyFreezeProtection:
+		return policies.FreezeProtection.Enabled
+	case models.WeatherPolicyIrrigationPause:
+		return policies.IrrigationPause.Enabled
+	}
+	return false
+}
+
+func (w *WeatherService) SetPolicyEnabled(policy models.WeatherPolicy, enabled bool) error {
+	w.policyMu.Lock()
+	defer w.policyMu.Unlock()
+	
+	policies := &w.config.Weather.Policies
+	switch policy {
+	case models.WeatherPolicyPrecondition:
+		policies.Precondition.Enabled = enabled
+	case models.WeatherPolicySunShading:
+		policies.SunShading.Enabled = enabled
+	case models.WeatherPolicyFreezeProtection:
+		policies.FreezeProtection.Enabled = enabled
+	case models.WeatherPolicyIrrigationPause:
+		policies.IrrigationPause.Enabled = enabled
+	default:
+		return fmt.Errorf("unknown weather policy: %s", policy)
+	}
+	return nil
+}
+
+func (w *WeatherService) PolicyStatus() []models.WeatherPolicyStatus {
+	w.policyMu.Lock()
+	defer w.policyMu.Unlock()
+	
+	statuses := make([]models.WeatherPolicyStatus, 0, len(models.WeatherPolicies))
+	for _, policy := range models.WeatherPolicies {
+		status := w.policyState(policy)
+		status.Enabled = w.policyEnabled(policy)
+		status.Devices = w.heldDevices(policy)
+		statuses = append(statuses, *status)
+	}
+	return statuses
+}
+
+func (w *WeatherService) EvaluatePolicies(devices []*models.Device) []models.WeatherDecision {
+	w.policyMu.Lock()
+	defer w.policyMu.Unlock()
+	
+	now := w.clock.Now()
+	weather := *w.current
+	
+	sorted := make([]*models.Device, 0, len(devices))
+	byID := make(map[string]*models.Device, len(devices))
+	for _, device := range devices {
+		working := &models.Device{
+			ID:         device.ID,
+			Type:       device.Type,
+			Status:     device.Status,
+			Properties: make(map[string]interface{}, len(device.Properties)),
+		}
+		for name, value := range device.Properties {
+			working.Properties[name] = value
+		}
+		sorted = append(sorted, working)
+		byID[device.ID] = working
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+	
+	type outcome struct {
+		state  string
+		reason string
+	}
+	outcomes := make(map[models.WeatherPolicy]outcome)
+	for _, policy := range models.WeatherPolicies {
+		if w.policyEnabled(policy) {
+			state, reason := w.policyCondition(policy, weather, now)
+			outcomes[policy] = outcome{state, reason}
+		}
+	}
+	
+	decisions := make([]models.WeatherDecision, 0)
+	for _, releasing := range []bool{false, true} {
+		for _, policy := range models.WeatherPolicies {
+			result := outcomes[policy]
+			if (result.state == "") != releasing {
+				continue
+			}
+			if decision, ok := w.decide(policy, result.state, result.reason, sorted, byID, now); ok {
+				decisions = append(decisions, decision)
+			}
+		}
+	}
+	
+	return decisions
+}
+
+func (w *WeatherService) decide(policy models.WeatherPolicy, state, reason string, sorted []*models.Device, byID map[string]*models.Device, now time.Time) (models.WeatherDecision, bool) {
+	status := w.policyState(policy)
+	
+	decision := models.WeatherDecision{
+		Policy:    policy,
+		State:     state,
+		Reason:    reason,
+		Actions:   make([]models.WeatherAction, 0),
+		Timestamp: now,
+	}
+	
+	switch {
+	case state == status.State:
+		if state == "" {
+			return decision, false
+		}
+		decision.Decision = "extended"
+		decision.Reason = status.Reason
+		
+	case status.State == "":
+		decision.Decision = "engaged"
+		
+	case state == "":
+		decision.Decision = "released"
+		decision.State = status.State
+		if !w.policyEnabled(policy) {
+			decision.Reason = "policy disabled"
+		} else {
+			decision.Reason = "conditions normal"
+		}
+		decision.Actions = w.release(policy, byID)
+		
+	default:
+		decision.Decision = "switched"
+		decision.Actions = w.release(policy, byID)
+	}
+	
+	if state != "" {
+		decision.Actions = append(decision.Actions, w.engage(policy, state, sorted)...)
+	}
+	
+	if decision.Decision == "extended" {
+		return decision, len(decision.Actions) > 0
+	}
+	status.State = state
+	status.Reason = decision.Reason
+	status.Since = now
+	return decision, true
+}
+
+func (w *WeatherService) policyState(policy models.WeatherPolicy) *models.WeatherPolicyStatus {
+	status, ok := w.policies[policy]
+	if !ok {
+		status = &models.WeatherPolicyStatus{Policy: policy}
+		w.policies[policy] = status
+	}
+	return status
+}
+
+func (w *WeatherService) policyCondition(policy models.WeatherPolicy, weather models.WeatherData, now time.Time) (string, string) {
+	policies := w.config.Weather.Policies
+	
+	switch policy {
+	case models.WeatherPolicyPrecondition:
+		cfg := policies.Precondition
+		temperatures := w.HourlyTemperatures(now, cfg.LookaheadHours)
+		low, high := weather.Temperature, weather.Temperature
+		for _, temperature := range temperatures {
+			if temperature < low {
+				low = temperature
+			}
+			if temperature > high {
+				high = temperature
+			}
+		}
+		
+		if high > cfg.HeatAboveC {
+			return "precool", fmt.Sprintf("Forecast high of %.1f°C within %d hours", high, cfg.LookaheadHours)
+		}
+		if low < cfg.ColdBelowC {
+			return "preheat", fmt.Sprintf("Forecast low of %.1f°C within %d hours", low, cfg.LookaheadHours)
+		}
+		
+	case models.WeatherPolicySunShading:
+		hour := now.Hour()
+		if weather.Temperature > policies.SunShading.AboveC && sunConditions[weather.Condition] && hour >= 8 && hour < 19 {
+			return "shading", fmt.Sprintf("%.1f°C with %s skies", weather.Temperature, weather.Condition)
+		}
+		
+	case models.WeatherPolicyFreezeProtection:
+		if weather.Temperature < policies.FreezeProtection.BelowC {
+			return "protecting", fmt.Sprintf("Temperature %.1f°C below %.1f°C", weather.Temperature, policies.FreezeProtection.BelowC)
+		}
+		
+	case models.WeatherPolicyIrrigationPause:
+		if rainConditions[weather.Condition] {
+			return "paused", fmt.Sprintf("Currently %s", weather.Condition)
+		}
+		since := now.Add(-time.Duration(w.config.WeatherUpdateInterval) * time.Second)
+		until := now.Add(time.Duration(policies.IrrigationPause.LookaheadHours) * time.Hour)
+		for _, point := range w.forecastPoints() {
+			if point.Timestamp.After(since) && !point.Timestamp.After(until) && rainConditions[point.Condition] {
+				return "paused", fmt.Sprintf("%s forecast at %s", point.Condition, point.Timestamp.Format(time.RFC3339))
+			}
+		}
+	}
+	
+	return "", ""
+}
+
+func (w *WeatherService) engage(policy models.WeatherPolicy, state string, devices []*models.Device) []models.WeatherAction {
+	policies := w.config.Weather.Policies
+	actions := make([]models.WeatherAction, 0)
+	
+	for _, device := range devices {
+		if device.Status == models.DeviceStatusOffline || w.holding(policy, device.ID) {
+			continue
+		}
+		
+		updates := make(map[string]interface{})
+		switch {
+		case policy == models.WeatherPolicyPrecondition && device.Type == models.DeviceTypeThermostat:
+			if mode, _ := device.Properties["mode"].(string); mode == "off" {
+				continue
+			}
+			target, ok := numberProperty(device, "target_temp")
+			if !ok {
+				continue
+			}
+			if state == "preheat" {
+				target += policies.Precondition.OffsetC
+			} else {
+				target -= policies.Precondition.OffsetC
+			}
+			w.hold(policy, device, "target_temp", target, updates)
+			
+		case policy == models.WeatherPolicySunShading && device.Type == models.DeviceTypeBlinds:
+			if sunFacing, _ := device.Properties["sun_facing"].(bool); !sunFacing {
+				continue
+			}
+			w.hold(policy, device, "position", policies.SunShading.Position, updates)
+			
+		case policy == models.WeatherPolicyFreezeProtection && device.Type == models.DeviceTypeThermostat:
+			if target, ok := numberProperty(device, "target_temp"); ok && target < policies.FreezeProtection.MinTargetC {
+				w.hold(policy, device, "target_temp", policies.FreezeProtection.MinTargetC, updates)
+			}
+			if mode, _ := device.Properties["mode"].(string); mode == "off" {
+				w.hold(policy, device, "mode", "heat", updates)
+			}
+			
+		case policy == models.WeatherPolicyFreezeProtection && device.Type == models.DeviceTypeIrrigation,
+			policy == models.WeatherPolicyIrrigationPause && device.Type == models.DeviceTypeIrrigation:
+			w.hold(policy, device, "paused", true, updates)
+		}
+		
+		if len(updates) > 0 {
+			actions = append(actions, models.WeatherAction{DeviceID: device.ID, Updates: updates})
+		}
+	}
+	
+	return actions
+}
+
+func (w *WeatherService) hold(policy models.WeatherPolicy, device *models.Device, property string, value interface{}, updates map[string]interface{}) {
+	key := device.ID + "/" + property
+	held, ok := w.holds[key]
+	if !ok {
+		held = &policyHold{
+			deviceID: device.ID,
+			property: property,
+			original: device.Properties[property],
+			values:   make(map[models.WeatherPolicy]interface{}),
+		}
+		w.holds[key] = held
+	}
+	
+	held.values[policy] = value
+	held.order = append(held.order, policy)
+	if !sameValue(device.Properties[property], value) {
+		updates[property] = value
+		device.Properties[property] = value
+	}
+}
+
+func (w *WeatherService) release(policy models.WeatherPolicy, devices map[string]*models.Device) []models.WeatherAction {
+	keys := make([]string, 0)
+	for key, held := range w.holds {
+		if _, ok := held.values[policy]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	
+	updates := make(map[string]map[string]interface{})
+	order := make([]string, 0)
+	for _, key := range keys {
+		held := w.holds[key]
+		applied := held.values[held.order[len(held.order)-1]]
+		delete(held.values, policy)
+		for i, holder := range held.order {
+			if holder == policy {
+				held.order = append(held.order[:i], held.order[i+1:]...)
+				break
+			}
+		}
+		
+		value := held.original
+		if len(held.order) > 0 {
+			value = held.values[held.order[len(held.order)-1]]
+		} else {
+			delete(w.holds, key)
+		}
+		
+		device, ok := devices[held.deviceID]
+		if !ok || !sameValue(device.Properties[held.property], applied) || sameValue(device.Properties[held.property], value) {
+			continue
+		}
+		if _, ok := updates[held.deviceID]; !ok {
+			updates[held.deviceID] = make(map[string]interface{})
+			order = append(order, held.deviceID)
+		}
+		updates[held.deviceID][held.property] = value
+		device.Properties[held.property] = value
+	}
+	
+	actions := make([]models.WeatherAction, 0, len(order))
+	for _, deviceID := range order {
+		actions = append(actions, models.WeatherAction{DeviceID: deviceID, Updates: updates[deviceID]})
+	}
+	return actions
+}
+
+func (w *WeatherService) holding(policy models.WeatherPolicy, deviceID string) bool {
+	for _, held := range w.holds {
+		if _, ok := held.values[policy]; ok && held.deviceID == deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WeatherService) heldDevices(policy models.WeatherPolicy) []string {
+	seen := make(map[string]bool)
+	devices := make([]string, 0)
+	for _, held := range w.holds {
+		if _, ok := held.values[policy]; ok && !seen[held.deviceID] {
+			seen[held.deviceID] = true
+			devices = append(devices, held.deviceID)
+		}
+	}
+	sort.Strings(devices)
+	return devices
+}
+
+func sameValue(a, b interface{}) bool {
+	if x, ok := numberValue(a); ok {
+		if y, ok := numberValue(b); ok {
+			return x == y
+		}
+	}
+	return a == b
+}
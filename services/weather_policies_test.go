@@ -0,0 +1,168 @@
+package services
+
+import (
+	"testing"
+
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+)
+
+func newTestPolicies(t *testing.T) (*WeatherService, map[string]*models.Device) {
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	cfg.Weather.Policies.Precondition.Enabled = false
+	
+	weather := NewWeatherService(newTestClock(t), random.NewSource(1), cfg)
+	// Decide from the current conditions only.
+	weather.forecast = nil
+	
+	devices := map[string]*models.Device{
+		"thermostat_1": {ID: "thermostat_1", Type: models.DeviceTypeThermostat, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"mode": "off", "target_temp": 8.0}},
+		"irrigation_1": {ID: "irrigation_1", Type: models.DeviceTypeIrrigation, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"paused": false}},
+		"blinds_1": {ID: "blinds_1", Type: models.DeviceTypeBlinds, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"position": 100, "sun_facing": true}},
+		"blinds_2": {ID: "blinds_2", Type: models.DeviceTypeBlinds, Status: models.DeviceStatusOnline,
+			Properties: map[string]interface{}{"position": 100, "sun_facing": false}},
+	}
+	return weather, devices
+}
+
+func setConditions(weather *WeatherService, temperature float64, condition string) {
+	weather.SetWeather(&models.WeatherData{Temperature: temperature, Humidity: 50, Pressure: 1013, Condition: condition, WindSpeed: 5, WindDir: "N"})
+}
+
+func evaluate(weather *WeatherService, devices map[string]*models.Device) map[models.WeatherPolicy]models.WeatherDecision {
+	list := make([]*models.Device, 0, len(devices))
+	for _, device := range devices {
+		list = append(list, device)
+	}
+	
+	decisions := make(map[models.WeatherPolicy]models.WeatherDecision)
+	for _, decision := range weather.EvaluatePolicies(list) {
+		decisions[decision.Policy] = decision
+		for _, action := range decision.Actions {
+			for name, value := range action.Updates {
+				devices[action.DeviceID].Properties[name] = value
+			}
+		}
+	}
+	return decisions
+}
+
+func TestFreezeProtectionHoldsAndReleases(t *testing.T) {
+	weather, devices := newTestPolicies(t)
+	
+	setConditions(weather, -3, "clear")
+	decisions := evaluate(weather, devices)
+	if decision := decisions[models.WeatherPolicyFreezeProtection]; decision.Decision != "engaged" || decision.State != "protecting" {
+		t.Fatalf("freeze protection = %+v, want engaged", decision)
+	}
+	thermostat := devices["thermostat_1"].Properties
+	if thermostat["mode"] != "heat" || thermostat["target_temp"] != 10.0 {
+		t.Errorf("thermostat = %v, want heat at 10", thermostat)
+	}
+	if devices["irrigation_1"].Properties["paused"] != true {
+		t.Error("irrigation not paused during freeze protection")
+	}
+	
+	// Nothing changes while the freeze continues.
+	setConditions(weather, -4, "clear")
+	if decisions := evaluate(weather, devices); len(decisions) != 0 {
+		t.Errorf("decisions while still freezing = %+v, want none", decisions)
+	}
+	
+	setConditions(weather, 5, "clear")
+	decisions = evaluate(weather, devices)
+	if decision := decisions[models.WeatherPolicyFreezeProtection]; decision.Decision != "released" || decision.Reason != "conditions normal" {
+		t.Fatalf("freeze protection = %+v, want released", decision)
+	}
+	if thermostat["mode"] != "off" || thermostat["target_temp"] != 8.0 || devices["irrigation_1"].Properties["paused"] != false {
+		t.Errorf("after release thermostat = %v and irrigation = %v, want the original settings", thermostat, devices["irrigation_1"].Properties)
+	}
+}
+
+func TestOverlappingHoldsReleaseInTurn(t *testing.T) {
+	weather, devices := newTestPolicies(t)
+	
+	setConditions(weather, -1, "rainy")
+	decisions := evaluate(weather, devices)
+	if decisions[models.WeatherPolicyFreezeProtection].Decision != "engaged" || decisions[models.WeatherPolicyIrrigationPause].Decision != "engaged" {
+		t.Fatalf("decisions = %+v, want freeze protection and irrigation pause engaged", decisions)
+	}
+	
+	// Irrigation stays paused while the rain still holds it.
+	setConditions(weather, 5, "rainy")
+	decisions = evaluate(weather, devices)
+	if decision := decisions[models.WeatherPolicyFreezeProtection]; decision.Decision != "released" {
+		t.Fatalf("freeze protection = %+v, want released", decision)
+	}
+	for _, action := range decisions[models.WeatherPolicyFreezeProtection].Actions {
+		if action.DeviceID == "irrigation_1" {
+			t.Errorf("freeze release changed irrigation: %v", action.Updates)
+		}
+	}
+	if devices["irrigation_1"].Properties["paused"] != true {
+		t.Fatal("irrigation resumed while rain is still falling")
+	}
+	
+	setConditions(weather, 5, "cloudy")
+	decisions = evaluate(weather, devices)
+	if decisions[models.WeatherPolicyIrrigationPause].Decision != "released" || devices["irrigation_1"].Properties["paused"] != false {
+		t.Errorf("irrigation pause = %+v with paused %v, want released and resumed", decisions[models.WeatherPolicyIrrigationPause], devices["irrigation_1"].Properties["paused"])
+	}
+}
+
+func TestShadingKeepsManualChangesOnRelease(t *testing.T) {
+	weather, devices := newTestPolicies(t)
+	
+	setConditions(weather, 33, "sunny")
+	decisions := evaluate(weather, devices)
+	if decisions[models.WeatherPolicySunShading].Decision != "engaged" {
+		t.Fatalf("sun shading = %+v, want engaged", decisions[models.WeatherPolicySunShading])
+	}
+	if devices["blinds_1"].Properties["position"] != 0 || devices["blinds_2"].Properties["position"] != 100 {
+		t.Errorf("blinds at %v and %v, want only the sun-facing blinds closed", devices["blinds_1"].Properties["position"], devices["blinds_2"].Properties["position"])
+	}
+	
+	devices["blinds_1"].Properties["position"] = 50
+	
+	setConditions(weather, 20, "sunny")
+	decisions = evaluate(weather, devices)
+	if decision := decisions[models.WeatherPolicySunShading]; decision.Decision != "released" || len(decision.Actions) != 0 {
+		t.Errorf("sun shading = %+v, want released without actions", decision)
+	}
+	if devices["blinds_1"].Properties["position"] != 50 {
+		t.Errorf("blinds position = %v, want the manual 50 kept", devices["blinds_1"].Properties["position"])
+	}
+}
+
+func TestDisablingPolicyReleasesIt(t *testing.T) {
+	weather, devices := newTestPolicies(t)
+	
+	setConditions(weather, -3, "clear")
+	evaluate(weather, devices)
+	
+	if err := weather.SetPolicyEnabled("hail_guard", false); err == nil {
+		t.Error("disabling an unknown policy succeeded")
+	}
+	if err := weather.SetPolicyEnabled(models.WeatherPolicyFreezeProtection, false); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	
+	decisions := evaluate(weather, devices)
+	if decision := decisions[models.WeatherPolicyFreezeProtection]; decision.Decision != "released" || decision.Reason != "policy disabled" {
+		t.Errorf("freeze protection = %+v, want released because it was disabled", decision)
+	}
+	if devices["thermostat_1"].Properties["mode"] != "off" {
+		t.Errorf("thermostat mode = %v, want off again", devices["thermostat_1"].Properties["mode"])
+	}
+	
+	for _, status := range weather.PolicyStatus() {
+		if status.Policy == models.WeatherPolicyFreezeProtection && (status.Enabled || status.State != "" || len(status.Devices) != 0) {
+			t.Errorf("status = %+v, want disabled and idle", status)
+		}
+	}
+}
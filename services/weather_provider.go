@@ -0,0 +1,35 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+)
+
+type WeatherProvider interface {
+	Name() string
+	Current(now time.Time, previous models.WeatherData) (models.WeatherData, error)
+	Forecast(now time.Time, current models.WeatherData, hours int) ([]models.WeatherData, error)
+}
+
+func NewWeatherProvider(clk clock.Clock, rand *random.Stream, cfg *config.Config) (WeatherProvider, error) {
+	switch cfg.Weather.Provider {
+	case "replay":
+		return newReplayWeatherProvider(cfg.Weather.ReplayFile, clk.Now(), cfg.Weather.ReplaySpeed, cfg.Weather.ReplayLoop)
+	case "http":
+		return newHTTPWeatherProvider(cfg.Weather.HTTPURL, time.Duration(cfg.Weather.HTTPTimeout)*time.Second), nil
+	case "random", "":
+		return newRandomWeatherProvider(rand), nil
+	}
+	return nil, fmt.Errorf("unknown weather provider %q", cfg.Weather.Provider)
+}
+
+func diurnalVariation(t time.Time) float64 {
+	hour := float64(t.Hour()) + float64(t.Minute())/60
+	return 5.0 * math.Sin(2.0*math.Pi*(hour-6)/24.0)
+}
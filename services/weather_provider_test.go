@@ -0,0 +1,268 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+const replayTrace = `timestamp,temperature,humidity,pressure,condition,wind_speed,wind_direction
+2024-01-15T12:00:00Z,20,55,1010,sunny,8,S
+2024-01-15T00:00:00Z,10,70,1020,clear,5,N
+2024-01-15T06:00:00Z,5,75,1015,cloudy,12,NE
+`
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestReplayProviderPlaysTraceAtSpeed(t *testing.T) {
+	provider, err := newReplayWeatherProvider(writeTestFile(t, "trace.csv", replayTrace), testStart, 60, false)
+	if err != nil {
+		t.Fatalf("load trace: %v", err)
+	}
+	
+	tests := []struct {
+		after time.Duration
+		want  float64
+	}{
+		{0, 10},
+		{5 * time.Minute, 10},
+		{6 * time.Minute, 5},
+		{11 * time.Minute, 5},
+		{12 * time.Minute, 20},
+		{time.Hour, 20},
+	}
+	for _, tt := range tests {
+		now := testStart.Add(tt.after)
+		weather, err := provider.Current(now, models.WeatherData{})
+		if err != nil {
+			t.Fatalf("current at +%s: %v", tt.after, err)
+		}
+		if weather.Temperature != tt.want {
+			t.Errorf("temperature at +%s = %.1f, want %.1f", tt.after, weather.Temperature, tt.want)
+		}
+		if !weather.Timestamp.Equal(now) {
+			t.Errorf("timestamp at +%s = %s, want %s", tt.after, weather.Timestamp, now)
+		}
+	}
+}
+
+func TestReplayProviderLoops(t *testing.T) {
+	provider, err := newReplayWeatherProvider(writeTestFile(t, "trace.csv", replayTrace), testStart, 60, true)
+	if err != nil {
+		t.Fatalf("load trace: %v", err)
+	}
+	
+	// The loop period is the trace span plus its last gap: 12h + 6h.
+	weather, _ := provider.Current(testStart.Add(18*time.Minute), models.WeatherData{})
+	if weather.Temperature != 10 {
+		t.Errorf("temperature after one loop = %.1f, want 10", weather.Temperature)
+	}
+	weather, _ = provider.Current(testStart.Add(24*time.Minute), models.WeatherData{})
+	if weather.Temperature != 5 {
+		t.Errorf("temperature in second loop = %.1f, want 5", weather.Temperature)
+	}
+	
+	forecast, err := provider.Forecast(testStart.Add(time.Minute), models.WeatherData{}, 1)
+	if err != nil {
+		t.Fatalf("forecast: %v", err)
+	}
+	if len(forecast) != 10 {
+		t.Fatalf("forecast has %d points, want 10", len(forecast))
+	}
+	for i, want := range []struct {
+		at          time.Duration
+		temperature float64
+	}{
+		{6 * time.Minute, 5},
+		{12 * time.Minute, 20},
+		{18 * time.Minute, 10},
+	} {
+		if !forecast[i].Timestamp.Equal(testStart.Add(want.at)) || forecast[i].Temperature != want.temperature {
+			t.Errorf("forecast[%d] = %.1f at %s, want %.1f at %s", i, forecast[i].Temperature, forecast[i].Timestamp, want.temperature, testStart.Add(want.at))
+		}
+	}
+}
+
+func TestReplayProviderForecastEndsWithTrace(t *testing.T) {
+	provider, err := newReplayWeatherProvider(writeTestFile(t, "trace.csv", replayTrace), testStart, 60, false)
+	if err != nil {
+		t.Fatalf("load trace: %v", err)
+	}
+	
+	forecast, _ := provider.Forecast(testStart.Add(7*time.Minute), models.WeatherData{}, 24)
+	if len(forecast) != 1 || forecast[0].Temperature != 20 {
+		t.Errorf("forecast = %+v, want only the 12:00 record", forecast)
+	}
+}
+
+func TestReplayProviderLoadsJSON(t *testing.T) {
+	path := writeTestFile(t, "trace.json", `[
+		{"temperature": 3, "condition": "snowy", "timestamp": "2024-01-15T00:00:00Z"},
+		{"temperature": 4, "condition": "cloudy", "timestamp": "2024-01-15T01:00:00Z"}
+	]`)
+	provider, err := newReplayWeatherProvider(path, testStart, 1, false)
+	if err != nil {
+		t.Fatalf("load trace: %v", err)
+	}
+	
+	weather, _ := provider.Current(testStart.Add(90*time.Minute), models.WeatherData{})
+	if weather.Temperature != 4 || weather.Condition != "cloudy" {
+		t.Errorf("current = %.1f %s, want 4.0 cloudy", weather.Temperature, weather.Condition)
+	}
+}
+
+func TestReplayProviderRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"extension", "trace.txt", replayTrace, "must be .csv or .json"},
+		{"no timestamp column", "trace.csv", "temperature\n20\n", "timestamp column"},
+		{"bad timestamp", "trace.csv", "timestamp,temperature\n2024-01-15 00:00,20\n", "line 2: timestamp must be RFC3339"},
+		{"bad number", "trace.csv", "timestamp,temperature\n2024-01-15T00:00:00Z,warm\n", `line 2: invalid temperature "warm"`},
+		{"empty", "trace.csv", "timestamp,temperature\n", "has no records"},
+		{"json without timestamp", "trace.json", `[{"temperature": 20}]`, "record 1 has no timestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newReplayWeatherProvider(writeTestFile(t, tt.file, tt.content), testStart, 1, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func newWeatherFeedServer(t *testing.T, status *int32, body *atomic.Value) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+		fmt.Fprint(w, body.Load().(string))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHTTPProviderReadsFeed(t *testing.T) {
+	status := int32(http.StatusOK)
+	var body atomic.Value
+	body.Store(`{
+		"current": {"temperature": 31.5, "humidity": 40, "pressure": 1009, "condition": "sunny", "wind_speed": 4, "wind_direction": "S"},
+		"forecast": [
+			{"temperature": 30, "condition": "clear", "timestamp": "2024-01-15T11:00:00Z"},
+			{"temperature": 28, "condition": "clear", "timestamp": "2024-01-15T18:00:00Z"},
+			{"temperature": 22, "condition": "rainy", "timestamp": "2024-01-16T06:00:00Z"},
+			{"temperature": 19, "condition": "rainy", "timestamp": "2024-01-16T18:00:00Z"}
+		]
+	}`)
+	server, requests := newWeatherFeedServer(t, &status, &body)
+	provider := newHTTPWeatherProvider(server.URL, time.Second)
+	
+	weather, err := provider.Current(testStart, models.WeatherData{})
+	if err != nil {
+		t.Fatalf("current: %v", err)
+	}
+	if weather.Temperature != 31.5 || weather.Condition != "sunny" || weather.WindDir != "S" {
+		t.Errorf("current = %+v, want the feed's current conditions", weather)
+	}
+	if !weather.Timestamp.Equal(testStart) {
+		t.Errorf("timestamp = %s, want %s", weather.Timestamp, testStart)
+	}
+	
+	forecast, err := provider.Forecast(testStart, weather, 18)
+	if err != nil {
+		t.Fatalf("forecast: %v", err)
+	}
+	if len(forecast) != 2 || forecast[0].Temperature != 28 || forecast[1].Temperature != 22 {
+		t.Errorf("forecast = %+v, want the two points within 18 hours", forecast)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("feed requested %d times, want the forecast to reuse the last poll", got)
+	}
+}
+
+func TestHTTPProviderRejectsBadFeeds(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int32
+		body   string
+		want   string
+	}{
+		{"status", http.StatusServiceUnavailable, `{}`, "503"},
+		{"invalid JSON", http.StatusOK, `{"current":`, "invalid weather feed"},
+		{"no current", http.StatusOK, `{"forecast": []}`, "no current conditions"},
+		{"forecast without timestamp", http.StatusOK, `{"current": {"temperature": 20}, "forecast": [{"temperature": 21}]}`, "entry 1 has no timestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			var body atomic.Value
+			body.Store(tt.body)
+			server, _ := newWeatherFeedServer(t, &status, &body)
+			provider := newHTTPWeatherProvider(server.URL, time.Second)
+			
+			previous := models.WeatherData{Temperature: 12, Condition: "cloudy"}
+			weather, err := provider.Current(testStart, previous)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want it to mention %q", err, tt.want)
+			}
+			if weather != previous {
+				t.Errorf("current = %+v after a failed poll, want the previous reading", weather)
+			}
+		})
+	}
+}
+
+func TestHTTPProviderKeepsLastFeedWhenPollFails(t *testing.T) {
+	status := int32(http.StatusOK)
+	var body atomic.Value
+	body.Store(`{"current": {"temperature": 18}, "forecast": [{"temperature": 16, "timestamp": "2024-01-15T15:00:00Z"}]}`)
+	server, _ := newWeatherFeedServer(t, &status, &body)
+	provider := newHTTPWeatherProvider(server.URL, time.Second)
+	
+	if _, err := provider.Current(testStart, models.WeatherData{}); err != nil {
+		t.Fatalf("current: %v", err)
+	}
+	atomic.StoreInt32(&status, http.StatusBadGateway)
+	if _, err := provider.Current(testStart.Add(time.Minute), models.WeatherData{}); err == nil {
+		t.Fatal("current succeeded against a failing feed")
+	}
+	
+	forecast, err := provider.Forecast(testStart.Add(time.Minute), models.WeatherData{}, 24)
+	if err != nil || len(forecast) != 1 || forecast[0].Temperature != 16 {
+		t.Errorf("forecast = %+v, %v, want the last good feed", forecast, err)
+	}
+}
+
+func TestHTTPProviderUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	
+	provider := newHTTPWeatherProvider(url, time.Second)
+	if _, err := provider.Current(testStart, models.WeatherData{}); err == nil {
+		t.Error("current succeeded against a closed server")
+	}
+	if _, err := provider.Forecast(testStart, models.WeatherData{}, 24); err == nil {
+		t.Error("forecast succeeded without any feed")
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/utils"
+)
+
+type outlookDay struct {
+	target   models.WeatherData
+	forecast models.WeatherData
+}
+
+type randomWeatherProvider struct {
+	rand    *random.Stream
+	outlook map[string]*outlookDay
+}
+
+func newRandomWeatherProvider(rand *random.Stream) *randomWeatherProvider {
+	return &randomWeatherProvider{
+		rand:    rand,
+		outlook: make(map[string]*outlookDay),
+	}
+}
+
+func (p *randomWeatherProvider) Name() string {
+	return "random"
+}
+
+func (p *randomWeatherProvider) Current(now time.Time, previous models.WeatherData) (models.WeatherData, error) {
+	weather := previous
+	
+	baseTemp := p.calculateBaseTemperature(now, now.Hour())
+	if day, ok := p.outlook[now.Format("2006-01-02")]; ok {
+		baseTemp += day.forecast.Temperature - p.calculateBaseTemperature(day.forecast.Timestamp, 12)
+	}
+	
+	tempVariation := (p.rand.Float64() - 0.5) * 4.0
+	weather.Temperature = baseTemp + tempVariation
+	
+	weather.Humidity = p.calculateHumidity(weather.Humidity)
+	weather.Pressure = p.calculatePressure(weather.Pressure)
+	weather.Condition = p.calculateCondition(weather.Pressure, weather.Humidity)
+	weather.WindSpeed = p.calculateWindSpeed(weather.WindSpeed)
+	weather.WindDir = p.calculateWindDirection(weather.WindDir)
+	weather.Timestamp = now
+	
+	return weather, nil
+}
+
+func (p *randomWeatherProvider) Forecast(now time.Time, current models.WeatherData, hours int) ([]models.WeatherData, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for key, day := range p.outlook {
+		if day.forecast.Timestamp.Before(today) {
+			delete(p.outlook, key)
+		}
+	}
+	
+	days := (hours + 23) / 24
+	forecast := make([]models.WeatherData, days)
+	
+	for i := 0; i < days; i++ {
+		date := today.AddDate(0, 0, i+1)
+		key := date.Format("2006-01-02")
+		
+		day, ok := p.outlook[key]
+		if ok {
+			p.evolve(day, i+1)
+		} else {
+			day = p.newOutlookDay(date.Add(12 * time.Hour))
+			p.outlook[key] = day
+		}
+		forecast[i] = day.forecast
+	}
+	
+	return forecast, nil
+}
+
+func (p *randomWeatherProvider) newOutlookDay(noon time.Time) *outlookDay {
+	baseTemp := p.calculateBaseTemperature(noon, 12)
+	tempVariation := (p.rand.Float64() - 0.5) * 8.0
+	
+	weather := models.WeatherData{
+		Temperature: baseTemp + tempVariation,
+		Humidity:    40.0 + p.rand.Float64()*40.0,
+		Pressure:    1000.0 + p.rand.Float64()*30.0,
+		WindSpeed:   p.rand.Float64() * 20.0,
+		WindDir:     p.calculateWindDirection("N"),
+		Timestamp:   noon,
+	}
+	weather.Condition = p.calculateCondition(weather.Pressure, weather.Humidity)
+	
+	return &outlookDay{target: weather, forecast: weather}
+}
+
+func (p *randomWeatherProvider) evolve(day *outlookDay, lead int) {
+	spread := float64(lead)
+	forecast := &day.forecast
+	target := day.target
+	
+	forecast.Temperature += (target.Temperature-forecast.Temperature)*0.01 + (p.rand.Float64()-0.5)*0.1*spread
+	forecast.Humidity = utils.LimitValue(forecast.Humidity+(target.Humidity-forecast.Humidity)*0.01+(p.rand.Float64()-0.5)*0.5*spread, 30.0, 90.0)
+	forecast.Pressure = utils.LimitValue(forecast.Pressure+(target.Pressure-forecast.Pressure)*0.01+(p.rand.Float64()-0.5)*0.5*spread, 980.0, 1040.0)
+	forecast.WindSpeed = utils.LimitValue(forecast.WindSpeed+(target.WindSpeed-forecast.WindSpeed)*0.01+(p.rand.Float64()-0.5)*0.2*spread, 0.0, 30.0)
+	
+	if p.rand.Float64() < 0.002*spread {
+		forecast.Condition = p.calculateCondition(forecast.Pressure, forecast.Humidity)
+		forecast.WindDir = p.calculateWindDirection(forecast.WindDir)
+	}
+}
+
+func (p *randomWeatherProvider) calculateBaseTemperature(now time.Time, hour int) float64 {
+	dayOfYear := now.YearDay()
+	seasonalVariation := 10.0 * math.Sin(2.0*math.Pi*float64(dayOfYear)/365.0)
+	
+	hourlyVariation := 5.0 * math.Sin(2.0*math.Pi*float64(hour-6)/24.0)
+	
+	baseTemp := 18.0 + seasonalVariation + hourlyVariation
+	
+	return baseTemp
+}
+
+func (p *randomWeatherProvider) calculateHumidity(currentHumidity float64) float64 {
+	variation := (p.rand.Float64() - 0.5) * 10.0
+	newHumidity := currentHumidity + variation
+	
+	if newHumidity < 30.0 {
+		newHumidity = 30.0
+	} else if newHumidity > 90.0 {
+		newHumidity = 90.0
+	}
+	
+	return newHumidity
+}
+
+func (p *randomWeatherProvider) calculatePressure(currentPressure float64) float64 {
+	variation := (p.rand.Float64() - 0.5) * 20.0
+	newPressure := currentPressure + variation
+	
+	if newPressure < 980.0 {
+		newPressure = 980.0
+	} else if newPressure > 1040.0 {
+		newPressure = 1040.0
+	}
+	
+	return newPressure
+}
+
+func (p *randomWeatherProvider) calculateCondition(pressure, humidity float64) string {
+	if pressure < 1000.0 && humidity > 80.0 {
+		conditions := []string{"rainy", "stormy", "cloudy"}
+		return conditions[p.rand.Intn(len(conditions))]
+	} else if pressure < 1010.0 {
+		conditions := []string{"cloudy", "partly_cloudy", "overcast"}
+		return conditions[p.rand.Intn(len(conditions))]
+	} else if humidity < 40.0 {
+		return "clear"
+	} else {
+		conditions := []string{"clear", "partly_cloudy", "sunny"}
+		return conditions[p.rand.Intn(len(conditions))]
+	}
+}
+
+func (p *randomWeatherProvider) calculateWindSpeed(currentSpeed float64) float64 {
+	variation := (p.rand.Float64() - 0.5) * 5.0
+	newSpeed := currentSpeed + variation
+	
+	if newSpeed < 0.0 {
+		newSpeed = 0.0
+	} else if newSpeed > 30.0 {
+		newSpeed = 30.0
+	}
+	
+	return newSpeed
+}
+
+func (p *randomWeatherProvider) calculateWindDirection(current string) string {
+	directions := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+	
+	currentIndex := p.findDirectionIndex(current)
+	
+	change := p.rand.Intn(3) - 1
+	newIndex := (currentIndex + change + len(directions)) % len(directions)
+	
+	return directions[newIndex]
+}
+
+func (p *randomWeatherProvider) findDirectionIndex(direction string) int {
+	directions := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+	for i, dir := range directions {
+		if dir == direction {
+			return i
+		}
+	}
+	return 0
+}
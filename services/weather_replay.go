@@ -0,0 +1,200 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+type replayWeatherProvider struct {
+	records    []models.WeatherData
+	clockStart time.Time
+	speed      float64
+	loop       bool
+	period     time.Duration
+}
+
+func newReplayWeatherProvider(filename string, start time.Time, speed float64, loop bool) (*replayWeatherProvider, error) {
+	var records []models.WeatherData
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = loadWeatherCSV(filename)
+	case ".json":
+		records, err = loadWeatherJSON(filename)
+	default:
+		return nil, fmt.Errorf("weather replay file must be .csv or .json: %s", filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("weather replay file %s has no records", filename)
+	}
+	
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	
+	provider := &replayWeatherProvider{
+		records:    records,
+		clockStart: start,
+		speed:      speed,
+		loop:       loop && len(records) > 1,
+	}
+	if provider.loop {
+		last := len(records) - 1
+		provider.period = records[last].Timestamp.Sub(records[0].Timestamp) + records[last].Timestamp.Sub(records[last-1].Timestamp)
+		provider.loop = provider.period > 0
+	}
+	
+	return provider, nil
+}
+
+func (p *replayWeatherProvider) Name() string {
+	return "replay"
+}
+
+func (p *replayWeatherProvider) Current(now time.Time, previous models.WeatherData) (models.WeatherData, error) {
+	position := p.position(now)
+	if p.loop {
+		position %= p.period
+	}
+	
+	weather := p.records[0]
+	for _, record := range p.records {
+		if record.Timestamp.Sub(p.records[0].Timestamp) > position {
+			break
+		}
+		weather = record
+	}
+	weather.Timestamp = now
+	return weather, nil
+}
+
+func (p *replayWeatherProvider) Forecast(now time.Time, current models.WeatherData, hours int) ([]models.WeatherData, error) {
+	position := p.position(now)
+	end := position + time.Duration(float64(hours)*float64(time.Hour)*p.speed)
+	
+	cycle := time.Duration(0)
+	if p.loop && position > 0 {
+		cycle = position / p.period
+	}
+	
+	forecast := make([]models.WeatherData, 0)
+	for {
+		for _, record := range p.records {
+			at := record.Timestamp.Sub(p.records[0].Timestamp) + cycle*p.period
+			if at <= position {
+				continue
+			}
+			if at > end {
+				return forecast, nil
+			}
+			record.Timestamp = p.clockStart.Add(time.Duration(float64(at) / p.speed))
+			forecast = append(forecast, record)
+		}
+		if !p.loop {
+			return forecast, nil
+		}
+		cycle++
+	}
+}
+
+func (p *replayWeatherProvider) position(now time.Time) time.Duration {
+	return time.Duration(float64(now.Sub(p.clockStart)) * p.speed)
+}
+
+func loadWeatherJSON(filename string) ([]models.WeatherData, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	
+	var records []models.WeatherData
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid weather replay JSON: %v", err)
+	}
+	for i, record := range records {
+		if record.Timestamp.IsZero() {
+			return nil, fmt.Errorf("weather replay record %d has no timestamp", i+1)
+		}
+	}
+	return records, nil
+}
+
+func loadWeatherCSV(filename string) ([]models.WeatherData, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid weather replay CSV: %v", err)
+	}
+	if len(rows) < 2 {
+		return nil, nil
+	}
+	
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["timestamp"]; !ok {
+		return nil, fmt.Errorf("weather replay CSV needs a timestamp column")
+	}
+	
+	records := make([]models.WeatherData, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		number := func(name string) (float64, error) {
+			value := field(name)
+			if value == "" {
+				return 0, nil
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: invalid %s %q", line+2, name, value)
+			}
+			return parsed, nil
+		}
+		
+		timestamp, err := time.Parse(time.RFC3339, field("timestamp"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: timestamp must be RFC3339", line+2)
+		}
+		record := models.WeatherData{
+			Condition: field("condition"),
+			WindDir:   field("wind_direction"),
+			Timestamp: timestamp,
+		}
+		for name, target := range map[string]*float64{
+			"temperature": &record.Temperature,
+			"humidity":    &record.Humidity,
+			"pressure":    &record.Pressure,
+			"wind_speed":  &record.WindSpeed,
+		} {
+			if *target, err = number(name); err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, record)
+	}
+	
+	return records, nil
+}
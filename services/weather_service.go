@@ -1,21 +1,47 @@
 package services
 
 import (
+	"log"
 	"math"
-	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
 	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
 	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
 )
 
+const weatherForecastHours = 7 * 24
+
 type WeatherService struct {
-	store   *storage.MemoryStore
-	current *models.WeatherData
+	store    *storage.MemoryStore
+	current  *models.WeatherData
+	clock    clock.Clock
+	rand     *random.Stream
+	random   *randomWeatherProvider
+	provider WeatherProvider
+	forecast []models.WeatherData
+	mu       sync.RWMutex
+	alerts   map[models.WeatherAlertType]*models.WeatherAlert
+	alertMu  sync.Mutex
+	policies map[models.WeatherPolicy]*models.WeatherPolicyStatus
+	holds    map[string]*policyHold
+	policyMu sync.Mutex
+	config   *config.Config
 }
 
-func NewWeatherService() *WeatherService {
+func NewWeatherService(clk clock.Clock, src *random.Source, cfg *config.Config) *WeatherService {
 	service := &WeatherService{
+		clock:    clk,
+		rand:     src.Stream("weather"),
+		config:   cfg,
+		alerts:   make(map[models.WeatherAlertType]*models.WeatherAlert),
+		policies: make(map[models.WeatherPolicy]*models.WeatherPolicyStatus),
+		holds:    make(map[string]*policyHold),
 		current: &models.WeatherData{
 			Temperature: 20.0,
 			Humidity:    60.0,
@@ -23,145 +49,97 @@ func NewWeatherService() *WeatherService {
 			Condition:   "clear",
 			WindSpeed:   5.0,
 			WindDir:     "N",
-			Timestamp:   time.Now(),
+			Timestamp:   clk.Now(),
 		},
 	}
+	service.random = newRandomWeatherProvider(service.rand)
+	
+	provider, err := NewWeatherProvider(clk, service.rand, cfg)
+	if err != nil {
+		log.Printf("Weather provider %q unavailable, using random weather: %v", cfg.Weather.Provider, err)
+		provider = service.random
+	}
+	service.provider = provider
+	if provider != service.random {
+		service.updateWeather()
+	} else {
+		service.refreshForecast(clk.Now())
+	}
+	log.Printf("Using %s weather provider", provider.Name())
 	
 	return service
 }
 
+func (w *WeatherService) Reset() {
+	w.policyMu.Lock()
+	w.policies = make(map[models.WeatherPolicy]*models.WeatherPolicyStatus)
+	w.holds = make(map[string]*policyHold)
+	w.policyMu.Unlock()
+	
+	w.alertMu.Lock()
+	w.alerts = make(map[models.WeatherAlertType]*models.WeatherAlert)
+	w.alertMu.Unlock()
+	
+	w.store.UpdateWeather(w.current)
+	w.evaluateAlerts()
+}
+
 func (w *WeatherService) SetStore(store *storage.MemoryStore) {
 	w.store = store
 	w.store.UpdateWeather(w.current)
+	w.evaluateAlerts()
 	go w.startWeatherUpdates()
 }
 
 func (w *WeatherService) startWeatherUpdates() {
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := w.clock.NewTicker(time.Duration(w.config.WeatherUpdateInterval) * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			w.updateWeather()
 		}
 	}
 }
 
 func (w *WeatherService) updateWeather() {
-	now := time.Now()
-	hour := now.Hour()
+	now := w.clock.Now()
 	
-	baseTemp := w.calculateBaseTemperature(hour)
-	
-	tempVariation := (rand.Float64() - 0.5) * 4.0
-	w.current.Temperature = baseTemp + tempVariation
-	
-	w.current.Humidity = w.calculateHumidity()
-	w.current.Pressure = w.calculatePressure()
-	w.current.Condition = w.calculateCondition()
-	w.current.WindSpeed = w.calculateWindSpeed()
-	w.current.WindDir = w.calculateWindDirection()
-	w.current.Timestamp = now
+	weather, err := w.provider.Current(now, *w.current)
+	if err != nil {
+		log.Printf("Weather update from %s provider failed: %v", w.provider.Name(), err)
+		w.expireAlerts()
+		return
+	}
+	weather.Timestamp = now
+	*w.current = weather
+	w.refreshForecast(now)
 	
 	if w.store != nil {
 		w.store.UpdateWeather(w.current)
 	}
+	w.evaluateAlerts()
 }
 
-func (w *WeatherService) calculateBaseTemperature(hour int) float64 {
-	dayOfYear := time.Now().YearDay()
-	seasonalVariation := 10.0 * math.Sin(2.0*math.Pi*float64(dayOfYear)/365.0)
-	
-	hourlyVariation := 5.0 * math.Sin(2.0*math.Pi*float64(hour-6)/24.0)
-	
-	baseTemp := 18.0 + seasonalVariation + hourlyVariation
-	
-	return baseTemp
-}
-
-func (w *WeatherService) calculateHumidity() float64 {
-	currentHumidity := w.current.Humidity
-	
-	variation := (rand.Float64() - 0.5) * 10.0
-	newHumidity := currentHumidity + variation
-	
-	if newHumidity < 30.0 {
-		newHumidity = 30.0
-	} else if newHumidity > 90.0 {
-		newHumidity = 90.0
+func (w *WeatherService) refreshForecast(now time.Time) {
+	points, err := w.provider.Forecast(now, *w.current, weatherForecastHours)
+	if err != nil {
+		log.Printf("Weather forecast from %s provider failed: %v", w.provider.Name(), err)
+		return
 	}
 	
-	return newHumidity
-}
-
-func (w *WeatherService) calculatePressure() float64 {
-	currentPressure := w.current.Pressure
-	
-	variation := (rand.Float64() - 0.5) * 20.0
-	newPressure := currentPressure + variation
-	
-	if newPressure < 980.0 {
-		newPressure = 980.0
-	} else if newPressure > 1040.0 {
-		newPressure = 1040.0
-	}
-	
-	return newPressure
-}
-
-func (w *WeatherService) calculateCondition() string {
-	pressure := w.current.Pressure
-	humidity := w.current.Humidity
-	
-	if pressure < 1000.0 && humidity > 80.0 {
-		conditions := []string{"rainy", "stormy", "cloudy"}
-		return conditions[rand.Intn(len(conditions))]
-	} else if pressure < 1010.0 {
-		conditions := []string{"cloudy", "partly_cloudy", "overcast"}
-		return conditions[rand.Intn(len(conditions))]
-	} else if humidity < 40.0 {
-		return "clear"
-	} else {
-		conditions := []string{"clear", "partly_cloudy", "sunny"}
-		return conditions[rand.Intn(len(conditions))]
-	}
-}
-
-func (w *WeatherService) calculateWindSpeed() float64 {
-	currentSpeed := w.current.WindSpeed
-	
-	variation := (rand.Float64() - 0.5) * 5.0
-	newSpeed := currentSpeed + variation
-	
-	if newSpeed < 0.0 {
-		newSpeed = 0.0
-	} else if newSpeed > 30.0 {
-		newSpeed = 30.0
-	}
-	
-	return newSpeed
-}
-
-func (w *WeatherService) calculateWindDirection() string {
-	directions := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
-	
-	currentIndex := w.findDirectionIndex(w.current.WindDir)
-	
-	change := rand.Intn(3) - 1
-	newIndex := (currentIndex + change + len(directions)) % len(directions)
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
 	
-	return directions[newIndex]
+	w.mu.Lock()
+	w.forecast = points
+	w.mu.Unlock()
 }
 
-func (w *WeatherService) findDirectionIndex(direction string) int {
-	directions := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
-	for i, dir := range directions {
-		if dir == direction {
-			return i
-		}
-	}
-	return 0
+func (w *WeatherService) ProviderName() string {
+	return w.provider.Name()
 }
 
 func (w *WeatherService) GetCurrentWeather() *models.WeatherData {
@@ -170,149 +148,235 @@ func (w *WeatherService) GetCurrentWeather() *models.WeatherData {
 
 func (w *WeatherService) SetWeather(weather *models.WeatherData) {
 	w.current = weather
-	w.current.Timestamp = time.Now()
+	w.current.Timestamp = w.clock.Now()
 	
 	if w.store != nil {
 		w.store.UpdateWeather(w.current)
 	}
+	w.evaluateAlerts()
 }
 
 func (w *WeatherService) SimulateWeatherScenario(scenario string) {
 	switch scenario {
 	case "storm":
-		w.current.Temperature = 15.0 + rand.Float64()*5.0
-		w.current.Humidity = 85.0 + rand.Float64()*10.0
-		w.current.Pressure = 980.0 + rand.Float64()*20.0
+		w.current.Temperature = 15.0 + w.rand.Float64()*5.0
+		w.current.Humidity = 85.0 + w.rand.Float64()*10.0
+		w.current.Pressure = 980.0 + w.rand.Float64()*20.0
 		w.current.Condition = "stormy"
-		w.current.WindSpeed = 20.0 + rand.Float64()*10.0
+		w.current.WindSpeed = 20.0 + w.rand.Float64()*10.0
 		w.current.WindDir = "SW"
 		
 	case "heatwave":
-		w.current.Temperature = 35.0 + rand.Float64()*10.0
-		w.current.Humidity = 20.0 + rand.Float64()*15.0
-		w.current.Pressure = 1020.0 + rand.Float64()*10.0
+		w.current.Temperature = 35.0 + w.rand.Float64()*10.0
+		w.current.Humidity = 20.0 + w.rand.Float64()*15.0
+		w.current.Pressure = 1020.0 + w.rand.Float64()*10.0
 		w.current.Condition = "clear"
-		w.current.WindSpeed = 2.0 + rand.Float64()*5.0
+		w.current.WindSpeed = 2.0 + w.rand.Float64()*5.0
 		w.current.WindDir = "S"
 		
 	case "cold_snap":
-		w.current.Temperature = -5.0 + rand.Float64()*10.0
-		w.current.Humidity = 40.0 + rand.Float64()*20.0
-		w.current.Pressure = 1030.0 + rand.Float64()*10.0
+		w.current.Temperature = -5.0 + w.rand.Float64()*10.0
+		w.current.Humidity = 40.0 + w.rand.Float64()*20.0
+		w.current.Pressure = 1030.0 + w.rand.Float64()*10.0
 		w.current.Condition = "clear"
-		w.current.WindSpeed = 15.0 + rand.Float64()*10.0
+		w.current.WindSpeed = 15.0 + w.rand.Float64()*10.0
 		w.current.WindDir = "N"
 		
 	case "rain":
-		w.current.Temperature = 12.0 + rand.Float64()*8.0
-		w.current.Humidity = 80.0 + rand.Float64()*15.0
-		w.current.Pressure = 995.0 + rand.Float64()*15.0
+		w.current.Temperature = 12.0 + w.rand.Float64()*8.0
+		w.current.Humidity = 80.0 + w.rand.Float64()*15.0
+		w.current.Pressure = 995.0 + w.rand.Float64()*15.0
 		w.current.Condition = "rainy"
-		w.current.WindSpeed = 8.0 + rand.Float64()*7.0
+		w.current.WindSpeed = 8.0 + w.rand.Float64()*7.0
 		w.current.WindDir = "W"
 		
 	case "fog":
-		w.current.Temperature = 8.0 + rand.Float64()*5.0
-		w.current.Humidity = 95.0 + rand.Float64()*5.0
-		w.current.Pressure = 1015.0 + rand.Float64()*5.0
+		w.current.Temperature = 8.0 + w.rand.Float64()*5.0
+		w.current.Humidity = 95.0 + w.rand.Float64()*5.0
+		w.current.Pressure = 1015.0 + w.rand.Float64()*5.0
 		w.current.Condition = "foggy"
-		w.current.WindSpeed = 1.0 + rand.Float64()*2.0
+		w.current.WindSpeed = 1.0 + w.rand.Float64()*2.0
 		w.current.WindDir = "Calm"
 		
 	default:
-		w.current.Temperature = 20.0 + rand.Float64()*10.0
-		w.current.Humidity = 50.0 + rand.Float64()*20.0
-		w.current.Pressure = 1013.0 + rand.Float64()*10.0
+		w.current.Temperature = 20.0 + w.rand.Float64()*10.0
+		w.current.Humidity = 50.0 + w.rand.Float64()*20.0
+		w.current.Pressure = 1013.0 + w.rand.Float64()*10.0
 		w.current.Condition = "clear"
-		w.current.WindSpeed = 5.0 + rand.Float64()*5.0
+		w.current.WindSpeed = 5.0 + w.rand.Float64()*5.0
 		w.current.WindDir = "N"
 	}
 	
-	w.current.Timestamp = time.Now()
+	w.current.Timestamp = w.clock.Now()
 	
 	if w.store != nil {
 		w.store.UpdateWeather(w.current)
 	}
+	w.evaluateAlerts()
 }
 
 func (w *WeatherService) GetForecast(days int) []models.WeatherData {
-	forecast := make([]models.WeatherData, days)
+	now := w.clock.Now()
+	points := w.forecastPoints()
 	
+	forecast := make([]models.WeatherData, days)
+	previous := *w.current
 	for i := 0; i < days; i++ {
-		futureDate := time.Now().AddDate(0, 0, i+1)
+		date := now.AddDate(0, 0, i+1)
+		noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, date.Location())
 		
-		baseTemp := w.calculateBaseTemperature(12)
-		tempVariation := (rand.Float64() - 0.5) * 8.0
+		best := -1
+		for j, point := range points {
+			if point.Timestamp.YearDay() != noon.YearDay() || point.Timestamp.Year() != noon.Year() {
+				continue
+			}
+			if best < 0 || absDuration(point.Timestamp.Sub(noon)) < absDuration(points[best].Timestamp.Sub(noon)) {
+				best = j
+			}
+		}
 		
-		forecast[i] = models.WeatherData{
-			Temperature: baseTemp + tempVariation,
-			Humidity:    40.0 + rand.Float64()*40.0,
-			Pressure:    1000.0 + rand.Float64()*30.0,
-			Condition:   w.calculateCondition(),
-			WindSpeed:   rand.Float64() * 20.0,
-			WindDir:     w.calculateWindDirection(),
-			Timestamp:   futureDate,
+		if best >= 0 {
+			previous = points[best]
+		} else {
+			previous.Timestamp = noon
 		}
+		forecast[i] = previous
 	}
 	
 	return forecast
 }
 
-func (w *WeatherService) GetWeatherHistory(hours int) []models.WeatherData {
-	history := make([]models.WeatherData, hours)
+func (w *WeatherService) HourlyTemperatures(from time.Time, hours int) []float64 {
+	anchors := append([]models.WeatherData{*w.current}, w.forecastPoints()...)
+	anchors[0].Timestamp = w.clock.Now()
 	
-	for i := 0; i < hours; i++ {
-		pastTime := time.Now().Add(-time.Duration(hours-i) * time.Hour)
-		hour := pastTime.Hour()
+	temperatures := make([]float64, hours)
+	for i := range temperatures {
+		t := from.Add(time.Duration(i) * time.Hour)
 		
-		baseTemp := w.calculateBaseTemperature(hour)
-		tempVariation := (rand.Float64() - 0.5) * 6.0
+		before, after := anchors[0], anchors[0]
+		for _, anchor := range anchors {
+			if !anchor.Timestamp.After(t) {
+				before, after = anchor, anchor
+				continue
+			}
+			after = anchor
+			break
+		}
 		
-		history[i] = models.WeatherData{
-			Temperature: baseTemp + tempVariation,
-			Humidity:    45.0 + rand.Float64()*35.0,
-			Pressure:    1005.0 + rand.Float64()*25.0,
-			Condition:   w.calculateCondition(),
-			WindSpeed:   rand.Float64() * 15.0,
-			WindDir:     w.calculateWindDirection(),
-			Timestamp:   pastTime,
+		offset := before.Temperature - diurnalVariation(before.Timestamp)
+		if after.Timestamp.After(before.Timestamp) {
+			weight := t.Sub(before.Timestamp).Seconds() / after.Timestamp.Sub(before.Timestamp).Seconds()
+			if weight < 0 {
+				weight = 0
+			}
+			next := after.Temperature - diurnalVariation(after.Timestamp)
+			offset += (next - offset) * weight
 		}
+		temperatures[i] = offset + diurnalVariation(t)
 	}
 	
-	return history
+	return temperatures
 }
 
-func (w *WeatherService) IsExtremeWeather() bool {
-	return w.current.Temperature < 0.0 || w.current.Temperature > 35.0 ||
-		w.current.WindSpeed > 20.0 || w.current.Condition == "stormy" ||
-		w.current.Pressure < 990.0
+func (w *WeatherService) forecastPoints() []models.WeatherData {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	
+	return append([]models.WeatherData(nil), w.forecast...)
 }
 
-func (w *WeatherService) GetWeatherAlert() *string {
-	if w.current.Temperature > 35.0 {
-		alert := "Extreme heat warning - Temperature above 35°C"
-		return &alert
+func (w *WeatherService) GetWeatherHistory(hours int) []models.WeatherHistoryPoint {
+	history := make([]models.WeatherHistoryPoint, 0)
+	if w.store == nil {
+		return history
 	}
 	
-	if w.current.Temperature < 0.0 {
-		alert := "Freezing temperature alert - Temperature below 0°C"
-		return &alert
+	now := w.clock.Now()
+	from := now.Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+	samples := w.store.GetWeatherSamples(from, now.Add(time.Nanosecond))
+	
+	for start := 0; start < len(samples); {
+		hour := samples[start].Timestamp.Truncate(time.Hour)
+		end := start
+		for end < len(samples) && samples[end].Timestamp.Truncate(time.Hour).Equal(hour) {
+			end++
+		}
+		history = append(history, aggregateWeather(hour, samples[start:end]))
+		start = end
 	}
 	
-	if w.current.WindSpeed > 20.0 {
-		alert := "High wind warning - Wind speed above 20 km/h"
-		return &alert
+	return history
+}
+
+func aggregateWeather(hour time.Time, samples []models.WeatherData) models.WeatherHistoryPoint {
+	point := models.WeatherHistoryPoint{
+		Hour:           hour,
+		Samples:        len(samples),
+		TemperatureMin: samples[0].Temperature,
+		TemperatureMax: samples[0].Temperature,
 	}
 	
-	if w.current.Condition == "stormy" {
-		alert := "Storm warning - Severe weather conditions"
-		return &alert
+	conditions := make(map[string]int)
+	directions := make(map[string]int)
+	for _, sample := range samples {
+		point.Temperature += sample.Temperature
+		point.Humidity += sample.Humidity
+		point.Pressure += sample.Pressure
+		point.WindSpeed += sample.WindSpeed
+		point.TemperatureMin = math.Min(point.TemperatureMin, sample.Temperature)
+		point.TemperatureMax = math.Max(point.TemperatureMax, sample.Temperature)
+		point.WindSpeedMax = math.Max(point.WindSpeedMax, sample.WindSpeed)
+		conditions[sample.Condition]++
+		directions[sample.WindDir]++
 	}
 	
-	if w.current.Pressure < 990.0 {
-		alert := "Low pressure system - Potential weather instability"
-		return &alert
+	count := float64(len(samples))
+	point.Temperature = utils.RoundToDecimal(point.Temperature/count, 2)
+	point.Humidity = utils.RoundToDecimal(point.Humidity/count, 2)
+	point.Pressure = utils.RoundToDecimal(point.Pressure/count, 2)
+	point.WindSpeed = utils.RoundToDecimal(point.WindSpeed/count, 2)
+	point.TemperatureMin = utils.RoundToDecimal(point.TemperatureMin, 2)
+	point.TemperatureMax = utils.RoundToDecimal(point.TemperatureMax, 2)
+	point.WindSpeedMax = utils.RoundToDecimal(point.WindSpeedMax, 2)
+	
+	for i := len(samples) - 1; i >= 0; i-- {
+		if conditions[samples[i].Condition] > conditions[point.Condition] {
+			point.Condition = samples[i].Condition
+		}
+		if directions[samples[i].WindDir] > directions[point.WindDir] {
+			point.WindDir = samples[i].WindDir
+		}
 	}
 	
-	return nil
-}
\ No newline at end of file
+	return point
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (w *WeatherService) IsExtremeWeather() bool {
+	return w.current.Temperature < 0.0 || w.current.Temperature > 35.0 ||
+		w.current.WindSpeed > 20.0 || w.current.Condition == "stormy" ||
+		w.current.Pressure < 990.0
+}
+
+func (w *WeatherService) GetWeatherAlert() *string {
+	alerts := w.ActiveAlerts()
+	if len(alerts) == 0 {
+		return nil
+	}
+	
+	alert := alerts[0]
+	for _, candidate := range alerts {
+		if candidate.Severity == "critical" {
+			alert = candidate
+			break
+		}
+	}
+	return &alert.Message
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/storage"
+)
+
+func newTestWeatherUpdates(t *testing.T) (*WeatherService, *clock.Simulated) {
+	clk := newTestClock(t)
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	// Keep the poller idle; tests update the weather explicitly.
+	cfg.WeatherUpdateInterval = 30 * 24 * 3600
+	
+	weather := NewWeatherService(clk, random.NewSource(1), cfg)
+	weather.SetStore(storage.NewMemoryStore(clk))
+	return weather, clk
+}
+
+func TestForecastIsStableBetweenUpdates(t *testing.T) {
+	weather, clk := newTestWeatherUpdates(t)
+	
+	forecast := weather.GetForecast(5)
+	for i, day := range forecast {
+		noon := time.Date(2024, 1, 16+i, 12, 0, 0, 0, time.UTC)
+		if !day.Timestamp.Equal(noon) {
+			t.Errorf("day %d at %s, want %s", i+1, day.Timestamp, noon)
+		}
+	}
+	if again := weather.GetForecast(5); !reflect.DeepEqual(again, forecast) {
+		t.Fatalf("forecast changed between reads:\n%+v\n%+v", forecast, again)
+	}
+	
+	// Temperatures only move by a few degrees as the day approaches.
+	clk.Step(time.Hour)
+	weather.updateWeather()
+	for i, day := range weather.GetForecast(5) {
+		if diff := day.Temperature - forecast[i].Temperature; diff > 5 || diff < -5 {
+			t.Errorf("day %d moved from %.1f°C to %.1f°C after one update", i+1, forecast[i].Temperature, day.Temperature)
+		}
+	}
+}
+
+func TestHistoryRecordsObservedWeather(t *testing.T) {
+	weather, clk := newTestWeatherUpdates(t)
+	
+	var temperatures []float64
+	for i := 0; i < 6; i++ {
+		clk.Step(20 * time.Minute)
+		weather.updateWeather()
+		temperatures = append(temperatures, weather.GetCurrentWeather().Temperature)
+	}
+	
+	// Samples at 12:20 and 12:40, 13:00 to 13:40, then 14:00.
+	history := weather.GetWeatherHistory(3)
+	if len(history) != 3 {
+		t.Fatalf("history = %+v, want three hours", history)
+	}
+	for i, want := range []int{3, 3, 1} {
+		if history[i].Samples != want || !history[i].Hour.Equal(testStart.Add(time.Duration(i)*time.Hour)) {
+			t.Errorf("hour %d = %d samples at %s, want %d", i, history[i].Samples, history[i].Hour, want)
+		}
+	}
+	
+	// Averages are rounded to two decimals.
+	if got, want := history[2].Temperature, math.Round(temperatures[5]*100)/100; got != want {
+		t.Errorf("14:00 temperature = %v, want the observed %v", got, want)
+	}
+	if again := weather.GetWeatherHistory(3); !reflect.DeepEqual(again, history) {
+		t.Error("history changed between reads")
+	}
+}
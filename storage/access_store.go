@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (s *MemoryStore) AddUser(user *models.SecurityUser) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if _, exists := s.users[user.ID]; exists {
+		return fmt.Errorf("user with ID %s already exists", user.ID)
+	}
+	
+	user.CreatedAt = s.clock.Now()
+	s.users[user.ID] = user
+	
+	s.addSystemEvent("user_added", "storage", fmt.Sprintf("Security user %s added", user.Name), map[string]interface{}{
+		"user_id": user.ID,
+	})
+	
+	return nil
+}
+
+func (s *MemoryStore) GetUser(id string) (*models.SecurityUser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	user, exists := s.users[id]
+	if !exists {
+		return nil, fmt.Errorf("user with ID %s not found", id)
+	}
+	
+	return user, nil
+}
+
+func (s *MemoryStore) ListUsers() []*models.SecurityUser {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	users := make([]*models.SecurityUser, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	
+	return users
+}
+
+func (s *MemoryStore) DeleteUser(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	user, exists := s.users[id]
+	if !exists {
+		return fmt.Errorf("user with ID %s not found", id)
+	}
+	
+	delete(s.users, id)
+	
+	s.addSystemEvent("user_deleted", "storage", fmt.Sprintf("Security user %s deleted", user.Name), map[string]interface{}{
+		"user_id": user.ID,
+	})
+	
+	return nil
+}
+
+func (s *MemoryStore) SetUserLockCode(userID, lockID, codeHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	user, exists := s.users[userID]
+	if !exists {
+		return fmt.Errorf("user with ID %s not found", userID)
+	}
+	
+	if user.LockCodes == nil {
+		user.LockCodes = make(map[string]string)
+	}
+	
+	if codeHash == "" {
+		delete(user.LockCodes, lockID)
+	} else {
+		user.LockCodes[lockID] = codeHash
+	}
+	
+	user.Locks = make([]string, 0, len(user.LockCodes))
+	for id := range user.LockCodes {
+		user.Locks = append(user.Locks, id)
+	}
+	
+	return nil
+}
+
+func (s *MemoryStore) AddAuditEntry(entry models.AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.auditLog = append(s.auditLog, entry)
+	
+	if len(s.auditLog) > 1000 {
+		s.auditLog = s.auditLog[len(s.auditLog)-1000:]
+	}
+}
+
+func (s *MemoryStore) GetAuditLog(limit int) []models.AuditEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	if limit <= 0 || limit > len(s.auditLog) {
+		limit = len(s.auditLog)
+	}
+	
+	result := make([]models.AuditEntry, limit)
+	copy(result, s.auditLog[len(s.auditLog)-limit:])
+	return result
+}
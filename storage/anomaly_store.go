@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (s *MemoryStore) AddAnomaly(anomaly models.EnergyAnomaly) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.anomalies = append(s.anomalies, anomaly)
+	if len(s.anomalies) > 500 {
+		s.anomalies = s.anomalies[len(s.anomalies)-500:]
+	}
+}
+
+func (s *MemoryStore) UpdateAnomaly(anomaly models.EnergyAnomaly) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for i := range s.anomalies {
+		if s.anomalies[i].ID == anomaly.ID {
+			s.anomalies[i] = anomaly
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("anomaly with ID %s not found", anomaly.ID)
+}
+
+func (s *MemoryStore) GetAnomaly(anomalyID string) (models.EnergyAnomaly, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	for _, anomaly := range s.anomalies {
+		if anomaly.ID == anomalyID {
+			return anomaly, nil
+		}
+	}
+	
+	return models.EnergyAnomaly{}, fmt.Errorf("anomaly with ID %s not found", anomalyID)
+}
+
+func (s *MemoryStore) GetAnomalies(limit int) []models.EnergyAnomaly {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	anomalies := make([]models.EnergyAnomaly, 0)
+	for i := len(s.anomalies) - 1; i >= 0; i-- {
+		anomalies = append(anomalies, s.anomalies[i])
+		if limit > 0 && len(anomalies) >= limit {
+			break
+		}
+	}
+	
+	return anomalies
+}
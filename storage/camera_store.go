@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (s *MemoryStore) AddCameraEvent(event models.CameraEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.cameraEvents = append(s.cameraEvents, event)
+}
+
+func (s *MemoryStore) UpdateCameraEvent(event models.CameraEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for i := range s.cameraEvents {
+		if s.cameraEvents[i].ID == event.ID {
+			s.cameraEvents[i] = event
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("camera event with ID %s not found", event.ID)
+}
+
+func (s *MemoryStore) GetCameraEvent(cameraID, eventID string) (models.CameraEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	for _, event := range s.cameraEvents {
+		if event.ID == eventID && event.CameraID == cameraID {
+			return event, nil
+		}
+	}
+	
+	return models.CameraEvent{}, fmt.Errorf("camera event with ID %s not found", eventID)
+}
+
+func (s *MemoryStore) GetCameraEvents(cameraID string, limit int) []models.CameraEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	events := make([]models.CameraEvent, 0)
+	for i := len(s.cameraEvents) - 1; i >= 0; i-- {
+		if cameraID != "" && s.cameraEvents[i].CameraID != cameraID {
+			continue
+		}
+		events = append(events, s.cameraEvents[i])
+		if limit > 0 && len(events) >= limit {
+			break
+		}
+	}
+	
+	return events
+}
+
+func (s *MemoryStore) DeleteCameraEvent(eventID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for i := range s.cameraEvents {
+		if s.cameraEvents[i].ID == eventID {
+			s.cameraEvents = append(s.cameraEvents[:i], s.cameraEvents[i+1:]...)
+			return
+		}
+	}
+}
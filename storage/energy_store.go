@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (s *MemoryStore) SetEnergyRetention(keep time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.energyKeep = keep
+}
+
+func (s *MemoryStore) GetEnergyUsageBetween(from, to time.Time) []models.EnergyUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	var usage []models.EnergyUsage
+	for _, sample := range s.energyUsage {
+		if !sample.Timestamp.Before(from) && sample.Timestamp.Before(to) {
+			usage = append(usage, sample)
+		}
+	}
+	return usage
+}
+
+func (s *MemoryStore) EarliestEnergyUsage() (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	if len(s.energyUsage) == 0 {
+		return time.Time{}, false
+	}
+	return s.energyUsage[0].Timestamp, true
+}
+
+func (s *MemoryStore) AddEnergyFlow(flow models.EnergyFlow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.energyFlows = append(s.energyFlows, flow)
+	
+	cutoff := flow.Timestamp.Add(-s.energyKeep)
+	expired := 0
+	for expired < len(s.energyFlows) && s.energyFlows[expired].Timestamp.Before(cutoff) {
+		expired++
+	}
+	s.energyFlows = s.energyFlows[expired:]
+}
+
+func (s *MemoryStore) GetEnergyFlowsBetween(from, to time.Time) []models.EnergyFlow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	var flows []models.EnergyFlow
+	for _, flow := range s.energyFlows {
+		if !flow.Timestamp.Before(from) && flow.Timestamp.Before(to) {
+			flows = append(flows, flow)
+		}
+	}
+	return flows
+}
+
+func (s *MemoryStore) AddEnergyRollups(rollups []models.EnergyRollup, keepSince time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	kept := s.rollups[:0]
+	for _, rollup := range s.rollups {
+		if !rollup.Hour.Before(keepSince) {
+			kept = append(kept, rollup)
+		}
+	}
+	s.rollups = append(kept, rollups...)
+}
+
+func (s *MemoryStore) GetEnergyRollups(since time.Time) []models.EnergyRollup {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	var rollups []models.EnergyRollup
+	for _, rollup := range s.rollups {
+		if !rollup.Hour.Before(since) {
+			rollups = append(rollups, rollup)
+		}
+	}
+	return rollups
+}
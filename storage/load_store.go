@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"fmt"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (s *MemoryStore) AddShedAction(action models.ShedAction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.shedActions = append(s.shedActions, action)
+	if len(s.shedActions) > 500 {
+		s.shedActions = s.shedActions[len(s.shedActions)-500:]
+	}
+}
+
+func (s *MemoryStore) UpdateShedAction(action models.ShedAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for i := range s.shedActions {
+		if s.shedActions[i].ID == action.ID {
+			s.shedActions[i] = action
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("shed action with ID %s not found", action.ID)
+}
+
+func (s *MemoryStore) GetShedActions(limit int) []models.ShedAction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	actions := make([]models.ShedAction, 0)
+	for i := len(s.shedActions) - 1; i >= 0; i-- {
+		actions = append(actions, s.shedActions[i])
+		if limit > 0 && len(actions) >= limit {
+			break
+		}
+	}
+	
+	return actions
+}
@@ -7,29 +7,60 @@ import (
 	"sync"
 	"time"
 
+	"multi-agent-framework-testing/clock"
 	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/utils"
 )
 
 type MemoryStore struct {
 	devices      map[string]*models.Device
 	weather      *models.WeatherData
+	weatherSamples []models.WeatherData
+	weatherAlerts []models.WeatherAlert
 	security     *models.SecuritySystem
 	tasks        map[string]*models.ScheduledTask
 	energyUsage  []models.EnergyUsage
+	energyKeep   time.Duration
+	energyFlows  []models.EnergyFlow
+	rollups      []models.EnergyRollup
+	anomalies    []models.EnergyAnomaly
 	systemEvents []models.SystemEvent
+	taskHistory  map[string][]models.TaskRun
+	taskFile     string
+	users        map[string]*models.SecurityUser
+	auditLog     []models.AuditEntry
+	notifications []models.Notification
+	cameraEvents []models.CameraEvent
+	safetyAlarms []models.LifeSafetyAlarm
+	shedActions  []models.ShedAction
 	mu           sync.RWMutex
 	startTime    time.Time
+	clock        clock.Clock
 }
 
-func NewMemoryStore() *MemoryStore {
+func NewMemoryStore(clk clock.Clock) *MemoryStore {
 	return &MemoryStore{
 		devices:      make(map[string]*models.Device),
 		weather:      &models.WeatherData{},
+		weatherSamples: make([]models.WeatherData, 0),
+		weatherAlerts: make([]models.WeatherAlert, 0),
 		security:     &models.SecuritySystem{State: models.SecurityStateDisarmed},
 		tasks:        make(map[string]*models.ScheduledTask),
 		energyUsage:  make([]models.EnergyUsage, 0),
+		energyKeep:   48 * time.Hour,
+		energyFlows:  make([]models.EnergyFlow, 0),
+		rollups:      make([]models.EnergyRollup, 0),
+		anomalies:    make([]models.EnergyAnomaly, 0),
 		systemEvents: make([]models.SystemEvent, 0),
-		startTime:    time.Now(),
+		taskHistory:  make(map[string][]models.TaskRun),
+		users:        make(map[string]*models.SecurityUser),
+		auditLog:     make([]models.AuditEntry, 0),
+		notifications: make([]models.Notification, 0),
+		cameraEvents: make([]models.CameraEvent, 0),
+		safetyAlarms: make([]models.LifeSafetyAlarm, 0),
+		shedActions:  make([]models.ShedAction, 0),
+		startTime:    clk.Now(),
+		clock:        clk,
 	}
 }
 
@@ -41,8 +72,8 @@ func (s *MemoryStore) AddDevice(device *models.Device) error {
 		return fmt.Errorf("device with ID %s already exists", device.ID)
 	}
 	
-	device.CreatedAt = time.Now()
-	device.LastUpdated = time.Now()
+	device.CreatedAt = s.clock.Now()
+	device.LastUpdated = s.clock.Now()
 	s.devices[device.ID] = device
 	
 	s.addSystemEvent("device_added", "storage", fmt.Sprintf("Device %s added", device.Name), map[string]interface{}{
@@ -97,7 +128,7 @@ func (s *MemoryStore) UpdateDevice(id string, updates map[string]interface{}) er
 		}
 	}
 	
-	device.LastUpdated = time.Now()
+	device.LastUpdated = s.clock.Now()
 	
 	s.addSystemEvent("device_updated", "storage", fmt.Sprintf("Device %s updated", device.Name), map[string]interface{}{
 		"device_id": device.ID,
@@ -142,6 +173,7 @@ func (s *MemoryStore) UpdateWeather(weather *models.WeatherData) {
 	defer s.mu.Unlock()
 	
 	s.weather = weather
+	s.recordWeatherSample(*weather)
 	
 	s.addSystemEvent("weather_updated", "storage", "Weather data updated", map[string]interface{}{
 		"temperature": weather.Temperature,
@@ -183,8 +215,9 @@ func (s *MemoryStore) AddTask(task *models.ScheduledTask) error {
 		return fmt.Errorf("task with ID %s already exists", task.ID)
 	}
 	
-	task.CreatedAt = time.Now()
+	task.CreatedAt = s.clock.Now()
 	s.tasks[task.ID] = task
+	s.persistTasks()
 	
 	s.addSystemEvent("task_added", "storage", fmt.Sprintf("Scheduled task %s added", task.Name), map[string]interface{}{
 		"task_id": task.ID,
@@ -242,21 +275,57 @@ func (s *MemoryStore) UpdateTask(id string, updates map[string]interface{}) erro
 			if lastRun, ok := value.(time.Time); ok {
 				task.LastRun = lastRun
 			}
+		case "missed_runs":
+			if missed, ok := value.(int); ok {
+				task.MissedRuns = missed
+			}
 		}
 	}
 	
+	s.persistTasks()
+	
 	return nil
 }
 
+func (s *MemoryStore) AddTaskRun(run models.TaskRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	history := append(s.taskHistory[run.TaskID], run)
+	if len(history) > 200 {
+		history = history[len(history)-200:]
+	}
+	s.taskHistory[run.TaskID] = history
+	
+	s.persistTasks()
+}
+
+func (s *MemoryStore) GetTaskHistory(taskID string, limit int) []models.TaskRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	history := s.taskHistory[taskID]
+	if limit <= 0 || limit > len(history) {
+		limit = len(history)
+	}
+	
+	result := make([]models.TaskRun, limit)
+	copy(result, history[len(history)-limit:])
+	return result
+}
+
 func (s *MemoryStore) AddEnergyUsage(usage models.EnergyUsage) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
 	s.energyUsage = append(s.energyUsage, usage)
 	
-	if len(s.energyUsage) > 1000 {
-		s.energyUsage = s.energyUsage[len(s.energyUsage)-1000:]
+	cutoff := usage.Timestamp.Add(-s.energyKeep)
+	expired := 0
+	for expired < len(s.energyUsage) && s.energyUsage[expired].Timestamp.Before(cutoff) {
+		expired++
 	}
+	s.energyUsage = s.energyUsage[expired:]
 }
 
 func (s *MemoryStore) GetEnergyUsage(limit int) []models.EnergyUsage {
@@ -292,6 +361,30 @@ func (s *MemoryStore) GetSystemEvents(limit int) []models.SystemEvent {
 	return s.systemEvents[len(s.systemEvents)-limit:]
 }
 
+func (s *MemoryStore) AddNotification(notification models.Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.notifications = append(s.notifications, notification)
+	
+	if len(s.notifications) > 500 {
+		s.notifications = s.notifications[len(s.notifications)-500:]
+	}
+}
+
+func (s *MemoryStore) GetNotifications(limit int) []models.Notification {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	if limit <= 0 || limit > len(s.notifications) {
+		limit = len(s.notifications)
+	}
+	
+	result := make([]models.Notification, limit)
+	copy(result, s.notifications[len(s.notifications)-limit:])
+	return result
+}
+
 func (s *MemoryStore) GetSystemState() *models.SystemState {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -313,8 +406,8 @@ func (s *MemoryStore) GetSystemState() *models.SystemState {
 		Tasks:        tasks,
 		EnergyUsage:  s.energyUsage,
 		SystemEvents: s.systemEvents,
-		Uptime:       time.Since(s.startTime),
-		Timestamp:    time.Now(),
+		Uptime:       s.clock.Since(s.startTime),
+		Timestamp:    s.clock.Now(),
 	}
 }
 
@@ -324,11 +417,24 @@ func (s *MemoryStore) Reset() {
 	
 	s.devices = make(map[string]*models.Device)
 	s.weather = &models.WeatherData{}
+	s.weatherSamples = make([]models.WeatherData, 0)
+	s.weatherAlerts = make([]models.WeatherAlert, 0)
 	s.security = &models.SecuritySystem{State: models.SecurityStateDisarmed}
 	s.tasks = make(map[string]*models.ScheduledTask)
+	s.taskHistory = make(map[string][]models.TaskRun)
+	s.users = make(map[string]*models.SecurityUser)
+	s.auditLog = make([]models.AuditEntry, 0)
+	s.notifications = make([]models.Notification, 0)
+	s.cameraEvents = make([]models.CameraEvent, 0)
+	s.safetyAlarms = make([]models.LifeSafetyAlarm, 0)
+	s.shedActions = make([]models.ShedAction, 0)
 	s.energyUsage = make([]models.EnergyUsage, 0)
+	s.energyFlows = make([]models.EnergyFlow, 0)
+	s.rollups = make([]models.EnergyRollup, 0)
+	s.anomalies = make([]models.EnergyAnomaly, 0)
 	s.systemEvents = make([]models.SystemEvent, 0)
-	s.startTime = time.Now()
+	s.startTime = s.clock.Now()
+	s.persistTasks()
 	
 	s.addSystemEvent("system_reset", "storage", "System state reset", map[string]interface{}{})
 }
@@ -349,12 +455,12 @@ func (s *MemoryStore) SaveToFile(filename string) error {
 
 func (s *MemoryStore) addSystemEvent(eventType, source, message string, data map[string]interface{}) {
 	event := models.SystemEvent{
-		ID:        fmt.Sprintf("event_%d", time.Now().UnixNano()),
+		ID:        utils.GenerateID("event"),
 		Type:      eventType,
 		Source:    source,
 		Message:   message,
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: s.clock.Now(),
 		Severity:  "info",
 	}
 	
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+
+	"multi-agent-framework-testing/models"
+)
+
+func (s *MemoryStore) AddSafetyAlarm(alarm models.LifeSafetyAlarm) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.safetyAlarms = append(s.safetyAlarms, alarm)
+	if len(s.safetyAlarms) > 500 {
+		s.safetyAlarms = s.safetyAlarms[len(s.safetyAlarms)-500:]
+	}
+}
+
+func (s *MemoryStore) UpdateSafetyAlarm(alarm models.LifeSafetyAlarm) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for i := range s.safetyAlarms {
+		if s.safetyAlarms[i].ID == alarm.ID {
+			s.safetyAlarms[i] = alarm
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("safety alarm with ID %s not found", alarm.ID)
+}
+
+func (s *MemoryStore) GetSafetyAlarm(id string) (models.LifeSafetyAlarm, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	for _, alarm := range s.safetyAlarms {
+		if alarm.ID == id {
+			return alarm, nil
+		}
+	}
+	
+	return models.LifeSafetyAlarm{}, fmt.Errorf("safety alarm with ID %s not found", id)
+}
+
+func (s *MemoryStore) GetSafetyAlarms(activeOnly bool, limit int) []models.LifeSafetyAlarm {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	alarms := make([]models.LifeSafetyAlarm, 0)
+	for i := len(s.safetyAlarms) - 1; i >= 0; i-- {
+		if activeOnly && !s.safetyAlarms[i].Active {
+			continue
+		}
+		alarms = append(alarms, s.safetyAlarms[i])
+		if limit > 0 && len(alarms) >= limit {
+			break
+		}
+	}
+	
+	return alarms
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"multi-agent-framework-testing/models"
+)
+
+type taskState struct {
+	Tasks   []models.ScheduledTask      `json:"tasks"`
+	History map[string][]models.TaskRun `json:"history"`
+}
+
+func (s *MemoryStore) EnableTaskPersistence(filename string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.taskFile = filename
+	
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read task state: %w", err)
+	}
+	
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse task state: %w", err)
+	}
+	
+	for i := range state.Tasks {
+		task := state.Tasks[i]
+		s.tasks[task.ID] = &task
+	}
+	
+	if state.History != nil {
+		s.taskHistory = state.History
+	}
+	
+	s.addSystemEvent("tasks_restored", "storage", fmt.Sprintf("Restored %d scheduled tasks from %s", len(state.Tasks), filename), map[string]interface{}{
+		"task_count": len(state.Tasks),
+	})
+	
+	return nil
+}
+
+func (s *MemoryStore) persistTasks() {
+	if s.taskFile == "" {
+		return
+	}
+	
+	state := taskState{
+		Tasks:   make([]models.ScheduledTask, 0, len(s.tasks)),
+		History: s.taskHistory,
+	}
+	for _, task := range s.tasks {
+		state.Tasks = append(state.Tasks, *task)
+	}
+	
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode task state: %v", err)
+		return
+	}
+	
+	tmp, err := os.CreateTemp(filepath.Dir(s.taskFile), ".tasks-*.json")
+	if err != nil {
+		log.Printf("Failed to persist task state: %v", err)
+		return
+	}
+	
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		log.Printf("Failed to persist task state: %v", err)
+		return
+	}
+	tmp.Close()
+	
+	if err := os.Rename(tmp.Name(), s.taskFile); err != nil {
+		os.Remove(tmp.Name())
+		log.Printf("Failed to persist task state: %v", err)
+	}
+}
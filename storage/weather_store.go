@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"time"
+
+	"multi-agent-framework-testing/models"
+)
+
+const weatherSampleRetention = 7 * 24 * time.Hour
+
+func (s *MemoryStore) recordWeatherSample(weather models.WeatherData) {
+	cutoff := weather.Timestamp.Add(-weatherSampleRetention)
+	drop := 0
+	for drop < len(s.weatherSamples) && s.weatherSamples[drop].Timestamp.Before(cutoff) {
+		drop++
+	}
+	s.weatherSamples = append(s.weatherSamples[drop:], weather)
+}
+
+func (s *MemoryStore) GetWeatherSamples(from, to time.Time) []models.WeatherData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	var samples []models.WeatherData
+	for _, sample := range s.weatherSamples {
+		if !sample.Timestamp.Before(from) && sample.Timestamp.Before(to) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+func (s *MemoryStore) SaveWeatherAlert(alert models.WeatherAlert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	alert = copyWeatherAlert(alert)
+	for i := range s.weatherAlerts {
+		if s.weatherAlerts[i].ID == alert.ID {
+			s.weatherAlerts[i] = alert
+			return
+		}
+	}
+	
+	s.weatherAlerts = append(s.weatherAlerts, alert)
+	if len(s.weatherAlerts) > 500 {
+		s.weatherAlerts = s.weatherAlerts[len(s.weatherAlerts)-500:]
+	}
+}
+
+func (s *MemoryStore) GetWeatherAlerts(activeOnly bool, limit int) []models.WeatherAlert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	alerts := make([]models.WeatherAlert, 0)
+	for i := len(s.weatherAlerts) - 1; i >= 0; i-- {
+		if activeOnly && !s.weatherAlerts[i].Active {
+			continue
+		}
+		alerts = append(alerts, copyWeatherAlert(s.weatherAlerts[i]))
+		if limit > 0 && len(alerts) >= limit {
+			break
+		}
+	}
+	
+	return alerts
+}
+
+func copyWeatherAlert(alert models.WeatherAlert) models.WeatherAlert {
+	copied := alert
+	copied.Metrics = make(map[string]float64, len(alert.Metrics))
+	for name, value := range alert.Metrics {
+		copied.Metrics[name] = value
+	}
+	copied.Peak = make(map[string]float64, len(alert.Peak))
+	for name, value := range alert.Peak {
+		copied.Peak[name] = value
+	}
+	return copied
+}
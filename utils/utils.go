@@ -7,8 +7,16 @@ import (
 	"math"
 	"sync"
 	"time"
+
+	"multi-agent-framework-testing/random"
 )
 
+var randomStream = random.NewSource(time.Now().UnixNano()).Stream("utils")
+
+func SetRandomSource(src *random.Source) {
+	randomStream = src.Stream("utils")
+}
+
 func GenerateID(prefix string) string {
 	bytes := make([]byte, 8)
 	rand.Read(bytes)
@@ -151,11 +159,7 @@ func ValidateDeviceProperties(deviceType string, properties map[string]interface
 }
 
 func GenerateRandomFloat(min, max float64) float64 {
-	bytes := make([]byte, 8)
-	rand.Read(bytes)
-	
-	value := float64(bytes[0]) / 255.0
-	return min + (max-min)*value
+	return min + (max-min)*randomStream.Float64()
 }
 
 func GenerateRandomInt(min, max int) int {
@@ -163,11 +167,7 @@ func GenerateRandomInt(min, max int) int {
 		return min
 	}
 	
-	bytes := make([]byte, 4)
-	rand.Read(bytes)
-	
-	value := int(bytes[0])
-	return min + value%(max-min)
+	return min + randomStream.Intn(max-min)
 }
 
 func StringInSlice(str string, slice []string) bool {
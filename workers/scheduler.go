@@ -6,27 +6,48 @@ import (
 	"sync"
 	"time"
 
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
 	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/rules"
 	"multi-agent-framework-testing/services"
 	"multi-agent-framework-testing/storage"
+	"multi-agent-framework-testing/utils"
+)
+
+const (
+	defaultMisfireGrace = time.Minute
+	defaultMaxCatchUp   = 10
+	maxRecordedMisfires = 50
 )
 
 type Scheduler struct {
 	store          *storage.MemoryStore
 	deviceService  *services.DeviceService
 	weatherService *services.WeatherService
+	security       *services.SecurityService
+	access         *services.AccessService
 	running        bool
 	stopChan       chan struct{}
 	wg             sync.WaitGroup
+	ruleStates     map[string]bool
+	ruleStatesMu   sync.Mutex
+	clock          clock.Clock
+	config         *config.Config
 }
 
-func NewScheduler(store *storage.MemoryStore, deviceService *services.DeviceService, weatherService *services.WeatherService) *Scheduler {
+func NewScheduler(store *storage.MemoryStore, deviceService *services.DeviceService, weatherService *services.WeatherService, securityService *services.SecurityService, accessService *services.AccessService, clk clock.Clock, cfg *config.Config) *Scheduler {
 	scheduler := &Scheduler{
 		store:          store,
 		deviceService:  deviceService,
 		weatherService: weatherService,
+		security:       securityService,
+		access:         accessService,
 		running:        false,
 		stopChan:       make(chan struct{}),
+		ruleStates:     make(map[string]bool),
+		clock:          clk,
+		config:         cfg,
 	}
 	
 	weatherService.SetStore(store)
@@ -34,6 +55,13 @@ func NewScheduler(store *storage.MemoryStore, deviceService *services.DeviceServ
 	return scheduler
 }
 
+func (s *Scheduler) Reset() {
+	s.ruleStatesMu.Lock()
+	defer s.ruleStatesMu.Unlock()
+	
+	s.ruleStates = make(map[string]bool)
+}
+
 func (s *Scheduler) Start() {
 	if s.running {
 		return
@@ -42,11 +70,12 @@ func (s *Scheduler) Start() {
 	s.running = true
 	log.Println("Scheduler started")
 	
-	s.wg.Add(4)
+	s.wg.Add(5)
 	go s.taskRunner()
 	go s.energyMonitor()
 	go s.securityMonitor()
 	go s.systemHealthMonitor()
+	go s.weatherPolicyMonitor()
 	
 	s.wg.Wait()
 }
@@ -65,12 +94,12 @@ func (s *Scheduler) Stop() {
 func (s *Scheduler) taskRunner() {
 	defer s.wg.Done()
 	
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := s.clock.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			s.processTasks()
 		case <-s.stopChan:
 			return
@@ -80,25 +109,260 @@ func (s *Scheduler) taskRunner() {
 
 func (s *Scheduler) processTasks() {
 	tasks := s.store.ListTasks()
-	now := time.Now()
+	now := s.clock.Now()
 	
 	for _, task := range tasks {
-		if task.Enabled && (task.NextRun.IsZero() || task.NextRun.Before(now)) {
-			s.executeTask(task)
+		if !task.Enabled {
+			continue
+		}
+		
+		if task.Schedule == "trigger_based" {
+			if s.ruleTriggered(task) {
+				s.executeTask(task, now)
+			}
+			continue
+		}
+		
+		if task.NextRun.IsZero() || task.NextRun.Before(now) {
+			s.runDueTask(task, now)
+		}
+	}
+}
+
+func (s *Scheduler) runDueTask(task *models.ScheduledTask, now time.Time) {
+	interval := s.scheduleInterval(task.Schedule)
+	
+	due := 1
+	latest := now
+	if !task.NextRun.IsZero() {
+		due = int(now.Sub(task.NextRun)/interval) + 1
+		latest = task.NextRun.Add(time.Duration(due-1) * interval)
+	}
+	
+	grace := time.Duration(task.MisfireGrace) * time.Second
+	if grace <= 0 {
+		grace = defaultMisfireGrace
+	}
+	onTime := now.Sub(latest) <= grace
+	
+	runs := 1
+	switch task.MisfirePolicy {
+	case models.MisfireSkip:
+		if !onTime {
+			runs = 0
+		}
+	case models.MisfireRunAll:
+		limit := task.MaxCatchUp
+		if limit <= 0 {
+			limit = defaultMaxCatchUp
+		}
+		runs = due
+		if runs > limit {
+			runs = limit
+		}
+	}
+	
+	if skipped := due - runs; skipped > 0 {
+		s.recordMisfire(task, skipped, interval, latest.Add(-time.Duration(runs)*interval))
+	}
+	
+	for i := runs - 1; i >= 0; i-- {
+		scheduledFor := latest.Add(-time.Duration(i) * interval)
+		if task.Condition != "" && !s.conditionHolds(task) {
+			s.skipTask(task, scheduledFor)
+			continue
 		}
+		s.executeTask(task, scheduledFor)
+	}
+	
+	s.store.UpdateTask(task.ID, map[string]interface{}{
+		"next_run": latest.Add(interval),
+	})
+}
+
+func (s *Scheduler) scheduleInterval(schedule string) time.Duration {
+	interval, err := utils.ParseSchedule(schedule)
+	if err != nil {
+		return time.Hour
+	}
+	return interval
+}
+
+func (s *Scheduler) recordMisfire(task *models.ScheduledTask, skipped int, interval time.Duration, lastSkipped time.Time) {
+	recorded := skipped
+	if recorded > maxRecordedMisfires {
+		recorded = maxRecordedMisfires
+	}
+	
+	for i := recorded - 1; i >= 0; i-- {
+		s.store.AddTaskRun(models.TaskRun{
+			TaskID:       task.ID,
+			ScheduledFor: lastSkipped.Add(-time.Duration(i) * interval),
+			RecordedAt:   s.clock.Now(),
+			Status:       models.TaskRunSkipped,
+			Reason:       "misfire",
+		})
 	}
+	
+	s.store.UpdateTask(task.ID, map[string]interface{}{
+		"missed_runs": task.MissedRuns + skipped,
+	})
+	
+	policy := task.MisfirePolicy
+	if policy == "" {
+		policy = models.MisfireRunOnce
+	}
+	
+	s.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("task"),
+		Type:      "task_misfire",
+		Source:    "scheduler",
+		Message:   fmt.Sprintf("Task %s missed %d run(s)", task.Name, skipped),
+		Data: map[string]interface{}{
+			"task_id":        task.ID,
+			"missed_runs":    skipped,
+			"misfire_policy": policy,
+		},
+		Timestamp: s.clock.Now(),
+		Severity:  "warning",
+	})
+}
+
+func (s *Scheduler) snapshot() *rules.Snapshot {
+	return rules.NewSnapshot(s.store.GetSystemState(), s.clock.Now())
 }
 
-func (s *Scheduler) executeTask(task *models.ScheduledTask) {
+func (s *Scheduler) ValidateCondition(condition string) error {
+	_, err := rules.Compile(condition, s.snapshot())
+	return err
+}
+
+func (s *Scheduler) conditionHolds(task *models.ScheduledTask) bool {
+	snap := s.snapshot()
+	
+	expr, err := rules.Compile(task.Condition, snap)
+	if err == nil {
+		var result bool
+		if result, err = expr.Eval(snap); err == nil {
+			return result
+		}
+	}
+	
+	s.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("rule_error"),
+		Type:      "rule_error",
+		Source:    "scheduler",
+		Message:   fmt.Sprintf("Condition for task %s could not be evaluated: %v", task.Name, err),
+		Data: map[string]interface{}{
+			"task_id":   task.ID,
+			"condition": task.Condition,
+			"error":     err.Error(),
+		},
+		Timestamp: s.clock.Now(),
+		Severity:  "warning",
+	})
+	
+	return false
+}
+
+func (s *Scheduler) ruleTriggered(task *models.ScheduledTask) bool {
+	if task.Condition == "" {
+		return false
+	}
+	
+	holds := s.conditionHolds(task)
+	
+	s.ruleStatesMu.Lock()
+	defer s.ruleStatesMu.Unlock()
+	
+	previous := s.ruleStates[task.ID]
+	s.ruleStates[task.ID] = holds
+	
+	return holds && !previous
+}
+
+func (s *Scheduler) skipTask(task *models.ScheduledTask, scheduledFor time.Time) {
+	s.store.AddTaskRun(models.TaskRun{
+		TaskID:       task.ID,
+		ScheduledFor: scheduledFor,
+		RecordedAt:   s.clock.Now(),
+		Status:       models.TaskRunSkipped,
+		Reason:       "condition_not_met",
+	})
+	
+	s.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("task"),
+		Type:      "task_skipped",
+		Source:    "scheduler",
+		Message:   fmt.Sprintf("Task %s skipped: condition not met", task.Name),
+		Data: map[string]interface{}{
+			"task_id":   task.ID,
+			"condition": task.Condition,
+		},
+		Timestamp: s.clock.Now(),
+		Severity:  "info",
+	})
+}
+
+func (s *Scheduler) executeTask(task *models.ScheduledTask, scheduledFor time.Time) {
 	log.Printf("Executing task: %s", task.Name)
 	
-	device, err := s.deviceService.GetDevice(task.DeviceID)
+	var err error
+	if task.Action == "automation_rule" {
+		for _, action := range task.Actions {
+			if err = s.runAction(action.Target, action.Type, action.Parameters); err != nil {
+				break
+			}
+		}
+	} else {
+		err = s.runAction(task.DeviceID, task.Action, task.Parameters)
+	}
+	
 	if err != nil {
-		log.Printf("Task %s failed: device not found", task.Name)
+		log.Printf("Task %s failed: %v", task.Name, err)
+		s.store.AddTaskRun(models.TaskRun{
+			TaskID:       task.ID,
+			ScheduledFor: scheduledFor,
+			RecordedAt:   s.clock.Now(),
+			Status:       models.TaskRunFailed,
+			Reason:       err.Error(),
+		})
 		return
 	}
 	
-	switch task.Action {
+	s.store.UpdateTask(task.ID, map[string]interface{}{
+		"last_run": s.clock.Now(),
+	})
+	
+	s.store.AddTaskRun(models.TaskRun{
+		TaskID:       task.ID,
+		ScheduledFor: scheduledFor,
+		RecordedAt:   s.clock.Now(),
+		Status:       models.TaskRunExecuted,
+	})
+	
+	s.store.AddSystemEvent(models.SystemEvent{
+		ID:        utils.GenerateID("task"),
+		Type:      "task_executed",
+		Source:    "scheduler",
+		Message:   fmt.Sprintf("Task %s executed successfully", task.Name),
+		Data: map[string]interface{}{
+			"task_id":   task.ID,
+			"device_id": task.DeviceID,
+			"action":    task.Action,
+		},
+		Timestamp: s.clock.Now(),
+		Severity:  "info",
+	})
+}
+
+func (s *Scheduler) runAction(deviceID, action string, parameters map[string]interface{}) error {
+	device, err := s.deviceService.GetDevice(deviceID)
+	if err != nil && action != "arm_security" && action != "disarm_security" {
+		return fmt.Errorf("device not found")
+	}
+	
+	switch action {
 	case "turn_on":
 		s.deviceService.UpdateDevice(device.ID, map[string]interface{}{
 			"power": true,
@@ -110,92 +374,126 @@ func (s *Scheduler) executeTask(task *models.ScheduledTask) {
 		})
 		
 	case "set_temperature":
-		if temp, ok := task.Parameters["temperature"].(float64); ok {
+		if temp, ok := parameters["temperature"].(float64); ok {
 			s.deviceService.UpdateDevice(device.ID, map[string]interface{}{
 				"target_temp": temp,
 			})
 		}
 		
 	case "set_brightness":
-		if brightness, ok := task.Parameters["brightness"].(float64); ok {
+		if brightness, ok := parameters["brightness"].(float64); ok {
 			s.deviceService.UpdateDevice(device.ID, map[string]interface{}{
 				"brightness": int(brightness),
 			})
 		}
 		
 	case "lock":
-		s.deviceService.UpdateDevice(device.ID, map[string]interface{}{
+		return s.deviceService.UpdateDevice(device.ID, map[string]interface{}{
 			"locked": true,
 		})
 		
 	case "unlock":
-		s.deviceService.UpdateDevice(device.ID, map[string]interface{}{
+		user, err := s.authorizedUser(parameters, "unlock", device.ID)
+		if err != nil {
+			return err
+		}
+		
+		if err := s.deviceService.UpdateDevice(device.ID, map[string]interface{}{
 			"locked": false,
-		})
+		}); err != nil {
+			s.access.Audit(user, "unlock", device.ID, false, err.Error())
+			return err
+		}
+		s.access.Audit(user, "unlock", device.ID, true, "scheduled task")
 		
 	case "arm_security":
-		security := s.store.GetSecurity()
-		security.State = models.SecurityStateArmed
-		security.LastArmed = time.Now()
-		s.store.UpdateSecurity(security)
+		mode, _ := parameters["mode"].(string)
+		bypass, _ := parameters["bypass"].(bool)
+		if _, err := s.security.Arm("scheduler", models.ArmMode(mode), bypass); err != nil {
+			return err
+		}
+		s.access.Audit(nil, "arm", "security", true, "scheduled task")
 		
 	case "disarm_security":
-		security := s.store.GetSecurity()
-		security.State = models.SecurityStateDisarmed
-		s.store.UpdateSecurity(security)
+		user, err := s.authorizedUser(parameters, "disarm", "security")
+		if err != nil {
+			return err
+		}
+		
+		source := "scheduler"
+		if user != nil {
+			source = user.Name
+		}
+		if _, err := s.security.Disarm(source); err != nil {
+			return err
+		}
+		s.access.Audit(user, "disarm", "security", true, "scheduled task")
 		
 	default:
-		log.Printf("Unknown task action: %s", task.Action)
-		return
+		return fmt.Errorf("unknown task action: %s", action)
 	}
 	
-	nextRun := s.calculateNextRun(task.Schedule)
-	s.store.UpdateTask(task.ID, map[string]interface{}{
-		"last_run": time.Now(),
-		"next_run": nextRun,
-	})
+	return nil
+}
+
+func (s *Scheduler) authorizedUser(parameters map[string]interface{}, action, target string) (*models.SecurityUser, error) {
+	if !s.access.Required() {
+		return nil, nil
+	}
 	
-	s.store.AddSystemEvent(models.SystemEvent{
-		ID:        fmt.Sprintf("task_%d", time.Now().UnixNano()),
-		Type:      "task_executed",
-		Source:    "scheduler",
-		Message:   fmt.Sprintf("Task %s executed successfully", task.Name),
-		Data: map[string]interface{}{
-			"task_id":   task.ID,
-			"device_id": task.DeviceID,
-			"action":    task.Action,
-		},
-		Timestamp: time.Now(),
-		Severity:  "info",
-	})
+	userID, _ := parameters["user_id"].(string)
+	return s.access.AuthorizeUser(userID, action, target)
 }
 
-func (s *Scheduler) calculateNextRun(schedule string) time.Time {
-	switch schedule {
-	case "daily":
-		return time.Now().AddDate(0, 0, 1)
-	case "hourly":
-		return time.Now().Add(time.Hour)
-	case "every_30_minutes":
-		return time.Now().Add(30 * time.Minute)
-	case "every_15_minutes":
-		return time.Now().Add(15 * time.Minute)
-	case "weekly":
-		return time.Now().AddDate(0, 0, 7)
-	default:
-		return time.Now().Add(time.Hour)
+func (s *Scheduler) authorizeAction(deviceID, action string, parameters map[string]interface{}) (map[string]interface{}, error) {
+	if action != "unlock" && action != "disarm_security" {
+		return parameters, nil
+	}
+	
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+	
+	code, _ := parameters["code"].(string)
+	delete(parameters, "code")
+	delete(parameters, "user_id")
+	
+	if !s.access.Required() {
+		return parameters, nil
+	}
+	
+	if code == "" {
+		return nil, fmt.Errorf("%w: %s actions need a code", services.ErrAccessDenied, action)
+	}
+	
+	var user *models.SecurityUser
+	var err error
+	if action == "unlock" {
+		user, err = s.access.AuthenticateLock(deviceID, code)
+	} else {
+		user, _, err = s.access.Authenticate(code, "disarm", "security")
+	}
+	if err != nil {
+		return nil, err
 	}
+	
+	parameters["user_id"] = user.ID
+	return parameters, nil
+}
+
+func (s *Scheduler) calculateNextRun(schedule string) time.Time {
+	return s.clock.Now().Add(s.scheduleInterval(schedule))
 }
 
 func (s *Scheduler) energyMonitor() {
 	defer s.wg.Done()
 	
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := s.clock.NewTicker(time.Duration(s.config.EnergyUpdateInterval) * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			s.collectEnergyUsage()
 		case <-s.stopChan:
 			return
@@ -204,42 +502,18 @@ func (s *Scheduler) energyMonitor() {
 }
 
 func (s *Scheduler) collectEnergyUsage() {
-	usageData := s.deviceService.CalculateEnergyUsage()
-	
-	for _, usage := range usageData {
-		s.store.AddEnergyUsage(usage)
-	}
-	
-	totalUsage := 0.0
-	for _, usage := range usageData {
-		totalUsage += usage.Usage
-	}
-	
-	if totalUsage > 10.0 {
-		s.store.AddSystemEvent(models.SystemEvent{
-			ID:        fmt.Sprintf("energy_alert_%d", time.Now().UnixNano()),
-			Type:      "energy_alert",
-			Source:    "scheduler",
-			Message:   fmt.Sprintf("High energy usage detected: %.2f kWh", totalUsage),
-			Data: map[string]interface{}{
-				"total_usage": totalUsage,
-				"threshold":   10.0,
-			},
-			Timestamp: time.Now(),
-			Severity:  "warning",
-		})
-	}
+	s.deviceService.SampleEnergy()
 }
 
 func (s *Scheduler) securityMonitor() {
 	defer s.wg.Done()
 	
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := s.clock.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			s.checkSecurityStatus()
 		case <-s.stopChan:
 			return
@@ -248,43 +522,25 @@ func (s *Scheduler) securityMonitor() {
 }
 
 func (s *Scheduler) checkSecurityStatus() {
-	security := s.store.GetSecurity()
-	
-	if security.State == models.SecurityStateTriggered {
-		timeSinceTriggered := time.Since(security.LastTriggered)
-		
-		if timeSinceTriggered > 5*time.Minute {
-			security.State = models.SecurityStateArmed
-			s.store.UpdateSecurity(security)
-			
-			s.store.AddSystemEvent(models.SystemEvent{
-				ID:        fmt.Sprintf("security_reset_%d", time.Now().UnixNano()),
-				Type:      "security_reset",
-				Source:    "scheduler",
-				Message:   "Security system automatically reset after timeout",
-				Data: map[string]interface{}{
-					"previous_state": "triggered",
-					"timeout_minutes": 5,
-				},
-				Timestamp: time.Now(),
-				Severity:  "info",
-			})
+	for _, device := range s.deviceService.ListDevices() {
+		kind := "Security"
+		if _, ok := models.HazardSensors[device.Type]; ok {
+			kind = "Life-safety"
+		} else if !models.IsSecuritySensor(device.Type) {
+			continue
 		}
-	}
-	
-	devices := s.deviceService.GetDevicesByType(models.DeviceTypeSensor)
-	for _, device := range devices {
+		
 		if device.Status == models.DeviceStatusOffline {
 			s.store.AddSystemEvent(models.SystemEvent{
-				ID:        fmt.Sprintf("sensor_offline_%d", time.Now().UnixNano()),
+				ID:        utils.GenerateID("sensor_offline"),
 				Type:      "sensor_offline",
 				Source:    "scheduler",
-				Message:   fmt.Sprintf("Security sensor %s is offline", device.Name),
+				Message:   fmt.Sprintf("%s sensor %s is offline", kind, device.Name),
 				Data: map[string]interface{}{
 					"device_id": device.ID,
 					"location":  device.Location,
 				},
-				Timestamp: time.Now(),
+				Timestamp: s.clock.Now(),
 				Severity:  "warning",
 			})
 		}
@@ -294,12 +550,12 @@ func (s *Scheduler) checkSecurityStatus() {
 func (s *Scheduler) systemHealthMonitor() {
 	defer s.wg.Done()
 	
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := s.clock.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			s.checkSystemHealth()
 		case <-s.stopChan:
 			return
@@ -319,7 +575,7 @@ func (s *Scheduler) checkSystemHealth() {
 	
 	if offlineCount > len(devices)/2 {
 		s.store.AddSystemEvent(models.SystemEvent{
-			ID:        fmt.Sprintf("system_health_%d", time.Now().UnixNano()),
+			ID:        utils.GenerateID("system_health"),
 			Type:      "system_health_warning",
 			Source:    "scheduler",
 			Message:   fmt.Sprintf("High number of offline devices: %d/%d", offlineCount, len(devices)),
@@ -327,41 +583,98 @@ func (s *Scheduler) checkSystemHealth() {
 				"offline_count": offlineCount,
 				"total_devices": len(devices),
 			},
-			Timestamp: time.Now(),
+			Timestamp: s.clock.Now(),
 			Severity:  "warning",
 		})
 	}
+}
+
+func (s *Scheduler) weatherPolicyMonitor() {
+	defer s.wg.Done()
 	
-	weather := s.weatherService.GetCurrentWeather()
-	if alert := s.weatherService.GetWeatherAlert(); alert != nil {
+	ticker := s.clock.NewTicker(time.Duration(s.config.WeatherUpdateInterval) * time.Second)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			s.applyWeatherPolicies()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) applyWeatherPolicies() {
+	for _, decision := range s.weatherService.EvaluatePolicies(s.deviceService.ListDevices()) {
+		devices := make([]string, 0, len(decision.Actions))
+		failures := make(map[string]string)
+		for _, action := range decision.Actions {
+			updates := make(map[string]interface{}, len(action.Updates))
+			for name, value := range action.Updates {
+				updates[name] = value
+			}
+			
+			if err := s.deviceService.UpdateDevice(action.DeviceID, updates); err != nil {
+				failures[action.DeviceID] = err.Error()
+				continue
+			}
+			devices = append(devices, action.DeviceID)
+		}
+		
+		message := fmt.Sprintf("Weather policy %s %s", decision.Policy, decision.Decision)
+		if decision.State != "" {
+			message = fmt.Sprintf("%s (%s)", message, decision.State)
+		}
+		message = fmt.Sprintf("%s: %s", message, decision.Reason)
+		
+		severity := "info"
+		if len(failures) > 0 {
+			severity = "warning"
+		}
+		
 		s.store.AddSystemEvent(models.SystemEvent{
-			ID:        fmt.Sprintf("weather_alert_%d", time.Now().UnixNano()),
-			Type:      "weather_alert",
+			ID:        utils.GenerateID(fmt.Sprintf("weather_policy_%s_%s", decision.Policy, decision.Decision)),
+			Type:      "weather_policy_" + decision.Decision,
 			Source:    "scheduler",
-			Message:   *alert,
+			Message:   message,
 			Data: map[string]interface{}{
-				"temperature": weather.Temperature,
-				"condition":   weather.Condition,
-				"wind_speed":  weather.WindSpeed,
-				"pressure":    weather.Pressure,
+				"policy":   decision.Policy,
+				"state":    decision.State,
+				"reason":   decision.Reason,
+				"devices":  devices,
+				"actions":  decision.Actions,
+				"failures": failures,
 			},
-			Timestamp: time.Now(),
-			Severity:  "warning",
+			Timestamp: decision.Timestamp,
+			Severity:  severity,
 		})
 	}
 }
 
 func (s *Scheduler) AddTask(task *models.ScheduledTask) error {
 	if task.ID == "" {
-		task.ID = fmt.Sprintf("task_%d", time.Now().UnixNano())
+		task.ID = utils.GenerateID("task")
+	}
+	
+	if task.Condition != "" {
+		if err := s.ValidateCondition(task.Condition); err != nil {
+			return err
+		}
+	}
+	
+	parameters, err := s.authorizeAction(task.DeviceID, task.Action, task.Parameters)
+	if err != nil {
+		return err
 	}
+	task.Parameters = parameters
 	
 	if task.NextRun.IsZero() {
 		task.NextRun = s.calculateNextRun(task.Schedule)
 	}
 	
 	task.Enabled = true
-	task.CreatedAt = time.Now()
+	task.CreatedAt = s.clock.Now()
 	
 	return s.store.AddTask(task)
 }
@@ -384,23 +697,40 @@ func (s *Scheduler) TriggerTask(id string) error {
 		return err
 	}
 	
-	s.executeTask(task)
+	s.executeTask(task, s.clock.Now())
 	return nil
 }
 
-func (s *Scheduler) CreateAutomationRule(name string, trigger map[string]interface{}, actions []models.TestAction) error {
+func (s *Scheduler) CreateAutomationRule(name string, condition string, actions []models.TestAction) (*models.ScheduledTask, error) {
+	if err := s.ValidateCondition(condition); err != nil {
+		return nil, err
+	}
+	
+	for i := range actions {
+		parameters, err := s.authorizeAction(actions[i].Target, actions[i].Type, actions[i].Parameters)
+		if err != nil {
+			return nil, err
+		}
+		actions[i].Parameters = parameters
+	}
+	
 	task := &models.ScheduledTask{
-		ID:         fmt.Sprintf("automation_%d", time.Now().UnixNano()),
-		Name:       name,
-		DeviceID:   "",
-		Action:     "automation_rule",
-		Parameters: trigger,
-		Schedule:   "trigger_based",
-		Enabled:    true,
-		CreatedAt:  time.Now(),
+		ID:        utils.GenerateID("automation"),
+		Name:      name,
+		DeviceID:  "",
+		Action:    "automation_rule",
+		Schedule:  "trigger_based",
+		Condition: condition,
+		Actions:   actions,
+		Enabled:   true,
+		CreatedAt: s.clock.Now(),
 	}
 	
-	return s.store.AddTask(task)
+	if err := s.store.AddTask(task); err != nil {
+		return nil, err
+	}
+	
+	return task, nil
 }
 
 func (s *Scheduler) TriggerAutomationScenario(scenario string) error {
@@ -436,9 +766,9 @@ func (s *Scheduler) executeMorningRoutine() error {
 		})
 	}
 	
-	security := s.store.GetSecurity()
-	security.State = models.SecurityStateDisarmed
-	s.store.UpdateSecurity(security)
+	if s.security.GetState().State != models.SecurityStateDisarmed {
+		s.security.Disarm("morning_routine")
+	}
 	
 	return nil
 }
@@ -477,10 +807,9 @@ func (s *Scheduler) executeAwayMode() error {
 		})
 	}
 	
-	security := s.store.GetSecurity()
-	security.State = models.SecurityStateArmed
-	security.LastArmed = time.Now()
-	s.store.UpdateSecurity(security)
+	if s.security.GetState().State == models.SecurityStateDisarmed {
+		s.security.Arm("away_mode", models.ArmModeAway, true)
+	}
 	
 	return nil
 }
@@ -504,19 +833,6 @@ func (s *Scheduler) executeSleepMode() error {
 }
 
 func (s *Scheduler) executeSecurityBreach() error {
-	security := s.store.GetSecurity()
-	security.State = models.SecurityStateTriggered
-	security.LastTriggered = time.Now()
-	security.TriggeredBy = "simulation"
-	s.store.UpdateSecurity(security)
-	
-	lights := s.deviceService.GetDevicesByType(models.DeviceTypeLight)
-	for _, light := range lights {
-		s.deviceService.UpdateDevice(light.ID, map[string]interface{}{
-			"power":      true,
-			"brightness": 100,
-		})
-	}
-	
-	return nil
+	_, err := s.security.Trigger("simulation")
+	return err
 }
\ No newline at end of file
@@ -0,0 +1,166 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"multi-agent-framework-testing/clock"
+	"multi-agent-framework-testing/config"
+	"multi-agent-framework-testing/models"
+	"multi-agent-framework-testing/random"
+	"multi-agent-framework-testing/services"
+	"multi-agent-framework-testing/storage"
+)
+
+var testStart = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestScheduler(t *testing.T) (*Scheduler, *storage.MemoryStore) {
+	clk := clock.NewSimulated(testStart, 1)
+	clk.Pause()
+	t.Cleanup(clk.Close)
+	
+	cfg := config.Load()
+	cfg.Weather.Provider = "random"
+	src := random.NewSource(1)
+	store := storage.NewMemoryStore(clk)
+	
+	securityService := services.NewSecurityService(store, clk, cfg)
+	accessService := services.NewAccessService(store, clk)
+	notifier := services.NewNotificationService(store, clk)
+	lockService := services.NewLockService(store, notifier, clk, src, cfg)
+	safetyService := services.NewLifeSafetyService(store, notifier, clk)
+	tariffService := services.NewTariffService(clk, cfg)
+	weatherService := services.NewWeatherService(clk, src, cfg)
+	forecastService := services.NewForecastService(store, weatherService, tariffService, clk, cfg)
+	budgetService := services.NewBudgetService(store, notifier, forecastService, clk, cfg)
+	deviceService := services.NewDeviceService(store, securityService, lockService, safetyService, tariffService, budgetService, clk, src, cfg)
+	
+	return NewScheduler(store, deviceService, weatherService, securityService, accessService, clk, cfg), store
+}
+
+func runMissedTask(t *testing.T, task *models.ScheduledTask) (*storage.MemoryStore, []models.TaskRun) {
+	t.Helper()
+	scheduler, store := newTestScheduler(t)
+	
+	task.ID = "task_missed"
+	task.Name = "Missed Task"
+	task.DeviceID = "light_001"
+	task.Action = "turn_on"
+	task.Schedule = "hourly"
+	task.Enabled = true
+	// Six hourly runs are due and the latest of them is 30 minutes late.
+	task.NextRun = testStart.Add(-5*time.Hour - 30*time.Minute)
+	if err := store.AddTask(task); err != nil {
+		t.Fatalf("add task: %v", err)
+	}
+	
+	scheduler.runDueTask(task, testStart)
+	
+	stored, err := store.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if want := testStart.Add(30 * time.Minute); !stored.NextRun.Equal(want) {
+		t.Errorf("next run = %s, want %s", stored.NextRun, want)
+	}
+	return store, store.GetTaskHistory(task.ID, 0)
+}
+
+func countRuns(history []models.TaskRun, status models.TaskRunStatus) int {
+	count := 0
+	for _, run := range history {
+		if run.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func assertMisfire(t *testing.T, store *storage.MemoryStore, history []models.TaskRun, executed, missed int, policy models.MisfirePolicy) {
+	t.Helper()
+	if got := countRuns(history, models.TaskRunExecuted); got != executed {
+		t.Errorf("executed runs = %d, want %d", got, executed)
+	}
+	if got := countRuns(history, models.TaskRunSkipped); got != missed {
+		t.Errorf("skipped runs = %d, want %d", got, missed)
+	}
+	for _, run := range history {
+		if run.Status == models.TaskRunSkipped && run.Reason != "misfire" {
+			t.Errorf("skipped run reason = %q, want misfire", run.Reason)
+		}
+	}
+	for i := 1; i < len(history); i++ {
+		if got := history[i].ScheduledFor.Sub(history[i-1].ScheduledFor); got != time.Hour {
+			t.Errorf("runs %d and %d are %s apart, want one hour", i-1, i, got)
+		}
+	}
+	
+	task, _ := store.GetTask("task_missed")
+	if task.MissedRuns != missed {
+		t.Errorf("missed runs = %d, want %d", task.MissedRuns, missed)
+	}
+	
+	var misfires []models.SystemEvent
+	for _, event := range store.GetSystemEvents(0) {
+		if event.Type == "task_misfire" {
+			misfires = append(misfires, event)
+		}
+	}
+	if missed == 0 {
+		if len(misfires) != 0 {
+			t.Errorf("task_misfire events = %d, want none", len(misfires))
+		}
+		return
+	}
+	if len(misfires) != 1 {
+		t.Fatalf("task_misfire events = %d, want 1", len(misfires))
+	}
+	if misfires[0].Data["missed_runs"] != missed || misfires[0].Data["misfire_policy"] != policy {
+		t.Errorf("task_misfire data = %v, want %d missed under %s", misfires[0].Data, missed, policy)
+	}
+}
+
+func TestMisfireRunOnceRunsLatest(t *testing.T) {
+	store, history := runMissedTask(t, &models.ScheduledTask{})
+	
+	assertMisfire(t, store, history, 1, 5, models.MisfireRunOnce)
+	if last := history[len(history)-1]; !last.ScheduledFor.Equal(testStart.Add(-30 * time.Minute)) {
+		t.Errorf("executed run scheduled for %s, want the latest missed slot", last.ScheduledFor)
+	}
+}
+
+func TestMisfireRunAllCatchesUp(t *testing.T) {
+	store, history := runMissedTask(t, &models.ScheduledTask{MisfirePolicy: models.MisfireRunAll})
+	
+	assertMisfire(t, store, history, 6, 0, models.MisfireRunAll)
+	if first := history[0]; !first.ScheduledFor.Equal(testStart.Add(-5*time.Hour - 30*time.Minute)) {
+		t.Errorf("first run scheduled for %s, want the earliest missed slot", first.ScheduledFor)
+	}
+}
+
+func TestMisfireRunAllHonoursMaxCatchUp(t *testing.T) {
+	store, history := runMissedTask(t, &models.ScheduledTask{MisfirePolicy: models.MisfireRunAll, MaxCatchUp: 4})
+	
+	assertMisfire(t, store, history, 4, 2, models.MisfireRunAll)
+	for i, run := range history {
+		want := models.TaskRunExecuted
+		if i < 2 {
+			want = models.TaskRunSkipped
+		}
+		if run.Status != want {
+			t.Errorf("run %d status = %s, want the oldest runs skipped", i, run.Status)
+		}
+	}
+}
+
+func TestMisfireSkipDropsLateRuns(t *testing.T) {
+	store, history := runMissedTask(t, &models.ScheduledTask{MisfirePolicy: models.MisfireSkip})
+	
+	assertMisfire(t, store, history, 0, 6, models.MisfireSkip)
+}
+
+func TestMisfireSkipRunsWithinGrace(t *testing.T) {
+	store, history := runMissedTask(t, &models.ScheduledTask{MisfirePolicy: models.MisfireSkip, MisfireGrace: 3600})
+	
+	assertMisfire(t, store, history, 1, 5, models.MisfireSkip)
+}